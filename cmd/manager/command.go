@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/apis"
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
+	errs "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	crconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// commands run once against the cluster from the current kubeconfig instead of running the controller
+var commands = map[string]func(args []string) int{
+	"register": register,
+	"diagnose": diagnose,
+}
+
+// commandFlags are flags shared by the commands
+type commandFlags struct {
+	namespace string
+	name      string
+	timeout   time.Duration
+}
+
+func newCommandFlags(name, description string) (*flag.FlagSet, *commandFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	f := &commandFlags{}
+	fs.StringVar(&f.namespace, "namespace", os.Getenv(k8sutil.WatchNamespaceEnvVar), "namespace of the ToolChainEnabler, defaults to WATCH_NAMESPACE env var")
+	fs.StringVar(&f.name, "name", "", "name of the ToolChainEnabler, required only if there are more of them in the namespace")
+	fs.DurationVar(&f.timeout, "timeout", 2*time.Minute, "deadline of the command including calls to API server, auth and cluster service")
+	// kubeconfig flag is registered by controller-runtime to the default flag set
+	if kubeconfig := flag.Lookup("kubeconfig"); kubeconfig != nil {
+		fs.Var(kubeconfig.Value, "kubeconfig", kubeconfig.Usage)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags]\n\n%s\n\n", os.Args[0], name, description)
+		fs.PrintDefaults()
+	}
+	return fs, f
+}
+
+// connect creates client of the cluster from the current kubeconfig, detects capabilities of the cluster and gets the
+// ToolChainEnabler the command works with
+func (f commandFlags) connect(ctx context.Context) (client.Client, capabilities.Capabilities, *codereadyv1alpha1.ToolChainEnabler, error) {
+	var caps capabilities.Capabilities
+	if f.namespace == "" {
+		return nil, caps, nil, errs.Errorf("namespace of the ToolChainEnabler isn't set, use -namespace flag or %s env var", k8sutil.WatchNamespaceEnvVar)
+	}
+	cfg, err := crconfig.GetConfig()
+	if err != nil {
+		return nil, caps, nil, errs.Wrapf(err, "failed to get kubeconfig")
+	}
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		return nil, caps, nil, err
+	}
+	cl, err := crclient.New(cfg, crclient.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		return nil, caps, nil, errs.Wrapf(err, "failed to create client of %s", cfg.Host)
+	}
+	d, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, caps, nil, errs.Wrapf(err, "failed to create discovery client of %s", cfg.Host)
+	}
+	caps, err = capabilities.Detect(d)
+	if err != nil {
+		return nil, caps, nil, err
+	}
+
+	tce, err := f.toolChainEnabler(ctx, cl)
+	if err != nil {
+		return nil, caps, nil, err
+	}
+	return client.NewClient(cl), caps, tce, nil
+}
+
+// toolChainEnabler gets the ToolChainEnabler of the given name, or the only one in the namespace if name isn't set
+func (f commandFlags) toolChainEnabler(ctx context.Context, cl crclient.Client) (*codereadyv1alpha1.ToolChainEnabler, error) {
+	if f.name != "" {
+		tce := &codereadyv1alpha1.ToolChainEnabler{}
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: f.namespace, Name: f.name}, tce); err != nil {
+			return nil, errs.Wrapf(err, "failed to get ToolChainEnabler %s/%s", f.namespace, f.name)
+		}
+		return tce, nil
+	}
+
+	tces := &codereadyv1alpha1.ToolChainEnablerList{}
+	if err := cl.List(ctx, &crclient.ListOptions{Namespace: f.namespace}, tces); err != nil {
+		return nil, errs.Wrapf(err, "failed to list ToolChainEnablers in namespace %s", f.namespace)
+	}
+	switch len(tces.Items) {
+	case 0:
+		return nil, errs.Errorf("there is no ToolChainEnabler in namespace %s", f.namespace)
+	case 1:
+		return &tces.Items[0], nil
+	default:
+		var names []string
+		for _, tce := range tces.Items {
+			names = append(names, tce.Name)
+		}
+		return nil, errs.Errorf("there are more ToolChainEnablers in namespace %s, choose one of %s with -name flag", f.namespace, strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/controller/toolchainenabler"
+	"github.com/fabric8-services/toolchain-operator/pkg/permissions"
+	"github.com/fabric8-services/toolchain-operator/pkg/redact"
+)
+
+// diagnose prints configuration resolved for the ToolChainEnabler with secrets redacted, permissions missing to the
+// operator and toolchain-sre, and calls to auth and cluster service which fail. Nothing is changed neither in the
+// cluster nor in cluster management service
+func diagnose(args []string) int {
+	fs, f := newCommandFlags("diagnose", "Prints resolved configuration, missing permissions and failing calls to auth and cluster service without changing anything.")
+	operatorSA := fs.String("operator-service-account", config.Name, "name of the Service Account the operator runs as")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	cl, caps, tce, err := f.connect(ctx)
+	if err != nil {
+		log.Error(err, "failed to connect to the cluster")
+		return 1
+	}
+
+	d := &diagnosis{out: os.Stdout}
+	d.section(fmt.Sprintf("ToolChainEnabler %s/%s", tce.Namespace, tce.Name))
+
+	// the rest is diagnosed with the defaults if the operator ConfigMap is invalid
+	operatorConfig, err := config.LoadOperatorConfig(ctx, cl, tce.Namespace)
+	d.section(fmt.Sprintf("Operator configuration (configmap %s)", config.OperatorConfigMapName))
+	if d.check(err) {
+		d.field(config.RegistrationRetryPeriodKey, operatorConfig.RegistrationRetryPeriod)
+		d.field(config.PermissionsRecheckPeriodKey, operatorConfig.PermissionsRecheckPeriod)
+		d.field(config.DriftCheckPeriodKey, operatorConfig.DriftCheckPeriod)
+		d.field(config.ReconcileTimeoutKey, operatorConfig.ReconcileTimeout)
+		d.field(config.HTTPConnectTimeoutKey, operatorConfig.HTTPConnectTimeout)
+		d.field(config.HTTPResponseTimeoutKey, operatorConfig.HTTPResponseTimeout)
+		d.field(config.ResyncPeriodKey, operatorConfig.ResyncPeriod)
+		d.field(config.ServiceAccountNameKey, operatorConfig.ServiceAccountName)
+		d.field(config.OAuthClientNameKey, operatorConfig.OAuthClientName)
+		d.field(config.InfraNamespaceKey, operatorConfig.InfraNamespace)
+	}
+
+	cfg, err := config.Load(ctx, cl, tce, operatorConfig)
+	d.section("Toolchain configuration")
+	if d.check(err) {
+		d.field("authURL", cfg.GetAuthServiceURL())
+		d.field("clusterURL", cfg.GetClusterServiceURL())
+		d.field("clusterName", cfg.GetClusterName())
+		d.field("clientID", cfg.GetClientID())
+		d.field("clientSecret", redact.Value(cfg.GetClientSecret()))
+		d.field("httpConnectTimeout", cfg.GetHTTPConnectTimeout())
+		d.field("httpResponseTimeout", cfg.GetHTTPResponseTimeout())
+		d.field("resyncPeriod", cfg.GetResyncPeriod())
+		d.field("caBundle", redact.Value(string(cfg.GetCABundle())))
+		d.field("clientCertificate", redact.Value(string(cfg.GetClientCertificate())))
+		d.field("clientKey", redact.Value(string(cfg.GetClientKey())))
+		d.field("httpProxy", redact.URL(cfg.GetHTTPProxy()))
+		d.field("httpsProxy", redact.URL(cfg.GetHTTPSProxy()))
+		d.field("noProxy", cfg.GetNoProxy())
+	}
+
+	d.section("Cluster capabilities")
+	d.field("platform", caps.Platform())
+	d.field("openShiftVersion", caps.OpenShiftVersion)
+	d.field("config", caps.Config)
+	d.field("route", caps.Route)
+	d.field("oauth", caps.OAuth)
+	d.field("tokenRequest", caps.TokenRequest)
+
+	d.section("Cluster configuration")
+	data, err := cluster.NewReadOnlyConfigInformer(cl, tce.Namespace, operatorConfig.ServiceAccountName, tce.Spec.ClusterName, caps, toolchainenabler.Platform(cl, tce, caps, operatorConfig)).Inform(ctx)
+	if d.check(err) {
+		d.field("name", data.Name)
+		d.field("api-url", data.APIURL)
+		d.field("app-dns", appDNS(data))
+		d.field("auth-client-id", data.AuthClientID)
+		d.field("auth-client-secret", redact.Value(data.AuthClientSecret))
+		d.field("auth-client-default-scope", data.AuthClientDefaultScope)
+		d.field("service-account-username", data.ServiceAccountUsername)
+		d.field("service-account-token", redact.Value(data.ServiceAccountToken))
+		d.field("type", data.Type)
+	}
+
+	d.section(fmt.Sprintf("Permissions of the operator (service account %s)", *operatorSA))
+	d.permissions(ctx, cl, tce.Namespace, *operatorSA, permissions.Operator(tce.Namespace, operatorConfig.InfraNamespace, caps.Platform(), toolchainenabler.Features(tce)...))
+
+	d.section(fmt.Sprintf("Permissions of toolchain (service account %s)", operatorConfig.ServiceAccountName))
+	d.permissions(ctx, cl, tce.Namespace, operatorConfig.ServiceAccountName, permissions.RequiredOn(caps.Platform()))
+
+	d.section("Calls to auth and cluster service")
+	if cfg.GetClusterServiceURL() != "" {
+		d.calls(ctx, cfg, data)
+	} else {
+		d.problem("skipped as toolchain configuration couldn't be loaded")
+	}
+
+	if d.problems > 0 {
+		fmt.Fprintf(d.out, "\n%d problem(s) found\n", d.problems)
+		return 1
+	}
+	fmt.Fprintln(d.out, "\nno problem found")
+	return 0
+}
+
+// diagnosis prints findings and counts problems among them
+type diagnosis struct {
+	out      io.Writer
+	problems int
+}
+
+func (d *diagnosis) section(title string) {
+	fmt.Fprintf(d.out, "\n%s\n", title)
+}
+
+func (d *diagnosis) field(name string, value interface{}) {
+	fmt.Fprintf(d.out, "  %s: %v\n", name, value)
+}
+
+func (d *diagnosis) problem(format string, args ...interface{}) {
+	d.problems++
+	fmt.Fprintf(d.out, "  FAIL %s\n", fmt.Sprintf(format, args...))
+}
+
+func (d *diagnosis) ok(format string, args ...interface{}) {
+	fmt.Fprintf(d.out, "  OK   %s\n", fmt.Sprintf(format, args...))
+}
+
+// check reports the given error, scrubbed of secrets, as a problem and returns true if there is none
+func (d *diagnosis) check(err error) bool {
+	if err != nil {
+		d.problem("%s", redact.Text(err.Error()))
+		return false
+	}
+	return true
+}
+
+// permissions reviews the given permissions of the Service Account and reports every missing one
+func (d *diagnosis) permissions(ctx context.Context, cl client.Client, namespace, serviceAccount string, required []permissions.Permission) {
+	user, groups := permissions.ServiceAccountUser(namespace, serviceAccount)
+	missing, err := permissions.Missing(ctx, cl, user, groups, required)
+	if !d.check(err) {
+		return
+	}
+	for _, p := range missing {
+		d.problem("missing %s", p)
+	}
+	if len(missing) == 0 {
+		d.ok("all %d permissions granted", len(required))
+	}
+}
+
+// calls checks cluster service status, then fetches the cluster registered in cluster service, which needs a token
+// from auth service, and compares it with the resolved cluster configuration
+func (d *diagnosis) calls(ctx context.Context, cfg config.ToolchainConfig, data *clusterclient.CreateClusterData) {
+	service := cluster.NewClusterService(cfg)
+	if d.check(service.Status(ctx)) {
+		d.ok("cluster service %s is up", cfg.GetClusterServiceURL())
+	}
+	if data == nil {
+		d.problem("registered cluster not checked as cluster configuration couldn't be resolved")
+		return
+	}
+
+	v, err := service.CompareCluster(ctx, data)
+	if !d.check(err) {
+		return
+	}
+	d.ok("token of %s obtained from auth service %s", cfg.GetClientID(), cfg.GetAuthServiceURL())
+	fields := mismatches(v, data)
+	switch {
+	case !v.Found:
+		d.problem("cluster %s isn't registered in cluster management service", data.APIURL)
+	case len(fields) > 0:
+		for _, field := range fields {
+			d.problem("registered cluster doesn't match %s", field)
+		}
+	default:
+		d.ok("cluster %s registered in cluster management service", data.APIURL)
+	}
+}
+
+// appDNS returns app DNS of the given cluster configuration, or why it's missing if it wasn't resolved
+func appDNS(data *clusterclient.CreateClusterData) string {
+	if data.AppDNS == "" {
+		return cluster.UnresolvedAppDNS
+	}
+	return data.AppDNS
+}
+
+// mismatches returns fields of the registered cluster which don't match the given cluster configuration, except for
+// app DNS if it wasn't resolved
+func mismatches(v cluster.Verification, data *clusterclient.CreateClusterData) []string {
+	var fields []string
+	for _, field := range v.Mismatches {
+		if field == "app-dns" && data.AppDNS == "" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
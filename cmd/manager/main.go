@@ -2,20 +2,34 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/fabric8-services/toolchain-operator/pkg/apis"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	toolchainconfig "github.com/fabric8-services/toolchain-operator/pkg/config"
 	"github.com/fabric8-services/toolchain-operator/pkg/controller"
+	"github.com/fabric8-services/toolchain-operator/pkg/controller/toolchainenabler"
+	"github.com/fabric8-services/toolchain-operator/pkg/election"
+	"github.com/fabric8-services/toolchain-operator/pkg/health"
 	"github.com/fabric8-services/toolchain-operator/pkg/online_registration"
+	"github.com/fabric8-services/toolchain-operator/pkg/permissions"
+	"github.com/fabric8-services/toolchain-operator/pkg/redact"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
-	"github.com/operator-framework/operator-sdk/pkg/leader"
-	"github.com/operator-framework/operator-sdk/pkg/ready"
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/discovery"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -24,15 +38,129 @@ import (
 
 var log = logf.Log.WithName("cmd")
 
+var (
+	healthAddr         = flag.String("health-addr", ":8081", "address serving liveness and readiness probes")
+	reconcileStuckTime = flag.Duration("reconcile-stuck-timeout", 5*time.Minute, "duration of single reconcile after which the operator is reported as not live")
+	checkClusterSvc    = flag.Bool("check-cluster-service", false, "report the operator as not ready while cluster service is not reachable")
+
+	leaderElect = flag.Bool("leader-elect", true, "elect a leader among replicas of the operator, only the leader reconciles resources")
+	// the Role of deploy/namespace-manifests.yaml grants update of the lock configmap by its name, it has to be changed
+	// along with the flag, otherwise the lease is never renewed
+	leaderLock    = flag.String("leader-election-id", "toolchain-enabler-lock", "name of the configmap holding the leader election lease, update of the configmap has to be granted to the operator by its name")
+	leaseDuration = flag.Duration("leader-election-lease-duration", election.DefaultLeaseDuration, "duration standby replicas wait before taking over leadership not renewed by the leader")
+	renewDeadline = flag.Duration("leader-election-renew-deadline", election.DefaultRenewDeadline, "duration the leader retries renewing its lease before giving up leadership")
+	retryPeriod   = flag.Duration("leader-election-retry-period", election.DefaultRetryPeriod, "duration replicas wait between tries of acquiring or renewing the lease")
+
+	capabilitiesRefresh = flag.Duration("capabilities-refresh-period", capabilities.DefaultRefreshPeriod, "period of detecting APIs served by the cluster again, e.g. after it's upgraded")
+
+	tracingExporter      = flag.String("tracing-exporter", tracing.ExporterNone, "exporter of spans of reconciles and calls to auth and cluster service, one of none, stdout or ocagent")
+	tracingAgentAddress  = flag.String("tracing-agent-address", "", "host and port of OpenCensus agent or OpenTelemetry collector receiving spans of ocagent exporter, localhost:55678 if empty")
+	tracingAgentInsecure = flag.Bool("tracing-agent-insecure", false, "send spans to the agent without TLS")
+)
+
 func printVersion() {
 	log.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	log.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
 	log.Info(fmt.Sprintf("operator-sdk Version: %v", sdkVersion.Version))
 }
 
-func main() {
-	flag.Parse()
+// podName returns name of the pod running the operator, or hostname if it's running outside of the cluster
+func podName() (string, error) {
+	if name, found := os.LookupEnv(k8sutil.PodNameEnvVar); found && name != "" {
+		return name, nil
+	}
+	return os.Hostname()
+}
 
+// preflight logs all the permissions the operator lacks on a cluster of the given platform in a single line, with
+// online-registration service account in the given infra namespace. Missing
+// permissions of optional features are logged at info level, since they're needed only by ToolChainEnablers which
+// enable the feature. The operator keeps running, each reconcile checks the permissions again and reports missing ones
+// in status of ToolChainEnabler
+func preflight(mgr manager.Manager, namespace, infraNamespace string, platform capabilities.Platform) {
+	missing, err := permissions.MissingForSelf(context.TODO(), mgr.GetClient(), permissions.Operator(namespace, infraNamespace, platform))
+	if err != nil {
+		log.Error(err, "failed to review permissions of the operator")
+		return
+	}
+	if len(missing) > 0 {
+		log.Error(fmt.Errorf("missing permissions: %s", permissions.Join(missing)), "operator won't make any change until missing permissions are granted")
+		return
+	}
+	granted := true
+	for _, feature := range permissions.AllFeatures {
+		missing, err := permissions.MissingForSelf(context.TODO(), mgr.GetClient(), permissions.ForFeature(namespace, infraNamespace, feature))
+		if err != nil {
+			log.Error(err, "failed to review permissions of the operator", "feature", feature)
+			return
+		}
+		if len(missing) > 0 {
+			granted = false
+			log.Info("optional feature can't be enabled in ToolChainEnabler until missing permissions are granted", "feature", feature, "missing", permissions.Join(missing))
+		}
+	}
+	if granted {
+		log.Info("operator is granted all the permissions it needs")
+		return
+	}
+	log.Info("operator is granted all the permissions it needs except those of optional features")
+}
+
+// sweepOrphans deletes cluster-scoped resources left behind by ToolChainEnablers deleted while the operator wasn't
+// running. Failure isn't fatal, the resources are swept again on the next start
+func sweepOrphans(mgr manager.Manager, namespace, infraNamespace string) {
+	// the cache isn't started yet, so resources are read directly from the API server
+	cl, err := crclient.New(mgr.GetConfig(), crclient.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		log.Error(err, "failed to create client for sweeping orphaned resources")
+		return
+	}
+	if err := toolchainenabler.SweepOrphans(context.TODO(), cl, namespace, infraNamespace); err != nil {
+		log.Error(err, "failed to sweep orphaned resources")
+	}
+}
+
+// loadOperatorConfig reads configuration of the operator from the operator ConfigMap. The cache isn't started yet, so
+// the ConfigMap is read directly from the API server
+func loadOperatorConfig(cfg *rest.Config, namespace string) (toolchainconfig.OperatorConfig, error) {
+	cl, err := crclient.New(cfg, crclient.Options{})
+	if err != nil {
+		return toolchainconfig.OperatorConfig{}, err
+	}
+	return toolchainconfig.LoadOperatorConfig(context.TODO(), client.NewClient(cl), namespace)
+}
+
+// operatorConfigChanged logs configuration of the operator reloaded from the operator ConfigMap. It exits when the
+// change applies only once the operator is restarted, e.g. infra namespace the cache is bound to at startup, so that
+// the operator is restarted with it
+func operatorConfigChanged(current, previous toolchainconfig.OperatorConfig) {
+	log.Info("operator configuration reloaded", "config", redact.NewOperatorConfig(current))
+	if keys := current.RestartRequired(previous); len(keys) > 0 {
+		log.Info(fmt.Sprintf("changes of %v apply once the operator is restarted, exiting", keys))
+		os.Exit(1)
+	}
+}
+
+// capabilitiesChanged exits when refreshed capabilities of the cluster change the way token of toolchain-sre is obtained
+// or the watched resources, which are picked at startup, so that the operator is restarted with them
+func capabilitiesChanged(current, previous capabilities.Capabilities) {
+	if names := previous.RestartRequired(current); len(names) > 0 {
+		log.Info(fmt.Sprintf("changes of capabilities %v apply once the operator is restarted, exiting", names), "capabilities", current)
+		os.Exit(1)
+	}
+}
+
+// serveOperatorConfig serves the effective configuration of the operator as JSON
+func serveOperatorConfig(store *toolchainconfig.OperatorConfigStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(redact.NewOperatorConfig(store.Get()).MarshalLog()); err != nil {
+			log.Error(err, "failed to serve operator configuration")
+		}
+	})
+}
+
+func main() {
 	// The logger instantiated here can be changed to any logger
 	// implementing the logr.Logger interface. This logger will
 	// be propagated through the whole operator, generating
@@ -40,6 +168,13 @@ func main() {
 	// ToDo: Use Logrus
 	logf.SetLogger(logf.ZapLogger(false))
 
+	if len(os.Args) > 1 {
+		if command, found := commands[os.Args[1]]; found {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+	flag.Parse()
+
 	printVersion()
 
 	namespace, err := k8sutil.GetWatchNamespace()
@@ -55,26 +190,57 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Become the leader before proceeding
-	if err := leader.Become(context.TODO(), "toolchain-enabler-lock"); err != nil {
-		log.Error(err, "")
+	operatorConfig, err := loadOperatorConfig(cfg, namespace)
+	if err != nil {
+		log.Error(err, "failed to load operator configuration")
 		os.Exit(1)
 	}
+	log.Info("operator configuration loaded", "config", redact.NewOperatorConfig(operatorConfig))
+	operatorConfigStore := toolchainconfig.NewOperatorConfigStore(namespace, operatorConfig, operatorConfigChanged)
+
+	stop := signals.SetupSignalHandler()
 
-	r := ready.NewFileReady()
-	err = r.Set()
+	stopTracing, err := tracing.Setup(tracing.Config{Exporter: *tracingExporter, Address: *tracingAgentAddress, Insecure: *tracingAgentInsecure})
 	if err != nil {
-		log.Error(err, "")
+		log.Error(err, "failed to set up tracing")
 		os.Exit(1)
 	}
+	// spans which haven't been exported yet are flushed once the manager stops
+	defer stopTracing()
 
-	defer func() {
-		if err := r.Unset(); err != nil {
-			log.Error(err, "")
+	// serve health probes right away, standby replicas are live but not ready
+	healthServer := health.NewServer()
+	watchdog := health.NewWatchdog(*reconcileStuckTime)
+	healthServer.AddLivenessCheck("reconcile", watchdog.Check)
+	healthServer.AddEndpoint(health.ConfigPath, serveOperatorConfig(operatorConfigStore))
+	go func() {
+		if err := healthServer.Start(*healthAddr, stop); err != nil {
+			log.Error(err, "failed to serve health probes")
 			os.Exit(1)
 		}
 	}()
 
+	var elector *election.Elector
+	if *leaderElect {
+		identity, err := podName()
+		if err != nil {
+			log.Error(err, "failed to get identity for leader election")
+			os.Exit(1)
+		}
+		elector, err = election.New(cfg, election.Config{
+			LockName:      *leaderLock,
+			Namespace:     namespace,
+			Identity:      identity,
+			LeaseDuration: *leaseDuration,
+			RenewDeadline: *renewDeadline,
+			RetryPeriod:   *retryPeriod,
+		})
+		if err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
 	// Create a new Cmd to provide shared dependencies and start components
 	mgr, err := manager.New(cfg, manager.Options{Namespace: namespace})
 	if err != nil {
@@ -90,46 +256,78 @@ func main() {
 		os.Exit(1)
 	}
 
-	secondaryCache, err := cache.New(mgr.GetConfig(), cache.Options{Namespace: online_registration.Namespace, Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	// the operator ConfigMap is watched through the cache of the manager, so that changes apply without a restart
+	configMapInformer, err := mgr.GetCache().GetInformer(&corev1.ConfigMap{})
+	if err != nil {
+		log.Error(err, "failed to watch operator configuration")
+		os.Exit(1)
+	}
+	configMapInformer.AddEventHandler(operatorConfigStore.EventHandler())
+
+	// secondary cache for openshift-infra ns is started by controller only when online-registration is enabled
+	secondaryCache, err := cache.New(mgr.GetConfig(), cache.Options{Namespace: operatorConfig.InfraNamespace, Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
 	if err != nil {
 		log.Error(fmt.Errorf("failed to create openshift-infra cache: %v", err), "")
 		os.Exit(1)
 	}
 
-	// Setup all Controllers
-	if err := controller.AddToManager(mgr, secondaryCache); err != nil {
+	infraCache := online_registration.NewInfraCache(secondaryCache, operatorConfig.InfraNamespace, stop)
+
+	sweepOrphans(mgr, namespace, operatorConfig.InfraNamespace)
+
+	// APIs served by the cluster drive differences between OpenShift 3 and 4, they're detected again periodically
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
+	detector, err := capabilities.NewDetector(discoveryClient, capabilitiesChanged)
+	if err != nil {
+		log.Error(err, "failed to detect capabilities of the cluster")
+		os.Exit(1)
+	}
+	go detector.Run(*capabilitiesRefresh, stop)
 
-	if err := start(mgr, secondaryCache); err != nil {
+	// Setup all Controllers
+	if err := controller.AddToManager(mgr, infraCache, watchdog, elector, detector, operatorConfigStore); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
-}
-
-func start(mgr manager.Manager, cache cache.Cache) error {
-	stop := signals.SetupSignalHandler()
-	errChan := make(chan error)
+	preflight(mgr, namespace, operatorConfig.InfraNamespace, detector.Get().Platform())
 
-	go func() {
-		// Start secondary cache for openshift-infra ns.
-		if err := cache.Start(stop); err != nil {
-			errChan <- err
+	healthServer.AddReadinessCheck("cache", health.CacheSynced(mgr.GetCache()))
+	healthServer.AddReadinessCheck("openshift-infra-cache", func(req *http.Request) error {
+		// the cache is started only when online-registration is enabled
+		if !infraCache.Started() {
+			return nil
 		}
-	}()
+		return health.CacheSynced(infraCache)(req)
+	})
+	if *checkClusterSvc {
+		healthServer.AddReadinessCheck("cluster-service", toolchainenabler.ClusterServiceCheck(client.NewClient(mgr.GetClient()), namespace, operatorConfigStore))
+	}
+
+	healthServer.AddReadinessCheck("leader", elector.Check)
 
-	log.Info("waiting for cache to sync")
-	if !cache.WaitForCacheSync(stop) {
-		return fmt.Errorf("failed to sync cache")
+	if elector != nil {
+		// caches are started regardless of leadership to keep them warm on standby replicas, leadership only enables
+		// reconciling. Exit when leadership is lost, so that no two replicas reconcile at the same time
+		go func() {
+			elector.Run(stop)
+			select {
+			case <-stop:
+			default:
+				log.Info("leadership lost, exiting")
+				os.Exit(1)
+			}
+		}()
 	}
-	log.Info("cache synced")
 
-	go func() {
-		log.Info("Starting the Cmd.")
-		errChan <- mgr.Start(stop)
-	}()
+	log.Info("Starting the Cmd.")
+	if err := mgr.Start(stop); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
 
-	return <-errChan
 }
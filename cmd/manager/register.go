@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/controller/toolchainenabler"
+)
+
+// register resolves cluster configuration and registers the cluster in cluster management service once, the same way
+// the operator does. The Service Account and OAuthClient, if the cluster serves it, have to be already created by the
+// operator
+func register(args []string) int {
+	fs, f := newCommandFlags("register", "Registers the cluster in cluster management service and exits.")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	cl, caps, tce, err := f.connect(ctx)
+	if err != nil {
+		log.Error(err, "failed to connect to the cluster")
+		return 1
+	}
+	operatorConfig, err := config.LoadOperatorConfig(ctx, cl, tce.Namespace)
+	if err != nil {
+		log.Error(err, "failed to load operator configuration")
+		return 1
+	}
+	cfg, err := config.Load(ctx, cl, tce, operatorConfig)
+	if err != nil {
+		log.Error(err, "failed to load toolchain configuration", "toolchainenabler", tce.Name)
+		return 1
+	}
+	data, err := cluster.NewConfigInformer(cl, tce.Namespace, operatorConfig.ServiceAccountName, cfg.GetClusterName(), caps, toolchainenabler.Platform(cl, tce, caps, operatorConfig)).Inform(ctx)
+	if err != nil {
+		log.Error(err, "failed to resolve cluster configuration", "toolchainenabler", tce.Name)
+		return 1
+	}
+	if err := cluster.NewClusterService(cfg).CreateCluster(ctx, data); err != nil {
+		log.Error(err, "failed to register the cluster", "cluster_service_url", cfg.GetClusterServiceURL())
+		return 1
+	}
+
+	log.Info("cluster registered in cluster management service", "cluster", data.APIURL, "cluster_service_url", cfg.GetClusterServiceURL())
+	return 0
+}
@@ -34,6 +34,8 @@ create-resources: login-as-admin
 	@oc apply -f $(DEPLOY_DIR)/olm-catalog/manifests/0.0.2/online-registration.ClusterRole.yaml
 	@echo "Creating namespaced scope resources..."
 	@cat $(DEPLOY_DIR)/namespace-manifests.yaml | sed s/\REPLACE_NAMESPACE/$(NAMESPACE)/ | oc apply -f -
+	@echo "Creating online-registration resources in openshift-infra..."
+	@cat $(DEPLOY_DIR)/online-registration-manifests.yaml | sed s/\REPLACE_NAMESPACE/$(NAMESPACE)/ | oc apply -f -
 
 .PHONY: create-cr
 create-cr:
@@ -68,6 +70,8 @@ clean-resources: login-as-admin
 	@oc delete -f $(DEPLOY_DIR)/crds/codeready_v1alpha1_toolchainenabler_cr.yaml || true
 	@echo "Deleting namespaced scope resources..."
 	@cat $(DEPLOY_DIR)/namespace-manifests.yaml | sed s/\REPLACE_NAMESPACE/$(NAMESPACE)/ | oc delete -f - || true
+	@echo "Deleting online-registration resources in openshift-infra..."
+	@cat $(DEPLOY_DIR)/online-registration-manifests.yaml | sed s/\REPLACE_NAMESPACE/$(NAMESPACE)/ | oc delete -f - || true
 	@echo "deleting cluster scoped resources"
 	@cat $(DEPLOY_DIR)/global-manifests.yaml | sed s/\REPLACE_NAMESPACE/$(NAMESPACE)/ | oc delete -f - || true
 	@echo "Deleting OAuthClient 'codeready-toolchain'"
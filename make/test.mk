@@ -22,15 +22,16 @@ test-coverage-html: ./vendor ./out/cover.out
 ./out/cover.out: ./vendor
 	$(Q)go test ${V_FLAG} -race $(shell go list ./... | grep -v /test/e2e) -failfast -coverprofile=cover.out -covermode=atomic -outputdir=./out
 
+# the operator runs locally next to the e2e tests, so that it reaches fake auth and cluster services started by them
 .PHONY: test-e2e-ci
-test-e2e-ci: ./vendor e2e-setup create-resources deploy-operator-for-ci
-	$(Q)operator-sdk test local ./test/e2e --no-setup --debug --namespace $(NAMESPACE) --go-test-flags "-v -timeout=15m"
+test-e2e-ci: ./vendor e2e-setup create-resources
+	$(Q)operator-sdk test local ./test/e2e --no-setup --up-local --debug --namespace $(NAMESPACE) --go-test-flags "-v -timeout=15m"
 
 .PHONY: test-e2e
-## Runs the e2e tests locally
-test-e2e: ./vendor e2e-setup create-resources deploy-operator
+## Runs the e2e tests locally against fake auth and cluster services
+test-e2e: ./vendor e2e-setup create-resources
 	$(info Running E2E test: $@)
-	$(Q)operator-sdk test local ./test/e2e --no-setup --debug --namespace $(NAMESPACE) --go-test-flags "-v -timeout=15m"
+	$(Q)operator-sdk test local ./test/e2e --no-setup --up-local --debug --namespace $(NAMESPACE) --go-test-flags "-v -timeout=15m"
 
 .PHONY: e2e-setup
 e2e-setup: e2e-cleanup
@@ -4,6 +4,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	templatev1 "github.com/openshift/api/template/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -16,5 +17,6 @@ func AddToScheme(s *runtime.Scheme) error {
 	AddToSchemes.Register(oauthv1.Install)
 	AddToSchemes.Register(configv1.Install)
 	AddToSchemes.Register(routev1.Install)
+	AddToSchemes.Register(templatev1.Install)
 	return AddToSchemes.AddToScheme(s)
 }
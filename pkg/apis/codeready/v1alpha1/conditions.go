@@ -0,0 +1,96 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetCondition returns the condition of the given type or nil if there is no such condition
+func (s *ToolChainEnablerStatus) GetCondition(t ConditionType) *Condition {
+	return getCondition(s.Conditions, t)
+}
+
+// SetCondition adds or updates the condition of the same type. Last transition time is changed only when condition
+// status changes. It returns true if anything in the status has been changed
+func (s *ToolChainEnablerStatus) SetCondition(c Condition) bool {
+	return setCondition(&s.Conditions, c)
+}
+
+// RemoveCondition removes the condition of the given type. It returns true if there was such condition
+func (s *ToolChainEnablerStatus) RemoveCondition(t ConditionType) bool {
+	return removeCondition(&s.Conditions, t)
+}
+
+// IsConditionTrue returns true if the condition of the given type exists and its status is true
+func (s *ToolChainEnablerStatus) IsConditionTrue(t ConditionType) bool {
+	return isConditionTrue(s.Conditions, t)
+}
+
+// GetMember returns status of the member cluster of the given name or nil if there is no such member
+func (s *ToolChainEnablerStatus) GetMember(name string) *MemberStatus {
+	for i := range s.Members {
+		if s.Members[i].Name == name {
+			return &s.Members[i]
+		}
+	}
+	return nil
+}
+
+// GetCondition returns the condition of the given type or nil if there is no such condition
+func (s *MemberStatus) GetCondition(t ConditionType) *Condition {
+	return getCondition(s.Conditions, t)
+}
+
+// SetCondition adds or updates the condition of the same type. Last transition time is changed only when condition
+// status changes. It returns true if anything in the status has been changed
+func (s *MemberStatus) SetCondition(c Condition) bool {
+	return setCondition(&s.Conditions, c)
+}
+
+// IsConditionTrue returns true if the condition of the given type exists and its status is true
+func (s *MemberStatus) IsConditionTrue(t ConditionType) bool {
+	return isConditionTrue(s.Conditions, t)
+}
+
+func getCondition(conditions []Condition, t ConditionType) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == t {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func setCondition(conditions *[]Condition, c Condition) bool {
+	existing := getCondition(*conditions, c.Type)
+	if existing == nil {
+		c.LastTransitionTime = metav1.Now()
+		*conditions = append(*conditions, c)
+		return true
+	}
+	if existing.Status == c.Status && existing.Reason == c.Reason && existing.Message == c.Message {
+		return false
+	}
+	if existing.Status != c.Status {
+		existing.LastTransitionTime = metav1.Now()
+	}
+	existing.Status = c.Status
+	existing.Reason = c.Reason
+	existing.Message = c.Message
+	return true
+}
+
+func removeCondition(conditions *[]Condition, t ConditionType) bool {
+	for i := range *conditions {
+		if (*conditions)[i].Type == t {
+			*conditions = append((*conditions)[:i], (*conditions)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func isConditionTrue(conditions []Condition, t ConditionType) bool {
+	c := getCondition(conditions, t)
+	return c != nil && c.Status == corev1.ConditionTrue
+}
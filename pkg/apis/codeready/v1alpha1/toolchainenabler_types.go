@@ -1,6 +1,9 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -15,12 +18,299 @@ type ToolChainEnablerSpec struct {
 	ClusterURL          string `json:"clusterURL"`
 	ClusterName         string `json:"clusterName"`
 	ToolchainSecretName string `json:"toolchainSecretName"`
+
+	// HTTPConnectTimeout is the maximum time to wait for connection to auth and cluster service being established
+	HTTPConnectTimeout *metav1.Duration `json:"httpConnectTimeout,omitempty"`
+	// HTTPResponseTimeout is the maximum time to wait for auth and cluster service response headers
+	HTTPResponseTimeout *metav1.Duration `json:"httpResponseTimeout,omitempty"`
+
+	// CABundleConfigMap is the name of the ConfigMap with additional trusted CA certificates under 'ca-bundle.crt' key
+	// used to verify auth and cluster service
+	CABundleConfigMap string `json:"caBundleConfigMap,omitempty"`
+	// ClientCertificateSecret is the name of the Secret with 'tls.crt' and 'tls.key' used as client certificate
+	// when auth and cluster service require mutual TLS
+	ClientCertificateSecret string `json:"clientCertificateSecret,omitempty"`
+	// Proxy configures the proxy used when talking to auth and cluster service
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+
+	// OnlineRegistration configures online-registration resources required by manage.openshift.com
+	OnlineRegistration OnlineRegistrationSpec `json:"onlineRegistration,omitempty"`
+
+	// ResyncPeriod is the period of verification of the cluster registered in cluster management service. The cluster
+	// is registered again if it's missing or doesn't match
+	ResyncPeriod *metav1.Duration `json:"resyncPeriod,omitempty"`
+
+	// ProjectTemplate configures defaults of projects self-provisioned by users. It's rendered into project request
+	// template set in project configuration of the cluster, the template is removed if not set. The template is shared
+	// by the cluster, a ToolChainEnabler setting different template than an older one is rejected as Degraded
+	ProjectTemplate *ProjectTemplateSpec `json:"projectTemplate,omitempty"`
+
+	// Capacity configures thresholds of cluster capacity. The cluster is flagged as capacity exhausted in cluster
+	// management service once any of them is crossed, so that new users are placed on other clusters
+	Capacity *CapacitySpec `json:"capacity,omitempty"`
+
+	// AdoptionPolicy decides what happens to Service Account, ClusterRoleBindings and OAuthClient which already exist
+	// but aren't owned by the operator, e.g. as they were created manually before the operator was deployed. Ignore
+	// if not set
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// Members are remote clusters provisioned and registered in cluster management service by the operator in
+	// addition to the cluster it runs on
+	Members []MemberSpec `json:"members,omitempty"`
+
+	// Kubernetes configures registration of clusters which serve no OpenShift API. It's ignored on OpenShift
+	Kubernetes *KubernetesSpec `json:"kubernetes,omitempty"`
+}
+
+// KubernetesSpec defines what can't be read from OpenShift APIs on clusters which don't serve them
+type KubernetesSpec struct {
+	// APIURL is the public URL of the API server registered in cluster management service, it's formed from the
+	// cluster name if not set. API URL of members is taken from their kubeconfig
+	APIURL string `json:"apiURL,omitempty"`
+	// IngressDomain is the domain of applications exposed by Ingresses, registered as app DNS. It's required, as
+	// Kubernetes doesn't tell the domain its ingress controller serves
+	IngressDomain string `json:"ingressDomain,omitempty"`
+	// OIDCClientSecret is the name of the Secret with 'client-id', 'client-secret' and optional 'default-scope' of OIDC
+	// client auth service logs in users with. The client is registered in identity provider of the cluster beforehand.
+	// Kubernetes members are registered with the same client
+	OIDCClientSecret string `json:"oidcClientSecret,omitempty"`
+}
+
+// MemberSpec defines remote cluster provisioned by the operator
+type MemberSpec struct {
+	// Name is the cluster name registered in cluster management service, it identifies the member in status
+	Name string `json:"name"`
+	// KubeconfigSecret is the name of the Secret with kubeconfig of the member cluster under 'kubeconfig' key
+	KubeconfigSecret string `json:"kubeconfigSecret"`
+	// Namespace on the member cluster where toolchain-sre service account is created, namespace of ToolChainEnabler
+	// if not set
+	Namespace string `json:"namespace,omitempty"`
+	// IngressDomain is the domain of applications registered as app DNS of Kubernetes member, it's required for members
+	// which serve no routes and ignored otherwise
+	IngressDomain string `json:"ingressDomain,omitempty"`
+}
+
+// AdoptionPolicy is the policy applied to existing resources which aren't owned by the operator
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyAdopt takes ownership of the existing resource and converges it to the desired state
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+	// AdoptionPolicyFail leaves the existing resource as it is and marks ToolChainEnabler degraded
+	AdoptionPolicyFail AdoptionPolicy = "Fail"
+	// AdoptionPolicyIgnore leaves the existing resource as it is, unowned and unreconciled
+	AdoptionPolicyIgnore AdoptionPolicy = "Ignore"
+)
+
+// CapacitySpec defines thresholds of cluster capacity, zero disables the threshold
+type CapacitySpec struct {
+	// MaxCPURequestedPercent is the maximum percentage of allocatable CPU of worker nodes requested by pods
+	MaxCPURequestedPercent int `json:"maxCPURequestedPercent,omitempty"`
+	// MaxMemoryRequestedPercent is the maximum percentage of allocatable memory of worker nodes requested by pods
+	MaxMemoryRequestedPercent int `json:"maxMemoryRequestedPercent,omitempty"`
+	// MaxUserProjects is the maximum number of projects self-provisioned by users
+	MaxUserProjects int `json:"maxUserProjects,omitempty"`
+}
+
+// ProjectTemplateSpec defines resources created in each project self-provisioned by users
+type ProjectTemplateSpec struct {
+	// ResourceQuota is the default quota of the project
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+	// LimitRange is the default limit range of the project
+	LimitRange *corev1.LimitRangeSpec `json:"limitRange,omitempty"`
+	// NetworkPolicies are the default network policies of the project
+	NetworkPolicies []ProjectNetworkPolicy `json:"networkPolicies,omitempty"`
+}
+
+// ProjectNetworkPolicy is a network policy created in each project self-provisioned by users
+type ProjectNetworkPolicy struct {
+	Name string                         `json:"name"`
+	Spec networkingv1.NetworkPolicySpec `json:"spec"`
+}
+
+// OnlineRegistrationSpec defines whether online-registration service account and cluster role binding are created in
+// openshift-infra namespace
+type OnlineRegistrationSpec struct {
+	// Enabled creates online-registration resources if true or not set, as ToolChainEnablers created before it could be
+	// disabled rely on them, and deletes resources created by the operator if false
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// IsEnabled returns true unless online-registration is explicitly disabled
+func (s OnlineRegistrationSpec) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// ProxySpec defines proxy to be used for outgoing HTTP(S) calls. If not set, HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables of the operator are used
+type ProxySpec struct {
+	HTTPProxy  string `json:"httpProxy,omitempty"`
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	NoProxy    string `json:"noProxy,omitempty"`
 }
 
 // ToolChainEnablerStatus defines the observed state of ToolChainEnabler
 type ToolChainEnablerStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Verification is the result of last verification of the cluster registered in cluster management service
+	Verification *VerificationStatus `json:"verification,omitempty"`
+	// ServiceAccountToken describes token of toolchain-sre service account registered in cluster management service
+	ServiceAccountToken *TokenStatus `json:"serviceAccountToken,omitempty"`
+	// Capacity is the cluster capacity computed last time
+	Capacity *CapacityStatus `json:"capacity,omitempty"`
+	// Drift lists resources which differ from their desired state while reconciliation is paused
+	Drift []string `json:"drift,omitempty"`
+	// Members reports state of member clusters
+	Members []MemberStatus `json:"members,omitempty"`
+	// Capabilities are APIs served by the cluster detected by the operator
+	Capabilities *CapabilitiesStatus `json:"capabilities,omitempty"`
+	// ProvisionedNames are names from operator configuration the resources of the cluster have been provisioned with,
+	// they're kept to clean up the resources once the names change
+	ProvisionedNames *ProvisionedNames `json:"provisionedNames,omitempty"`
+}
+
+// ProvisionedNames are names of resources configured by operator configuration
+type ProvisionedNames struct {
+	// ServiceAccountName is the name of toolchain-sre service account
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// OAuthClientName is the name of OAuthClient used by auth service
+	OAuthClientName string `json:"oauthClientName,omitempty"`
+	// InfraNamespace is the namespace of online-registration service account, empty on members
+	InfraNamespace string `json:"infraNamespace,omitempty"`
+}
+
+// MemberStatus describes state of member cluster
+type MemberStatus struct {
+	Name string `json:"name"`
+	// APIURL is the API server URL of the member cluster
+	APIURL string `json:"apiURL,omitempty"`
+	// KubeconfigSecret and Namespace are those of the spec the member has been connected with, they're kept to clean
+	// up the member once it's removed from the spec
+	KubeconfigSecret string `json:"kubeconfigSecret,omitempty"`
+	Namespace        string `json:"namespace,omitempty"`
+	// RegisteredAPIURL is the API URL the member is registered with in cluster management service
+	RegisteredAPIURL string      `json:"registeredAPIURL,omitempty"`
+	Conditions       []Condition `json:"conditions,omitempty"`
+	// Capabilities are APIs served by the member cluster, detected once it's connected
+	Capabilities *CapabilitiesStatus `json:"capabilities,omitempty"`
+	// ProvisionedNames are names from operator configuration the member has been provisioned with
+	ProvisionedNames *ProvisionedNames `json:"provisionedNames,omitempty"`
+}
+
+// CapabilitiesStatus describes APIs served by the cluster which change behaviour of the operator
+type CapabilitiesStatus struct {
+	// Config is true if cluster configuration API config.openshift.io is served
+	Config bool `json:"config"`
+	// Route is true if route.openshift.io API is served
+	Route bool `json:"route"`
+	// OAuth is true if oauth.openshift.io API is served
+	OAuth bool `json:"oauth"`
+	// TokenRequest is true if tokens of service accounts can be minted via TokenRequest API
+	TokenRequest bool `json:"tokenRequest"`
+	// OpenShiftVersion is the major version of OpenShift, empty on other Kubernetes distributions
+	OpenShiftVersion string `json:"openShiftVersion,omitempty"`
+	// Platform is OpenShift if any OpenShift API used by the operator is served, Kubernetes otherwise
+	Platform string `json:"platform,omitempty"`
+}
+
+// CapacityStatus describes capacity of the cluster
+type CapacityStatus struct {
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+	// AllocatableCPU and RequestedCPU are the sums of allocatable and requested CPU of worker nodes
+	AllocatableCPU resource.Quantity `json:"allocatableCPU"`
+	RequestedCPU   resource.Quantity `json:"requestedCPU"`
+	// AllocatableMemory and RequestedMemory are the sums of allocatable and requested memory of worker nodes
+	AllocatableMemory resource.Quantity `json:"allocatableMemory"`
+	RequestedMemory   resource.Quantity `json:"requestedMemory"`
+	// CPURequestedPercent and MemoryRequestedPercent are the percentages of allocatable resources requested by pods
+	CPURequestedPercent    int `json:"cpuRequestedPercent"`
+	MemoryRequestedPercent int `json:"memoryRequestedPercent"`
+	// UserProjects is the number of projects self-provisioned by users
+	UserProjects int `json:"userProjects"`
+	// Exhausted is true when any of thresholds is crossed
+	Exhausted bool `json:"exhausted"`
+	// Reasons lists thresholds crossed
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// VerificationResult is the result of verification of the registered cluster
+type VerificationResult string
+
+const (
+	// VerificationMatched means that the registered cluster matches the cluster configuration
+	VerificationMatched VerificationResult = "Matched"
+	// VerificationReregistered means that the cluster was missing or didn't match, and has been registered again
+	VerificationReregistered VerificationResult = "Reregistered"
+	// VerificationFailed means that the registered cluster couldn't be verified or registered again
+	VerificationFailed VerificationResult = "Failed"
+)
+
+// TokenMechanism is the way token of toolchain-sre service account is obtained
+type TokenMechanism string
+
+const (
+	// TokenMechanismSecret is a long-lived token populated by the API server into Secret created by the operator
+	TokenMechanismSecret TokenMechanism = "Secret"
+	// TokenMechanismTokenRequest is a bounded token minted via TokenRequest API and renewed before it expires
+	TokenMechanismTokenRequest TokenMechanism = "TokenRequest"
+)
+
+// TokenStatus describes token of toolchain-sre service account registered in cluster management service
+type TokenStatus struct {
+	Mechanism TokenMechanism `json:"mechanism"`
+	// IssueTime is the time when bounded token was minted, nil for long-lived token
+	IssueTime *metav1.Time `json:"issueTime,omitempty"`
+	// ExpirationTime is the time when bounded token expires, nil for long-lived token
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+}
+
+// VerificationStatus describes last verification of the cluster registered in cluster management service
+type VerificationStatus struct {
+	LastVerificationTime metav1.Time        `json:"lastVerificationTime"`
+	Result               VerificationResult `json:"result"`
+	Message              string             `json:"message,omitempty"`
+}
+
+// ConditionType is the type of ToolChainEnabler condition
+type ConditionType string
+
+const (
+	// OnlineRegistrationReady is true when online-registration service account and cluster role binding exist
+	OnlineRegistrationReady ConditionType = "OnlineRegistrationReady"
+	// ClusterRegistered is true when cluster configuration has been saved in cluster management service
+	ClusterRegistered ConditionType = "ClusterRegistered"
+	// OperatorAuthorized is true when the operator is granted all the permissions it needs to reconcile ToolChainEnabler
+	OperatorAuthorized ConditionType = "OperatorAuthorized"
+	// ProjectTemplateReady is true when project request template is rendered from the spec and set in project
+	// configuration of the cluster
+	ProjectTemplateReady ConditionType = "ProjectTemplateReady"
+	// CapacityReported is true when capacity of the cluster has been computed and its capacity-exhausted flag set in
+	// cluster management service
+	CapacityReported ConditionType = "CapacityReported"
+	// Degraded is true when a resource provisioned by the operator exists but isn't owned by it and adoption policy
+	// is Fail, or when project request template conflicts with the one of another ToolChainEnabler
+	Degraded ConditionType = "Degraded"
+	// Paused is true when reconciliation is suspended by annotation of ToolChainEnabler, no change is made meanwhile
+	Paused ConditionType = "Paused"
+	// MemberConnected is true when API server of member cluster is reachable with kubeconfig from the referred Secret
+	MemberConnected ConditionType = "Connected"
+	// MemberProvisioned is true when toolchain-sre service account, its role bindings and OAuthClient exist on member
+	// cluster
+	MemberProvisioned ConditionType = "Provisioned"
+	// MemberCleanedUp is false when resources provisioned on member cluster can't be deleted from it, e.g. as it's
+	// removed from the spec while it can't be reached. Such member is kept in status until it's cleaned up
+	MemberCleanedUp ConditionType = "CleanedUp"
+)
+
+// Condition describes the state of ToolChainEnabler at a certain point
+type Condition struct {
+	Type               ConditionType          `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,191 @@
+package capabilities
+
+import (
+	"sync"
+	"time"
+
+	errs "github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("capabilities")
+
+const (
+	// ConfigGroup serves cluster configuration of OpenShift 4, e.g. Infrastructure and Project configuration
+	ConfigGroup = "config.openshift.io"
+	// RouteGroup serves routes of OpenShift
+	RouteGroup = "route.openshift.io"
+	// OAuthGroup serves OAuthClients of OpenShift
+	OAuthGroup = "oauth.openshift.io"
+
+	// DefaultRefreshPeriod is the period of detecting capabilities again, e.g. once the cluster is upgraded
+	DefaultRefreshPeriod = 10 * time.Minute
+)
+
+// Capabilities are APIs served by the cluster which change behaviour of the operator
+type Capabilities struct {
+	// Config is true if config.openshift.io API group is served
+	Config bool
+	// Route is true if route.openshift.io API group is served
+	Route bool
+	// OAuth is true if oauth.openshift.io API group is served
+	OAuth bool
+	// TokenRequest is true if tokens of service accounts can be minted via TokenRequest API
+	TokenRequest bool
+	// OpenShiftVersion is the major version of OpenShift, empty on other Kubernetes distributions
+	OpenShiftVersion string
+}
+
+// Platform is the kind of cluster, it decides how the cluster is provisioned and what is registered in cluster
+// management service
+type Platform string
+
+const (
+	// PlatformOpenShift clusters serve routes and OAuthClients, OpenShift 4 also serves cluster configuration
+	PlatformOpenShift Platform = "OpenShift"
+	// PlatformKubernetes clusters serve no OpenShift API, app DNS comes from Ingresses and users are logged in with
+	// OIDC client registered in identity provider of the cluster
+	PlatformKubernetes Platform = "Kubernetes"
+)
+
+// Platform returns OpenShift if the cluster serves any OpenShift API used by the operator, Kubernetes otherwise
+func (c Capabilities) Platform() Platform {
+	if c.Config || c.Route || c.OAuth {
+		return PlatformOpenShift
+	}
+	return PlatformKubernetes
+}
+
+// RestartRequired returns names of capabilities which differ from the given ones and apply only once the operator
+// restarts, as the way token of toolchain-sre is obtained and the watched resources are picked at startup
+func (c Capabilities) RestartRequired(other Capabilities) []string {
+	var names []string
+	if c.TokenRequest != other.TokenRequest {
+		names = append(names, "TokenRequest")
+	}
+	if c.OAuth != other.OAuth {
+		names = append(names, "OAuth")
+	}
+	if c.Config != other.Config {
+		names = append(names, "Config")
+	}
+	return names
+}
+
+// OpenShift4 are capabilities of OpenShift 4 cluster, assumed when there is no Detector
+var OpenShift4 = Capabilities{Config: true, Route: true, OAuth: true, TokenRequest: true, OpenShiftVersion: "4"}
+
+// Discovery is the part of discovery client capabilities are detected with
+type Discovery interface {
+	discovery.ServerGroupsInterface
+	discovery.ServerResourcesInterface
+}
+
+// Detect discovers API groups and resources served by the cluster
+func Detect(d Discovery) (Capabilities, error) {
+	groups, err := d.ServerGroups()
+	if err != nil {
+		return Capabilities{}, errs.Wrapf(err, "failed to discover API groups")
+	}
+	c := Capabilities{
+		Config: served(groups, ConfigGroup),
+		Route:  served(groups, RouteGroup),
+		OAuth:  served(groups, OAuthGroup),
+	}
+
+	resources, err := d.ServerResourcesForGroupVersion("v1")
+	if err != nil {
+		return Capabilities{}, errs.Wrapf(err, "failed to discover resources of core API group")
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == "serviceaccounts/token" {
+			c.TokenRequest = true
+		}
+	}
+
+	// cluster configuration API has been introduced in OpenShift 4, routes are served by any OpenShift
+	switch {
+	case c.Config:
+		c.OpenShiftVersion = "4"
+	case c.Route:
+		c.OpenShiftVersion = "3"
+	}
+	return c, nil
+}
+
+func served(groups *metav1.APIGroupList, name string) bool {
+	for _, g := range groups.Groups {
+		if g.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Detector keeps capabilities of the cluster detected last time
+type Detector struct {
+	discovery Discovery
+	onChange  func(current, previous Capabilities)
+	mu        sync.RWMutex
+	current   Capabilities
+}
+
+// NewDetector detects capabilities of the cluster with the given discovery client. onChange, if set, is called
+// whenever refreshed capabilities change
+func NewDetector(d Discovery, onChange func(current, previous Capabilities)) (*Detector, error) {
+	c, err := Detect(d)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("cluster capabilities detected", "capabilities", c)
+	return &Detector{discovery: d, onChange: onChange, current: c}, nil
+}
+
+// Static returns Detector which always returns the given capabilities, e.g. in tests
+func Static(c Capabilities) *Detector {
+	return &Detector{current: c}
+}
+
+// Get returns capabilities detected last time, or capabilities of OpenShift 4 if the Detector is nil
+func (d *Detector) Get() Capabilities {
+	if d == nil {
+		return OpenShift4
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.current
+}
+
+// Refresh detects capabilities again, capabilities detected last time are kept if it fails
+func (d *Detector) Refresh() error {
+	if d.discovery == nil {
+		return nil
+	}
+	c, err := Detect(d.discovery)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	previous := d.current
+	d.current = c
+	d.mu.Unlock()
+	if c != previous {
+		log.Info("cluster capabilities changed", "capabilities", c, "previous", previous)
+		if d.onChange != nil {
+			d.onChange(c, previous)
+		}
+	}
+	return nil
+}
+
+// Run refreshes capabilities with the given period until the stop channel is closed
+func (d *Detector) Run(period time.Duration, stop <-chan struct{}) {
+	wait.Until(func() {
+		if err := d.Refresh(); err != nil {
+			log.Error(err, "failed to refresh cluster capabilities")
+		}
+	}, period, stop)
+}
@@ -0,0 +1,181 @@
+package capabilities
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+// fakeDiscovery serves the given API groups and resources of core API group
+type fakeDiscovery struct {
+	discovery.ServerGroupsInterface
+	discovery.ServerResourcesInterface
+	groups    []string
+	resources []metav1.APIResource
+	err       error
+}
+
+func (d *fakeDiscovery) ServerGroups() (*metav1.APIGroupList, error) {
+	list := &metav1.APIGroupList{}
+	for _, g := range d.groups {
+		list.Groups = append(list.Groups, metav1.APIGroup{Name: g})
+	}
+	return list, d.err
+}
+
+func (d *fakeDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	return &metav1.APIResourceList{GroupVersion: groupVersion, APIResources: d.resources}, d.err
+}
+
+func TestDetect(t *testing.T) {
+
+	t.Run("openshift 4", func(t *testing.T) {
+		//given
+		d := &fakeDiscovery{
+			groups:    []string{"", "apps", ConfigGroup, RouteGroup, OAuthGroup},
+			resources: []metav1.APIResource{{Name: "serviceaccounts"}, {Name: "serviceaccounts/token"}},
+		}
+
+		//when
+		c, err := Detect(d)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, OpenShift4, c)
+		assert.Equal(t, PlatformOpenShift, c.Platform())
+	})
+
+	t.Run("openshift 3", func(t *testing.T) {
+		//given
+		d := &fakeDiscovery{
+			groups:    []string{"", "apps", RouteGroup, OAuthGroup},
+			resources: []metav1.APIResource{{Name: "serviceaccounts"}},
+		}
+
+		//when
+		c, err := Detect(d)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, Capabilities{Route: true, OAuth: true, OpenShiftVersion: "3"}, c)
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		//given
+		d := &fakeDiscovery{
+			groups:    []string{"", "apps"},
+			resources: []metav1.APIResource{{Name: "serviceaccounts"}, {Name: "serviceaccounts/token"}},
+		}
+
+		//when
+		c, err := Detect(d)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, Capabilities{TokenRequest: true}, c)
+		assert.Equal(t, PlatformKubernetes, c.Platform())
+	})
+
+	t.Run("discovery failed", func(t *testing.T) {
+		//given
+		d := &fakeDiscovery{err: errors.New("connection refused")}
+
+		//when
+		_, err := Detect(d)
+
+		//then
+		assert.EqualError(t, err, "failed to discover API groups: connection refused")
+	})
+}
+
+func TestDetector(t *testing.T) {
+
+	t.Run("refreshed", func(t *testing.T) {
+		//given
+		d := &fakeDiscovery{groups: []string{RouteGroup, OAuthGroup}}
+		var changed []Capabilities
+		detector, err := NewDetector(d, func(current, previous Capabilities) {
+			changed = append(changed, current, previous)
+		})
+		require.NoError(t, err)
+		require.Equal(t, "3", detector.Get().OpenShiftVersion)
+
+		//when
+		d.groups = append(d.groups, ConfigGroup)
+		err = detector.Refresh()
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, "4", detector.Get().OpenShiftVersion)
+		assert.True(t, detector.Get().Config)
+		require.Len(t, changed, 2)
+		assert.Equal(t, detector.Get(), changed[0])
+		assert.Equal(t, []string{"Config"}, changed[1].RestartRequired(changed[0]))
+
+		//when nothing changes
+		err = detector.Refresh()
+
+		//then
+		require.NoError(t, err)
+		assert.Len(t, changed, 2)
+	})
+
+	t.Run("kept when refresh failed", func(t *testing.T) {
+		//given
+		d := &fakeDiscovery{groups: []string{RouteGroup, OAuthGroup}}
+		detector, err := NewDetector(d, nil)
+		require.NoError(t, err)
+
+		//when
+		d.err = errors.New("connection refused")
+		err = detector.Refresh()
+
+		//then
+		assert.EqualError(t, err, "failed to discover API groups: connection refused")
+		assert.Equal(t, "3", detector.Get().OpenShiftVersion)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		//given
+		detector := Static(Capabilities{Route: true})
+
+		//when
+		err := detector.Refresh()
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, Capabilities{Route: true}, detector.Get())
+	})
+
+	t.Run("nil detector assumes openshift 4", func(t *testing.T) {
+		//given
+		var detector *Detector
+
+		//then
+		assert.Equal(t, OpenShift4, detector.Get())
+	})
+}
+
+func TestRestartRequired(t *testing.T) {
+
+	t.Run("none", func(t *testing.T) {
+		//given
+		upgraded := OpenShift4
+		upgraded.OpenShiftVersion = "5"
+
+		//then
+		assert.Empty(t, OpenShift4.RestartRequired(upgraded))
+	})
+
+	t.Run("token mechanism and watched resources", func(t *testing.T) {
+		//given
+		openShift3 := Capabilities{Route: true, OpenShiftVersion: "3"}
+
+		//then
+		assert.Equal(t, []string{"TokenRequest", "OAuth", "Config"}, OpenShift4.RestartRequired(openShift3))
+	})
+}
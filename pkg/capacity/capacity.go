@@ -0,0 +1,208 @@
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	errs "github.com/pkg/errors"
+	"gopkg.in/inf.v0"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// WorkerRoleLabel marks worker nodes, all schedulable nodes are considered workers if no node has it
+	WorkerRoleLabel = "node-role.kubernetes.io/worker"
+	// RequesterAnnotation is set on projects self-provisioned by users
+	RequesterAnnotation = "openshift.io/requester"
+)
+
+// Usage is the capacity of the cluster used by pods and users
+type Usage struct {
+	AllocatableCPU    resource.Quantity
+	RequestedCPU      resource.Quantity
+	AllocatableMemory resource.Quantity
+	RequestedMemory   resource.Quantity
+	UserProjects      int
+}
+
+// pageSize is the number of resources listed at once, so that resources of large clusters aren't loaded into memory
+// all together
+const pageSize = 500
+
+// Compute sums allocatable and requested resources of worker nodes and counts projects self-provisioned by users
+func Compute(ctx context.Context, cl client.Client) (Usage, error) {
+	var u Usage
+
+	var nodes []corev1.Node
+	err := listPages(ctx, cl, func() runtime.Object { return &corev1.NodeList{} }, func(list runtime.Object) {
+		nodes = append(nodes, list.(*corev1.NodeList).Items...)
+	})
+	if err != nil {
+		return u, errs.Wrapf(err, "failed to list nodes")
+	}
+	workers := workerNodes(nodes)
+	for _, node := range workers {
+		u.AllocatableCPU.Add(*node.Status.Allocatable.Cpu())
+		u.AllocatableMemory.Add(*node.Status.Allocatable.Memory())
+	}
+
+	err = listPages(ctx, cl, func() runtime.Object { return &corev1.PodList{} }, func(list runtime.Object) {
+		for _, pod := range list.(*corev1.PodList).Items {
+			if _, ok := workers[pod.Spec.NodeName]; !ok {
+				continue
+			}
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			requests := podRequests(pod)
+			u.RequestedCPU.Add(*requests.Cpu())
+			u.RequestedMemory.Add(*requests.Memory())
+		}
+	})
+	if err != nil {
+		return u, errs.Wrapf(err, "failed to list pods")
+	}
+
+	err = listPages(ctx, cl, func() runtime.Object { return &corev1.NamespaceList{} }, func(list runtime.Object) {
+		for _, ns := range list.(*corev1.NamespaceList).Items {
+			if _, ok := ns.Annotations[RequesterAnnotation]; ok {
+				u.UserProjects++
+			}
+		}
+	})
+	if err != nil {
+		return u, errs.Wrapf(err, "failed to list namespaces")
+	}
+	return u, nil
+}
+
+// listPages lists resources page by page into lists created by the given function and passes each page to the given
+// consumer
+func listPages(ctx context.Context, cl client.Client, newList func() runtime.Object, consume func(list runtime.Object)) error {
+	continueToken := ""
+	for {
+		list := newList()
+		if err := cl.List(ctx, &client.ListOptions{Raw: &metav1.ListOptions{Limit: pageSize, Continue: continueToken}}, list); err != nil {
+			return err
+		}
+		consume(list)
+		accessor, err := meta.ListAccessor(list)
+		if err != nil {
+			return err
+		}
+		continueToken = accessor.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
+
+// workerNodes returns schedulable worker nodes by their names
+func workerNodes(nodes []corev1.Node) map[string]corev1.Node {
+	labeled := false
+	for _, node := range nodes {
+		if _, ok := node.Labels[WorkerRoleLabel]; ok {
+			labeled = true
+			break
+		}
+	}
+	workers := map[string]corev1.Node{}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		if _, ok := node.Labels[WorkerRoleLabel]; labeled && !ok {
+			continue
+		}
+		workers[node.Name] = node
+	}
+	return workers
+}
+
+// podRequests returns effective resource requests of the given pod, init containers run one by one before the others
+func podRequests(pod corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		for name, quantity := range c.Resources.Requests {
+			sum := requests[name]
+			sum.Add(quantity)
+			requests[name] = sum
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		for name, quantity := range c.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return requests
+}
+
+// CPUPercent returns the percentage of allocatable CPU requested by pods
+func (u Usage) CPUPercent() int {
+	return percent(u.RequestedCPU, u.AllocatableCPU)
+}
+
+// MemoryPercent returns the percentage of allocatable memory requested by pods
+func (u Usage) MemoryPercent() int {
+	return percent(u.RequestedMemory, u.AllocatableMemory)
+}
+
+// percent computes the percentage in arbitrary precision, as milli-units of memory of a whole cluster overflow int64
+func percent(requested, allocatable resource.Quantity) int {
+	if allocatable.IsZero() {
+		return 0
+	}
+	hundredfold := new(inf.Dec).Mul(requested.AsDec(), inf.NewDec(100, 0))
+	p := new(inf.Dec).QuoRound(hundredfold, allocatable.AsDec(), 0, inf.RoundDown)
+	return int(p.UnscaledBig().Int64())
+}
+
+// Status describes the given usage and the thresholds of the given spec it crosses
+func Status(u Usage, spec codereadyv1alpha1.CapacitySpec) *codereadyv1alpha1.CapacityStatus {
+	status := &codereadyv1alpha1.CapacityStatus{
+		LastUpdateTime:         metav1.Now(),
+		AllocatableCPU:         u.AllocatableCPU,
+		RequestedCPU:           u.RequestedCPU,
+		AllocatableMemory:      u.AllocatableMemory,
+		RequestedMemory:        u.RequestedMemory,
+		CPURequestedPercent:    u.CPUPercent(),
+		MemoryRequestedPercent: u.MemoryPercent(),
+		UserProjects:           u.UserProjects,
+	}
+	if spec.MaxCPURequestedPercent > 0 && status.CPURequestedPercent >= spec.MaxCPURequestedPercent {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%d%% of CPU requested, maximum is %d%%", status.CPURequestedPercent, spec.MaxCPURequestedPercent))
+	}
+	if spec.MaxMemoryRequestedPercent > 0 && status.MemoryRequestedPercent >= spec.MaxMemoryRequestedPercent {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%d%% of memory requested, maximum is %d%%", status.MemoryRequestedPercent, spec.MaxMemoryRequestedPercent))
+	}
+	if spec.MaxUserProjects > 0 && status.UserProjects >= spec.MaxUserProjects {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%d user projects, maximum is %d", status.UserProjects, spec.MaxUserProjects))
+	}
+	status.Exhausted = len(status.Reasons) > 0
+	return status
+}
+
+// Changed returns true if the given current status differs from the previous one in anything but the time of update
+func Changed(previous, current *codereadyv1alpha1.CapacityStatus) bool {
+	if previous == nil || current == nil {
+		return previous != current
+	}
+	return previous.Exhausted != current.Exhausted ||
+		!reflect.DeepEqual(previous.Reasons, current.Reasons) ||
+		previous.AllocatableCPU.Cmp(current.AllocatableCPU) != 0 ||
+		previous.RequestedCPU.Cmp(current.RequestedCPU) != 0 ||
+		previous.AllocatableMemory.Cmp(current.AllocatableMemory) != 0 ||
+		previous.RequestedMemory.Cmp(current.RequestedMemory) != 0 ||
+		previous.CPURequestedPercent != current.CPURequestedPercent ||
+		previous.MemoryRequestedPercent != current.MemoryRequestedPercent ||
+		previous.UserProjects != current.UserProjects
+}
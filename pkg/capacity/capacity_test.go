@@ -0,0 +1,191 @@
+package capacity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCompute(t *testing.T) {
+	//given
+	cl := fake.NewFakeClient(
+		node("master", "node-role.kubernetes.io/master", "4", "16Gi"),
+		node("worker-1", WorkerRoleLabel, "4", "8Gi"),
+		node("worker-2", WorkerRoleLabel, "4", "8Gi"),
+		pod("on-master", "master", "2", "4Gi", corev1.PodRunning),
+		pod("app", "worker-1", "2", "4Gi", corev1.PodRunning),
+		pod("build", "worker-2", "1", "2Gi", corev1.PodSucceeded),
+		pod("db", "worker-2", "2", "4Gi", corev1.PodPending),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "john-dev", Annotations: map[string]string{RequesterAnnotation: "john"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-infra"}},
+	)
+
+	//when
+	u, err := Compute(context.Background(), cl)
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, "8", u.AllocatableCPU.String())
+	assert.Equal(t, "4", u.RequestedCPU.String())
+	assert.Equal(t, 50, u.CPUPercent())
+	assert.Equal(t, 50, u.MemoryPercent())
+	assert.Equal(t, 1, u.UserProjects)
+}
+
+func TestPercent(t *testing.T) {
+	t.Run("cluster-scale memory", func(t *testing.T) {
+		//given
+		u := Usage{
+			AllocatableMemory: resource.MustParse("200Ti"),
+			RequestedMemory:   resource.MustParse("150Ti"),
+		}
+
+		//when
+		p := u.MemoryPercent()
+
+		//then
+		assert.Equal(t, 75, p)
+	})
+
+	t.Run("fractional CPU", func(t *testing.T) {
+		//given
+		u := Usage{
+			AllocatableCPU: resource.MustParse("3"),
+			RequestedCPU:   resource.MustParse("2500m"),
+		}
+
+		//when
+		p := u.CPUPercent()
+
+		//then
+		assert.Equal(t, 83, p)
+	})
+}
+
+func TestStatus(t *testing.T) {
+	u := Usage{
+		AllocatableCPU:    resource.MustParse("10"),
+		RequestedCPU:      resource.MustParse("9"),
+		AllocatableMemory: resource.MustParse("10Gi"),
+		RequestedMemory:   resource.MustParse("5Gi"),
+		UserProjects:      100,
+	}
+
+	t.Run("thresholds crossed", func(t *testing.T) {
+		//when
+		status := Status(u, codereadyv1alpha1.CapacitySpec{MaxCPURequestedPercent: 80, MaxMemoryRequestedPercent: 80, MaxUserProjects: 100})
+
+		//then
+		assert.True(t, status.Exhausted)
+		assert.Equal(t, []string{"90% of CPU requested, maximum is 80%", "100 user projects, maximum is 100"}, status.Reasons)
+	})
+
+	t.Run("thresholds not set", func(t *testing.T) {
+		//when
+		status := Status(u, codereadyv1alpha1.CapacitySpec{})
+
+		//then
+		assert.False(t, status.Exhausted)
+		assert.Equal(t, 90, status.CPURequestedPercent)
+		assert.Equal(t, 50, status.MemoryRequestedPercent)
+	})
+}
+
+func node(name, role, cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{role: ""}},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func pod(name, node, cpu, memory string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{
+				{
+					Name: name,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpu),
+							corev1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestChanged(t *testing.T) {
+	u := Usage{
+		AllocatableCPU:    resource.MustParse("10"),
+		RequestedCPU:      resource.MustParse("9"),
+		AllocatableMemory: resource.MustParse("10Gi"),
+		RequestedMemory:   resource.MustParse("5Gi"),
+		UserProjects:      100,
+	}
+	spec := codereadyv1alpha1.CapacitySpec{MaxCPURequestedPercent: 80}
+	previous := Status(u, spec)
+	previous.LastUpdateTime = metav1.NewTime(previous.LastUpdateTime.Add(-time.Hour))
+
+	t.Run("only update time differs", func(t *testing.T) {
+		//when
+		current := Status(u, spec)
+
+		//then
+		assert.False(t, Changed(previous, current))
+	})
+
+	t.Run("quantity differs", func(t *testing.T) {
+		//given
+		changed := u
+		changed.RequestedMemory = resource.MustParse("6Gi")
+
+		//when
+		current := Status(changed, spec)
+
+		//then
+		assert.True(t, Changed(previous, current))
+	})
+
+	t.Run("same quantity in other format", func(t *testing.T) {
+		//given
+		same := u
+		same.RequestedCPU = resource.MustParse("9000m")
+
+		//when
+		current := Status(same, spec)
+
+		//then
+		assert.False(t, Changed(previous, current))
+	})
+
+	t.Run("exhausted flag differs", func(t *testing.T) {
+		//when
+		current := Status(u, codereadyv1alpha1.CapacitySpec{})
+
+		//then
+		assert.True(t, Changed(previous, current))
+	})
+
+	t.Run("no previous status", func(t *testing.T) {
+		assert.True(t, Changed(nil, previous))
+		assert.False(t, Changed(nil, nil))
+	})
+}
@@ -14,46 +14,60 @@ import (
 type Client interface {
 	client.Client
 	Secret
+	ConfigMap
 	ServiceAccount
 	ClusterRoleBinding
 	OAuthClient
 	Route
 	Infrastructure
+	Ingress
 }
 
 // Secret contains methods for manipulating Secrets
 type Secret interface {
-	GetSecret(namespace, name string) (*v1.Secret, error)
-	CreateSecret(*v1.Secret) error
+	GetSecret(ctx context.Context, namespace, name string) (*v1.Secret, error)
+	CreateSecret(ctx context.Context, s *v1.Secret) error
+}
+
+// ConfigMap contains methods for manipulating ConfigMaps
+type ConfigMap interface {
+	GetConfigMap(ctx context.Context, namespace, name string) (*v1.ConfigMap, error)
 }
 
 // ServiceAccount contains methods for manipulating ServiceAccounts.
 type ServiceAccount interface {
-	CreateServiceAccount(*v1.ServiceAccount) error
-	GetServiceAccount(namespace, name string) (*v1.ServiceAccount, error)
+	CreateServiceAccount(ctx context.Context, sa *v1.ServiceAccount) error
+	GetServiceAccount(ctx context.Context, namespace, name string) (*v1.ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, sa *v1.ServiceAccount) error
 }
 
 // ClusterRoleBinding contains methods for manipulating ClusterRoleBindings.
 type ClusterRoleBinding interface {
-	CreateClusterRoleBinding(*rbacv1.ClusterRoleBinding) error
-	GetClusterRoleBinding(name string) (*rbacv1.ClusterRoleBinding, error)
+	CreateClusterRoleBinding(ctx context.Context, crb *rbacv1.ClusterRoleBinding) error
+	GetClusterRoleBinding(ctx context.Context, name string) (*rbacv1.ClusterRoleBinding, error)
+	DeleteClusterRoleBinding(ctx context.Context, crb *rbacv1.ClusterRoleBinding) error
 }
 
 // OAuthClient contains methods for manipulating OAuthClient.
 type OAuthClient interface {
-	CreateOAuthClient(*oauthv1.OAuthClient) error
-	GetOAuthClient(name string) (*oauthv1.OAuthClient, error)
+	CreateOAuthClient(ctx context.Context, oc *oauthv1.OAuthClient) error
+	GetOAuthClient(ctx context.Context, name string) (*oauthv1.OAuthClient, error)
 }
 
 type Route interface {
-	CreateRoute(route *routev1.Route) error
-	GetRoute(namespace, name string) (*routev1.Route, error)
-	DeleteRoute(r *routev1.Route) error
+	CreateRoute(ctx context.Context, route *routev1.Route) error
+	GetRoute(ctx context.Context, namespace, name string) (*routev1.Route, error)
+	DeleteRoute(ctx context.Context, r *routev1.Route) error
 }
 
 // Infrastructure contains method for manipulating Infrastructure
 type Infrastructure interface {
-	GetInfrastructure(name string) (*configv1.Infrastructure, error)
+	GetInfrastructure(ctx context.Context, name string) (*configv1.Infrastructure, error)
+}
+
+// Ingress contains method for manipulating Ingress configuration of the cluster
+type Ingress interface {
+	GetIngress(ctx context.Context, name string) (*configv1.Ingress, error)
 }
 
 // Interface assertion.
@@ -70,85 +84,113 @@ func NewClient(k8sClient client.Client) Client {
 }
 
 // CreateClusterRoleBinding creates the ClusterRoleBinding.
-func (c *clientImpl) CreateClusterRoleBinding(crb *rbacv1.ClusterRoleBinding) error {
-	return c.Client.Create(context.Background(), crb)
+func (c *clientImpl) CreateClusterRoleBinding(ctx context.Context, crb *rbacv1.ClusterRoleBinding) error {
+	return c.Client.Create(ctx, crb)
 }
 
 // GetClusterRoleBinding returns the existing ClusteRoleBinding.
-func (c *clientImpl) GetClusterRoleBinding(name string) (*rbacv1.ClusterRoleBinding, error) {
+func (c *clientImpl) GetClusterRoleBinding(ctx context.Context, name string) (*rbacv1.ClusterRoleBinding, error) {
 	crb := &rbacv1.ClusterRoleBinding{}
 
-	if err := c.Client.Get(context.Background(), types.NamespacedName{Name: name}, crb); err != nil {
+	if err := c.Client.Get(ctx, types.NamespacedName{Name: name}, crb); err != nil {
 		return nil, err
 	}
 	return crb, nil
 }
 
+// DeleteClusterRoleBinding deletes the ClusterRoleBinding.
+func (c *clientImpl) DeleteClusterRoleBinding(ctx context.Context, crb *rbacv1.ClusterRoleBinding) error {
+	return c.Client.Delete(ctx, crb)
+}
+
 // CreateOauthClient creates the OauthClient.
-func (c *clientImpl) CreateOAuthClient(oc *oauthv1.OAuthClient) error {
-	return c.Client.Create(context.Background(), oc)
+func (c *clientImpl) CreateOAuthClient(ctx context.Context, oc *oauthv1.OAuthClient) error {
+	return c.Client.Create(ctx, oc)
 }
 
 // GetOauthClient returns the existing OAuthClient.
-func (c *clientImpl) GetOAuthClient(name string) (*oauthv1.OAuthClient, error) {
+func (c *clientImpl) GetOAuthClient(ctx context.Context, name string) (*oauthv1.OAuthClient, error) {
 	oc := &oauthv1.OAuthClient{}
-	if err := c.Client.Get(context.Background(), types.NamespacedName{Name: name}, oc); err != nil {
+	if err := c.Client.Get(ctx, types.NamespacedName{Name: name}, oc); err != nil {
 		return nil, err
 	}
 	return oc, nil
 }
 
 // CreateServiceAccount creates the serviceAccount.
-func (c *clientImpl) CreateServiceAccount(sa *v1.ServiceAccount) error {
-	return c.Client.Create(context.Background(), sa)
+func (c *clientImpl) CreateServiceAccount(ctx context.Context, sa *v1.ServiceAccount) error {
+	return c.Client.Create(ctx, sa)
 }
 
 // GetServiceAccount returns the existing serviceAccount.
-func (c *clientImpl) GetServiceAccount(namespace, name string) (*v1.ServiceAccount, error) {
+func (c *clientImpl) GetServiceAccount(ctx context.Context, namespace, name string) (*v1.ServiceAccount, error) {
 	sa := &v1.ServiceAccount{}
-	if err := c.Client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, sa); err != nil {
+	if err := c.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sa); err != nil {
 		return nil, err
 	}
 	return sa, nil
 }
 
+// DeleteServiceAccount deletes the serviceAccount.
+func (c *clientImpl) DeleteServiceAccount(ctx context.Context, sa *v1.ServiceAccount) error {
+	return c.Client.Delete(ctx, sa)
+}
+
 // CreateRoute creates the Route.
-func (c *clientImpl) CreateRoute(r *routev1.Route) error {
-	return c.Client.Create(context.Background(), r)
+func (c *clientImpl) CreateRoute(ctx context.Context, r *routev1.Route) error {
+	return c.Client.Create(ctx, r)
 }
 
 // GetRoute returns the existing Route.
-func (c *clientImpl) GetRoute(namespace, name string) (*routev1.Route, error) {
+func (c *clientImpl) GetRoute(ctx context.Context, namespace, name string) (*routev1.Route, error) {
 	r := &routev1.Route{}
-	if err := c.Client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, r); err != nil {
+	if err := c.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, r); err != nil {
 		return nil, err
 	}
 	return r, nil
 }
 
 // DeleteRoute deletes the Route.
-func (c *clientImpl) DeleteRoute(r *routev1.Route) error {
-	return c.Client.Delete(context.Background(), r)
+func (c *clientImpl) DeleteRoute(ctx context.Context, r *routev1.Route) error {
+	return c.Client.Delete(ctx, r)
 }
 
 // GetSecret returns the existing Secret.
-func (c *clientImpl) GetSecret(namespace, name string) (*v1.Secret, error) {
+func (c *clientImpl) GetSecret(ctx context.Context, namespace, name string) (*v1.Secret, error) {
 	s := &v1.Secret{}
-	if err := c.Client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, s); err != nil {
+	if err := c.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, s); err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
 // CreateSecret creates the Secret.
-func (c *clientImpl) CreateSecret(s *v1.Secret) error {
-	return c.Client.Create(context.Background(), s)
+func (c *clientImpl) CreateSecret(ctx context.Context, s *v1.Secret) error {
+	return c.Client.Create(ctx, s)
+}
+
+// GetConfigMap returns the existing ConfigMap.
+func (c *clientImpl) GetConfigMap(ctx context.Context, namespace, name string) (*v1.ConfigMap, error) {
+	cm := &v1.ConfigMap{}
+	if err := c.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cm); err != nil {
+		return nil, err
+	}
+	return cm, nil
 }
 
 // GetInfrastructure returns the existing Infrastructure.
-func (c *clientImpl) GetInfrastructure(name string) (*configv1.Infrastructure, error) {
+func (c *clientImpl) GetInfrastructure(ctx context.Context, name string) (*configv1.Infrastructure, error) {
 	r := &configv1.Infrastructure{}
-	if err := c.Client.Get(context.Background(), types.NamespacedName{Name: name}, r); err != nil {
+	if err := c.Client.Get(ctx, types.NamespacedName{Name: name}, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetIngress returns the existing Ingress configuration.
+func (c *clientImpl) GetIngress(ctx context.Context, name string) (*configv1.Ingress, error) {
+	r := &configv1.Ingress{}
+	if err := c.Client.Get(ctx, types.NamespacedName{Name: name}, r); err != nil {
 		return nil, err
 	}
 	return r, nil
@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
+	"github.com/fabric8-services/fabric8-common/goasupport"
+	"github.com/fabric8-services/fabric8-common/httpsupport"
+	"github.com/fabric8-services/toolchain-operator/pkg/redact"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+// capacityPayload updates only capacity-exhausted flag of the cluster with the given API URL
+type capacityPayload struct {
+	Data struct {
+		APIURL            string `json:"api-url"`
+		CapacityExhausted bool   `json:"capacity-exhausted"`
+	} `json:"data"`
+}
+
+// UpdateCapacityExhausted sets capacity-exhausted flag of the cluster registered in cluster management service, so that
+// new users are placed on other clusters while it's exhausted. It returns true if the flag has been changed. The
+// generated cluster client has no action for it, so PATCH /api/clusters is sent with the flag and API URL of the
+// cluster and the cluster is read back to make sure the flag is set, rather than trusting the status of the response
+func (s *clusterService) UpdateCapacityExhausted(ctx context.Context, apiURL string, exhausted bool, options ...httpsupport.HTTPClientOption) (bool, error) {
+	remoteClusterService, err := s.signedClient(ctx, options...)
+	if err != nil {
+		return false, err
+	}
+
+	registered, err := s.getCluster(ctx, remoteClusterService, apiURL)
+	if err != nil {
+		return false, err
+	}
+	if registered == nil {
+		return false, errors.Errorf("cluster %s isn't registered in cluster management service", apiURL)
+	}
+	if registered.CapacityExhausted == exhausted {
+		return false, nil
+	}
+
+	if err := s.patchCapacity(ctx, remoteClusterService, apiURL, exhausted); err != nil {
+		return false, err
+	}
+	updated, err := s.getCluster(ctx, remoteClusterService, apiURL)
+	if err != nil {
+		return false, err
+	}
+	if updated == nil || updated.CapacityExhausted != exhausted {
+		return false, errors.Errorf("capacity-exhausted flag of cluster %s hasn't been set to %t by %s %s", apiURL, exhausted, http.MethodPatch, clusterclient.CreateClustersPath())
+	}
+	log.Info("capacity-exhausted flag updated in cluster management service", "cluster", apiURL, "capacity_exhausted", exhausted)
+	return true, nil
+}
+
+func (s clusterService) patchCapacity(ctx context.Context, remoteClusterService *clusterclient.Client, apiURL string, exhausted bool) (err error) {
+	ctx, span := tracing.Start(ctx, "UpdateCapacityExhausted",
+		trace.StringAttribute("cluster.api_url", apiURL), trace.BoolAttribute("cluster.capacity_exhausted", exhausted))
+	defer tracing.End(span, &err)
+	var payload capacityPayload
+	payload.Data.APIURL = apiURL
+	payload.Data.CapacityExhausted = exhausted
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal capacity of cluster %s", apiURL)
+	}
+
+	u := strings.TrimSuffix(s.config.GetClusterServiceURL(), "/") + clusterclient.CreateClustersPath()
+	req, err := http.NewRequest(http.MethodPatch, u, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for capacity of cluster %s", apiURL)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if remoteClusterService.JWTSigner != nil {
+		if err := remoteClusterService.JWTSigner.Sign(req); err != nil {
+			return errors.Wrapf(err, "failed to sign request for capacity of cluster %s", apiURL)
+		}
+	}
+
+	res, err := remoteClusterService.Do(goasupport.ForwardContextRequestID(ctx), req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update capacity of cluster %s", apiURL)
+	}
+	defer func() {
+		if err := httpsupport.CloseResponse(res); err != nil {
+			log.Error(err, "error during closing response body when updating capacity of cluster")
+		}
+	}()
+
+	bodyString, err := httpsupport.ReadBody(res.Body)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read response while updating capacity of cluster")
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return errors.Errorf("received unexpected response code while updating capacity of cluster in cluster management service. Response status: %s. Response body: %s", res.Status, redact.Text(bodyString))
+	}
+	return nil
+}
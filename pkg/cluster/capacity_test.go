@@ -0,0 +1,168 @@
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-common/httpsupport"
+	"github.com/fabric8-services/toolchain-operator/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+const exhaustedClusterBody = `{"data":{"api-url":"https://api.dsaas-stage.openshift.com/","app-dns":"8a09.starter-us-east-2.openshiftapps.com","capacity-exhausted":true,"name":"dsaas-stage","type":"OSD"}}`
+
+func TestUpdateCapacityExhausted(t *testing.T) {
+	c := newConfig()
+	apiURL := "https://api.dsaas-stage.openshift.com/"
+
+	t.Run("unchanged", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			MatchParam("cluster-url", apiURL).
+			Reply(200).
+			BodyString(registeredClusterBody)
+
+		// when
+		changed, err := NewClusterService(c).UpdateCapacityExhausted(context.Background(), apiURL, false, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.True(t, gock.IsDone(), "pending mocks: %v", gock.Pending())
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(200).
+			BodyString(registeredClusterBody)
+		gock.New("http://cluster").
+			Patch("api/clusters").
+			MatchHeader("Authorization", "Bearer "+TOKEN).
+			JSON(map[string]interface{}{"data": map[string]interface{}{"api-url": apiURL, "capacity-exhausted": true}}).
+			Reply(204)
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(200).
+			BodyString(exhaustedClusterBody)
+
+		// when
+		changed, err := NewClusterService(c).UpdateCapacityExhausted(context.Background(), apiURL, true, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.True(t, gock.IsDone(), "pending mocks: %v", gock.Pending())
+	})
+
+	t.Run("patch ignored", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Times(2).
+			Reply(200).
+			BodyString(registeredClusterBody)
+		gock.New("http://cluster").
+			Patch("api/clusters").
+			Reply(204)
+
+		// when
+		changed, err := NewClusterService(c).UpdateCapacityExhausted(context.Background(), apiURL, true, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		assert.EqualError(t, err, "capacity-exhausted flag of cluster https://api.dsaas-stage.openshift.com/ hasn't been set to true by PATCH /api/clusters")
+		assert.False(t, changed)
+		assert.True(t, gock.IsDone(), "pending mocks: %v", gock.Pending())
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(404)
+
+		// when
+		_, err := NewClusterService(c).UpdateCapacityExhausted(context.Background(), apiURL, true, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		assert.EqualError(t, err, "cluster https://api.dsaas-stage.openshift.com/ isn't registered in cluster management service")
+	})
+}
+
+func TestUpdateCapacityExhaustedInFakeClusterService(t *testing.T) {
+	// given
+	c := newConfig()
+	auth := test.NewFakeAuthService(c.ClientID, c.ClientSecret)
+	defer auth.Close()
+	clusterService := test.NewFakeClusterService(auth)
+	defer clusterService.Close()
+	c.AuthURL = auth.URL
+	c.ClusterURL = clusterService.URL
+
+	i := dummyClusterConfigInformer{c.ClusterName}
+	clusterData, err := i.Inform(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, NewClusterService(c).CreateCluster(context.Background(), clusterData))
+
+	// when
+	changed, err := NewClusterService(c).UpdateCapacityExhausted(context.Background(), clusterData.APIURL, true)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, changed)
+	registered := clusterService.AssertRegistered(t, clusterData.APIURL)
+	assert.True(t, registered.CapacityExhausted)
+	assert.Equal(t, "mysatoken", registered.ServiceAccountToken)
+
+	t.Run("fail", func(t *testing.T) {
+		// given
+		clusterService.Fail(http.MethodPatch, "/api/clusters", http.StatusInternalServerError, 1)
+
+		// when
+		_, err := NewClusterService(c).UpdateCapacityExhausted(context.Background(), clusterData.APIURL, false)
+
+		// then
+		require.Error(t, err)
+		assert.True(t, clusterService.AssertRegistered(t, clusterData.APIURL).CapacityExhausted)
+	})
+}
+
+func TestUpdateCapacityExhaustedReusesSignedClient(t *testing.T) {
+	// given
+	c := newConfig()
+	auth := test.NewFakeAuthService(c.ClientID, c.ClientSecret)
+	defer auth.Close()
+	clusterService := test.NewFakeClusterService(auth)
+	defer clusterService.Close()
+	c.AuthURL = auth.URL
+	c.ClusterURL = clusterService.URL
+
+	i := dummyClusterConfigInformer{c.ClusterName}
+	clusterData, err := i.Inform(context.Background())
+	require.NoError(t, err)
+	service := NewClusterService(c)
+
+	// when
+	_, err = service.VerifyCluster(context.Background(), clusterData)
+	require.NoError(t, err)
+	changed, err := service.UpdateCapacityExhausted(context.Background(), clusterData.APIURL, true)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 1, auth.Issued())
+	assert.True(t, clusterService.AssertRegistered(t, clusterData.APIURL).CapacityExhausted)
+}
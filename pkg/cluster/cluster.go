@@ -2,41 +2,81 @@ package cluster
 
 import (
 	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	authclient "github.com/fabric8-services/fabric8-auth-client/auth"
 	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
-	"github.com/fabric8-services/fabric8-common/auth"
 	"github.com/fabric8-services/fabric8-common/goasupport"
 	"github.com/fabric8-services/fabric8-common/httpsupport"
+	"github.com/fabric8-services/toolchain-operator/pkg/redact"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
 	goaclient "github.com/goadesign/goa/client"
 	"github.com/pkg/errors"
-	"net/http"
-	"net/url"
+	"go.opencensus.io/trace"
 )
 
 type Config interface {
+	GetOwner() string
 	GetClusterServiceURL() string
 	GetAuthServiceURL() string
 	GetClientID() string
 	GetClientSecret() string
 	GetClusterName() string
+	GetHTTPConnectTimeout() time.Duration
+	GetHTTPResponseTimeout() time.Duration
+	GetCABundle() []byte
+	GetClientCertificate() []byte
+	GetClientKey() []byte
+	GetHTTPProxy() string
+	GetHTTPSProxy() string
+	GetNoProxy() string
 }
 
 type clusterService struct {
 	config Config
+	// signed is the cluster service client signed with the token of the service account. It's created by the first
+	// call and reused by the following ones, so that a single token is obtained from auth service per clusterService.
+	// The service is meant to be used by a single reconcile, it isn't safe for concurrent use
+	signed *clusterclient.Client
 }
 
 func NewClusterService(config Config) *clusterService {
-	return &clusterService{config}
+	return &clusterService{config: config}
 }
 
-// CreateCluster adds cluster configuration in cluster service
-func (s clusterService) CreateCluster(ctx context.Context, data *clusterclient.CreateClusterData, options ...httpsupport.HTTPClientOption) error {
+// signedClient returns the cluster service client signed with the token of the service account, the given options
+// apply only to the first call which creates it
+func (s *clusterService) signedClient(ctx context.Context, options ...httpsupport.HTTPClientOption) (*clusterclient.Client, error) {
+	if s.signed != nil {
+		return s.signed, nil
+	}
 	signer := newJWTSASigner(ctx, s.config, options...)
 	remoteClusterService, err := signer.createSignedClient()
 	if err != nil {
-		return errors.Wrapf(err, "failed to create JWT signer for cluster service")
+		return nil, errors.Wrapf(err, "failed to create JWT signer for cluster service")
+	}
+	s.signed = remoteClusterService
+	return remoteClusterService, nil
+}
+
+// CreateCluster adds cluster configuration in cluster service
+func (s *clusterService) CreateCluster(ctx context.Context, data *clusterclient.CreateClusterData, options ...httpsupport.HTTPClientOption) error {
+	remoteClusterService, err := s.signedClient(ctx, options...)
+	if err != nil {
+		return err
 	}
 
+	return s.createCluster(ctx, remoteClusterService, data)
+}
+
+func (s clusterService) createCluster(ctx context.Context, remoteClusterService *clusterclient.Client, data *clusterclient.CreateClusterData) (err error) {
 	clusterURL := s.config.GetClusterServiceURL()
+	ctx, span := tracing.Start(ctx, "CreateClusters",
+		trace.StringAttribute("cluster.name", data.Name), trace.StringAttribute("cluster.api_url", data.APIURL))
+	defer tracing.End(span, &err)
 	clusterData := &clusterclient.CreateClustersPayload{Data: data}
 
 	res, err := remoteClusterService.CreateClusters(goasupport.ForwardContextRequestID(ctx), clusterclient.CreateClustersPath(), clusterData)
@@ -54,14 +94,98 @@ func (s clusterService) CreateCluster(ctx context.Context, data *clusterclient.C
 		return errors.Wrapf(err, "unable to read response while saving cluster configuration")
 	}
 	if res.StatusCode != http.StatusCreated {
+		// cluster service may echo the configuration back, including token of the service account
+		bodyString = redact.Text(bodyString)
 		err := errors.Errorf("received unexpected response code while adding cluster configuration in cluster management service. Response status: %s. Response body: %s", res.Status, bodyString)
 		log.Error(err, "failed to add cluster configuration in cluster management service",
-			"cluster_url", clusterURL, "response_status", res.Status, "response_body", bodyString)
+			"cluster_url", clusterURL, "response_status", res.Status, "response_body", bodyString, "cluster", redact.NewClusterData(data))
 		return err
 	}
 	return nil
 }
 
+// DeleteCluster removes the cluster with the given API URL from cluster service, so that no user is placed on it
+// anymore. Cluster which isn't registered is considered deleted. The generated cluster client has no action for it, so
+// DELETE /api/clusters?cluster-url=<api url> is sent and the cluster is read back to make sure it's gone, since cluster
+// service which doesn't serve the route responds with 404 too
+func (s *clusterService) DeleteCluster(ctx context.Context, apiURL string, options ...httpsupport.HTTPClientOption) (err error) {
+	ctx, span := tracing.Start(ctx, "DeleteCluster", trace.StringAttribute("cluster.api_url", apiURL))
+	defer tracing.End(span, &err)
+	remoteClusterService, err := s.signedClient(ctx, options...)
+	if err != nil {
+		return err
+	}
+
+	u := strings.TrimSuffix(s.config.GetClusterServiceURL(), "/") + clusterclient.CreateClustersPath() + "?cluster-url=" + url.QueryEscape(apiURL)
+	req, err := http.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for deleting cluster %s", apiURL)
+	}
+	if remoteClusterService.JWTSigner != nil {
+		if err := remoteClusterService.JWTSigner.Sign(req); err != nil {
+			return errors.Wrapf(err, "failed to sign request for deleting cluster %s", apiURL)
+		}
+	}
+
+	res, err := remoteClusterService.Do(goasupport.ForwardContextRequestID(ctx), req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete cluster %s", apiURL)
+	}
+	defer func() {
+		if err := httpsupport.CloseResponse(res); err != nil {
+			log.Error(err, "error during closing response body when deleting cluster")
+		}
+	}()
+
+	bodyString, err := httpsupport.ReadBody(res.Body)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read response while deleting cluster")
+	}
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+	default:
+		return errors.Errorf("received unexpected response code while deleting cluster in cluster management service. Response status: %s. Response body: %s", res.Status, redact.Text(bodyString))
+	}
+
+	registered, err := s.getCluster(ctx, remoteClusterService, apiURL)
+	if err != nil {
+		return err
+	}
+	if registered != nil {
+		return errors.Errorf("cluster %s is still registered in cluster management service after it's been deleted, %s %s responded with %s", apiURL, http.MethodDelete, clusterclient.CreateClustersPath(), res.Status)
+	}
+	return nil
+}
+
+// Status checks that cluster service is reachable and reports itself as up and running
+func (s clusterService) Status(ctx context.Context, options ...httpsupport.HTTPClientOption) error {
+	httpClient, err := newHTTPClient(s.config, options...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create http client for cluster service")
+	}
+
+	statusURL := strings.TrimSuffix(s.config.GetClusterServiceURL(), "/") + "/api/status"
+	req, err := http.NewRequest(http.MethodGet, statusURL, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for cluster service status")
+	}
+
+	res, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get status of cluster service %s", s.config.GetClusterServiceURL())
+	}
+	defer func() {
+		if err := httpsupport.CloseResponse(res); err != nil {
+			log.Error(err, "error during closing response body when getting cluster service status")
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("received unexpected response code while getting status of cluster service %s. Response status: %s", s.config.GetClusterServiceURL(), res.Status)
+	}
+	return nil
+}
+
 type saSigner interface {
 	createSignedClient() (*clusterclient.Client, error)
 }
@@ -76,16 +200,21 @@ func newJWTSASigner(ctx context.Context, config Config, options ...httpsupport.H
 	return &jwtSASigner{ctx, config, options}
 }
 
-// createSignedClient creates a client with a JWT signer which uses the Auth Service Account token
+// createSignedClient creates a client with a JWT signer which uses the Auth Service Account token. Auth and cluster
+// service are called with the same dedicated HTTP client, http.DefaultClient is never used
 func (c jwtSASigner) createSignedClient() (*clusterclient.Client, error) {
-	cln, err := c.createClient(c.ctx)
+	httpClient, err := newHTTPClient(c.config, c.options...)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "failed to create http client for auth and cluster service")
 	}
-	token, err := auth.ServiceAccountToken(c.ctx, c.config, c.config.GetClientID(), c.config.GetClientSecret(), c.options...)
+	cln, err := c.createClient(httpClient)
 	if err != nil {
 		return nil, err
 	}
+	token, err := serviceAccountToken(c.ctx, c.config, httpClient)
+	if err != nil {
+		return nil, redact.Error(err)
+	}
 
 	cln.SetJWTSigner(
 		&goaclient.JWTSigner{
@@ -97,22 +226,57 @@ func (c jwtSASigner) createSignedClient() (*clusterclient.Client, error) {
 	return cln, nil
 }
 
-func (c jwtSASigner) createClient(ctx context.Context) (*clusterclient.Client, error) {
+func (c jwtSASigner) createClient(httpClient *http.Client) (*clusterclient.Client, error) {
 	u, err := url.Parse(c.config.GetClusterServiceURL())
 	if err != nil {
 		return nil, err
 	}
-
-	httpClient := http.DefaultClient
-
-	if c.options != nil {
-		for _, opt := range c.options {
-			opt(httpClient)
-		}
-	}
 	cln := clusterclient.New(goaclient.HTTPClientDoer(httpClient))
 
 	cln.Host = u.Host
 	cln.Scheme = u.Scheme
 	return cln, nil
 }
+
+// serviceAccountToken exchanges client credentials of toolchain for a token of its service account in auth service
+func serviceAccountToken(ctx context.Context, config Config, httpClient *http.Client) (_ string, err error) {
+	authURL := config.GetAuthServiceURL()
+	ctx, span := tracing.Start(ctx, "ServiceAccountToken", trace.StringAttribute("auth.url", authURL))
+	defer tracing.End(span, &err)
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	cln := authclient.New(goaclient.HTTPClientDoer(httpClient))
+	cln.Host = u.Host
+	cln.Scheme = u.Scheme
+
+	secret := config.GetClientSecret()
+	payload := &authclient.TokenExchange{
+		GrantType:    "client_credentials",
+		ClientID:     config.GetClientID(),
+		ClientSecret: &secret,
+	}
+	res, err := cln.ExchangeToken(goasupport.ForwardContextRequestID(ctx), authclient.ExchangeTokenPath(), payload, "application/x-www-form-urlencoded")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to obtain token of service account from auth service %s", authURL)
+	}
+	defer func() {
+		if err := httpsupport.CloseResponse(res); err != nil {
+			log.Error(err, "error during closing response body when obtaining token of service account")
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		bodyString, _ := httpsupport.ReadBody(res.Body)
+		return "", errors.Errorf("received unexpected response code while obtaining token of service account from auth service %s. Response status: %s. Response body: %s", authURL, res.Status, redact.Text(bodyString))
+	}
+	token, err := cln.DecodeOauthToken(res)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decode token of service account from auth service %s", authURL)
+	}
+	if token.AccessToken == nil || *token.AccessToken == "" {
+		return "", errors.Errorf("received empty token of service account from auth service %s", authURL)
+	}
+	return *token.AccessToken, nil
+}
@@ -29,7 +29,7 @@ func TestSaveClusterOK(t *testing.T) {
 	c := newConfig()
 
 	i := dummyClusterConfigInformer{c.ClusterName}
-	clusterData, err := i.Inform()
+	clusterData, err := i.Inform(context.Background())
 	require.NoError(t, err)
 
 	clusterService := NewClusterService(c)
@@ -41,7 +41,74 @@ func TestSaveClusterOK(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSaveClusterLeavesDefaultClientAlone(t *testing.T) {
+	// given
+	defer gock.OffAll()
+	setupGockForAuth()
+	gock.New("http://cluster").
+		Post("api/clusters").
+		MatchHeader("Authorization", "Bearer "+TOKEN).
+		Reply(201)
+	c := newConfig()
+	c.HTTPSProxy = "http://secure-proxy:3128"
+	clusterData, err := dummyClusterConfigInformer{c.ClusterName}.Inform(context.Background())
+	require.NoError(t, err)
+	defaultTransport := http.DefaultClient.Transport
+
+	// when
+	err = NewClusterService(c).CreateCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, defaultTransport, http.DefaultClient.Transport)
+}
+
+func TestSaveClusterPropagatesTraceContext(t *testing.T) {
+	// given
+	defer gock.OffAll()
+	gock.New("http://auth").
+		Post("api/token").
+		MatchHeader("traceparent", "^00-[0-9a-f]{32}-[0-9a-f]{16}-0[01]$").
+		Reply(200).
+		BodyString(`{"access_token":"` + TOKEN + `","token_type":"Bearer"}`)
+	gock.New("http://cluster").
+		Post("api/clusters").
+		MatchHeader("Authorization", "Bearer "+TOKEN).
+		MatchHeader("traceparent", "^00-[0-9a-f]{32}-[0-9a-f]{16}-0[01]$").
+		Reply(201)
+	c := newConfig()
+	clusterData, err := dummyClusterConfigInformer{c.ClusterName}.Inform(context.Background())
+	require.NoError(t, err)
+
+	// when
+	err = NewClusterService(c).CreateCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone())
+}
+
 func TestSaveClusterFail(t *testing.T) {
+	t.Run("token not issued", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		gock.New("http://auth").
+			Post("api/token").
+			Reply(401).
+			BodyString(`{"errors":[{"code":"unauthorized_error","detail":"invalid service account credentials","status":"401"}]}`)
+		c := newConfig()
+		clusterData, err := dummyClusterConfigInformer{c.ClusterName}.Inform(context.Background())
+		require.NoError(t, err)
+
+		// when
+		err = NewClusterService(c).CreateCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "received unexpected response code while obtaining token of service account from auth service http://auth. Response status: 401 Unauthorized")
+		assert.False(t, gock.HasUnmatchedRequest())
+	})
+
 	t.Run("unauthorized", func(t *testing.T) {
 		// given
 		defer gock.OffAll()
@@ -62,7 +129,7 @@ func TestSaveClusterFail(t *testing.T) {
 		c := newConfig()
 
 		i := dummyClusterConfigInformer{clusterName: c.ClusterName}
-		clusterData, err := i.Inform()
+		clusterData, err := i.Inform(context.Background())
 		require.NoError(t, err)
 
 		clusterService := NewClusterService(c)
@@ -88,7 +155,7 @@ func TestSaveClusterFail(t *testing.T) {
 		c := newConfig()
 
 		i := dummyClusterConfigInformer{clusterName: c.ClusterName}
-		clusterData, err := i.Inform()
+		clusterData, err := i.Inform(context.Background())
 		require.NoError(t, err)
 
 		clusterService := NewClusterService(c)
@@ -100,6 +167,34 @@ func TestSaveClusterFail(t *testing.T) {
 		assert.EqualError(t, err, "received unexpected response code while adding cluster configuration in cluster management service. Response status: 400 Bad Request. Response body: something bad happened")
 	})
 
+	t.Run("bad request echoing cluster configuration", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Post("api/clusters").
+			MatchHeader("Authorization", "Bearer "+TOKEN).
+			Reply(400).
+			BodyString(`{"errors":[{"detail":"invalid"}],"data":{"auth-client-secret":"oauthsecret","service-account-token":"mysatoken"}}`)
+
+		c := newConfig()
+
+		i := dummyClusterConfigInformer{clusterName: c.ClusterName}
+		clusterData, err := i.Inform(context.Background())
+		require.NoError(t, err)
+
+		clusterService := NewClusterService(c)
+
+		// when
+		err = clusterService.CreateCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "oauthsecret")
+		assert.NotContains(t, err.Error(), "mysatoken")
+		assert.Contains(t, err.Error(), `"service-account-token":"<redacted>"`)
+	})
+
 	t.Run("internal server error", func(t *testing.T) {
 		// given
 		defer gock.OffAll()
@@ -114,7 +209,7 @@ func TestSaveClusterFail(t *testing.T) {
 		c := newConfig()
 
 		i := dummyClusterConfigInformer{clusterName: c.ClusterName}
-		clusterData, err := i.Inform()
+		clusterData, err := i.Inform(context.Background())
 		require.NoError(t, err)
 
 		clusterService := NewClusterService(c)
@@ -131,7 +226,7 @@ type dummyClusterConfigInformer struct {
 	clusterName string
 }
 
-func (d dummyClusterConfigInformer) Inform(options ...SASecretOption) (*clusterclient.CreateClusterData, error) {
+func (d dummyClusterConfigInformer) Inform(ctx context.Context, options ...SASecretOption) (*clusterclient.CreateClusterData, error) {
 	tokenID := "3d7b75e3-7053-4846-9b64-26cf42717692"
 	return &clusterclient.CreateClusterData{
 		Name:                   d.clusterName,
@@ -147,6 +242,116 @@ func (d dummyClusterConfigInformer) Inform(options ...SASecretOption) (*clusterc
 	}, nil
 }
 
+func TestDeleteCluster(t *testing.T) {
+	apiURL := "https://api.dsaas-stage.openshift.com/"
+
+	t.Run("deleted", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Delete("api/clusters").
+			MatchParam("cluster-url", apiURL).
+			MatchHeader("Authorization", "Bearer "+TOKEN).
+			Reply(204)
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			MatchParam("cluster-url", apiURL).
+			Reply(404)
+
+		// when
+		err := NewClusterService(newConfig()).DeleteCluster(context.Background(), apiURL, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, gock.IsDone(), "pending mocks: %v", gock.Pending())
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Delete("api/clusters").
+			Reply(404)
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(404)
+
+		// when
+		err := NewClusterService(newConfig()).DeleteCluster(context.Background(), apiURL, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("route not served", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Delete("api/clusters").
+			Reply(404)
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(200).
+			BodyString(registeredClusterBody)
+
+		// when
+		err := NewClusterService(newConfig()).DeleteCluster(context.Background(), apiURL, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		assert.EqualError(t, err, "cluster https://api.dsaas-stage.openshift.com/ is still registered in cluster management service after it's been deleted, DELETE /api/clusters responded with 404 Not Found")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Delete("api/clusters").
+			Reply(500).
+			BodyString(`{"errors":[{"detail":"internal error"}]}`)
+
+		// when
+		err := NewClusterService(newConfig()).DeleteCluster(context.Background(), apiURL, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		assert.EqualError(t, err, `received unexpected response code while deleting cluster in cluster management service. Response status: 500 Internal Server Error. Response body: {"errors":[{"detail":"internal error"}]}`)
+	})
+}
+
+func TestClusterServiceStatus(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		gock.New("http://cluster").
+			Get("api/status").
+			Reply(200).
+			BodyString(`{"commit":"abc"}`)
+
+		// when
+		err := NewClusterService(newConfig()).Status(context.Background(), httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		gock.New("http://cluster").
+			Get("api/status").
+			Reply(503)
+
+		// when
+		err := NewClusterService(newConfig()).Status(context.Background(), httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		assert.EqualError(t, err, "received unexpected response code while getting status of cluster service http://cluster. Response status: 503 Service Unavailable")
+	})
+}
+
 func setupGockForAuth() {
 	// to retrieve sa token
 	gock.New("http://auth").
@@ -1,31 +1,49 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
-	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/satoken"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
 	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
 )
 
-type configOption func(data *clusterclient.CreateClusterData) error
+// ConfigName is the name of configuration objects of the cluster, e.g. Infrastructure and Ingress
+const ConfigName = "cluster"
+
+type configOption func(ctx context.Context, data *clusterclient.CreateClusterData) error
+
+// traced runs the given option in a span of the given name
+func traced(name string, opt configOption) configOption {
+	return func(ctx context.Context, data *clusterclient.CreateClusterData) (err error) {
+		ctx, span := tracing.Start(ctx, name)
+		defer tracing.End(span, &err)
+		return opt(ctx, data)
+	}
+}
 
 func clusterNameAndAPIURL(i configInformer) configOption {
-	return func(c *clusterclient.CreateClusterData) error {
-		infrastructure, err := i.oc.GetInfrastructure("cluster")
-		if err != nil {
-			// Openshift 3 doesn't have infrastucture resource named cluster. This is workaround for our tests to run on minishift
-			if infrastructure == nil && (errors.IsNotFound(err) || meta.IsNoMatchError(err)) {
-				apiURL := fmt.Sprintf("https://api.%s.openshift.com/", i.clusterName)
-				// To Do change to warning when we moved to logrus implementation
-				log.Info("forming cluster url using given cluster name for openshift 3 clusters", "cluster_name", i.clusterName, "cluster_url", apiURL)
+	return func(ctx context.Context, c *clusterclient.CreateClusterData) error {
+		// Openshift 3 doesn't have infrastucture resource named cluster. This is workaround for our tests to run on minishift
+		if !i.caps.Config {
+			if i.platform.APIURL != "" {
 				c.Name = i.clusterName
-				c.APIURL = apiURL
+				c.APIURL = i.platform.APIURL
+				return nil
+			}
+			fromClusterName(i, c)
+			return nil
+		}
+		infrastructure, err := i.oc.GetInfrastructure(ctx, ConfigName)
+		if err != nil {
+			if infrastructure == nil && errors.IsNotFound(err) {
+				fromClusterName(i, c)
 				return nil
 			}
 			return errs.Wrapf(err, "failed to get infrastructure resource named cluster ")
@@ -39,9 +57,54 @@ func clusterNameAndAPIURL(i configInformer) configOption {
 	}
 }
 
+// fromClusterName forms cluster url using the cluster name given in config
+func fromClusterName(i configInformer, c *clusterclient.CreateClusterData) {
+	apiURL := fmt.Sprintf("https://api.%s.openshift.com/", i.clusterName)
+	// To Do change to warning when we moved to logrus implementation
+	log.Info("forming cluster url using given cluster name for openshift 3 clusters", "cluster_name", i.clusterName, "cluster_url", apiURL)
+	c.Name = i.clusterName
+	c.APIURL = apiURL
+}
+
+// UnresolvedAppDNS describes app DNS which read-only ConfigInformer leaves empty, as it's detected only by probing with a
+// route on OpenShift 3
+const UnresolvedAppDNS = "unresolvable without probe"
+
 func appDNS(i configInformer, options ...RouteOption) configOption {
-	return func(c *clusterclient.CreateClusterData) error {
-		subDomain, err := routingSubDomain(i, options...)
+	return resolveAppDNS(i, true, options...)
+}
+
+// readOnlyAppDNS resolves app DNS only from configuration, it's left empty if the cluster has to be probed for it
+func readOnlyAppDNS(i configInformer) configOption {
+	return resolveAppDNS(i, false)
+}
+
+func resolveAppDNS(i configInformer, probe bool, options ...RouteOption) configOption {
+	return func(ctx context.Context, c *clusterclient.CreateClusterData) error {
+		// Openshift 4 configures the domain of routes in ingress configuration of the cluster
+		if i.caps.Config {
+			domain, err := ingressDomain(ctx, i)
+			if err != nil {
+				return err
+			}
+			if domain != "" {
+				c.AppDNS = domain
+				return nil
+			}
+		}
+		// routing sub-domain is detected from host of a route, clusters without routes expose applications by Ingresses
+		// on a domain which no Kubernetes API tells, so it's configured
+		if !i.caps.Route {
+			if i.platform.IngressDomain == "" {
+				return errs.New("domain of applications isn't known on cluster without routes, set 'ingressDomain'")
+			}
+			c.AppDNS = i.platform.IngressDomain
+			return nil
+		}
+		if !probe {
+			return nil
+		}
+		subDomain, err := routingSubDomain(ctx, i, options...)
 		if err != nil {
 			return err
 		}
@@ -50,29 +113,55 @@ func appDNS(i configInformer, options ...RouteOption) configOption {
 	}
 }
 
-func oauthClient(i configInformer) configOption {
-	return func(c *clusterclient.CreateClusterData) error {
-		c.AuthClientID = config.OAuthClientName
-		c.AuthClientDefaultScope = "user:full"
+// ingressDomain returns the domain of routes set in ingress configuration of the cluster, empty if it isn't set
+func ingressDomain(ctx context.Context, i configInformer) (string, error) {
+	ingress, err := i.oc.GetIngress(ctx, ConfigName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errs.Wrapf(err, "failed to get ingress configuration named %s", ConfigName)
+	}
+	return ingress.Spec.Domain, nil
+}
 
-		oauthClient, err := i.oc.GetOAuthClient(config.OAuthClientName)
+func oauthClient(i configInformer) configOption {
+	return func(ctx context.Context, c *clusterclient.CreateClusterData) error {
+		if i.platform.OIDC == nil {
+			return errs.New("no provisioner of OIDC client is set")
+		}
+		client, err := i.platform.OIDC.Client(ctx)
 		if err != nil {
 			return err
 		}
 
-		c.AuthClientSecret = oauthClient.Secret
+		c.AuthClientID = client.ID
+		c.AuthClientSecret = client.Secret
+		c.AuthClientDefaultScope = client.DefaultScope
 		return nil
 	}
 }
 
 func serviceAccount(i configInformer, options ...SASecretOption) configOption {
-	return func(c *clusterclient.CreateClusterData) error {
-		c.ServiceAccountUsername = fmt.Sprintf("system:serviceaccount:%s:%s", i.ns, config.SAName)
-		sa, err := i.oc.GetServiceAccount(i.ns, config.SAName)
+	return func(ctx context.Context, c *clusterclient.CreateClusterData) error {
+		c.ServiceAccountUsername = fmt.Sprintf("system:serviceaccount:%s:%s", i.ns, i.saName)
+		sa, err := i.oc.GetServiceAccount(ctx, i.ns, i.saName)
 		if err != nil {
 			return err
 		}
 
+		// token Secret created by the operator, API servers don't populate token secrets of Service Accounts anymore
+		tokenSecret, err := i.oc.GetSecret(ctx, i.ns, satoken.SecretName(i.saName))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			if token := satoken.Token(tokenSecret); token != "" {
+				c.ServiceAccountToken = token
+				return nil
+			}
+		}
+
 		//used for testing
 		for _, opt := range options {
 			opt(sa)
@@ -84,7 +173,7 @@ func serviceAccount(i configInformer, options ...SASecretOption) configOption {
 
 		var saSecret *v1.Secret
 		for _, s := range sa.Secrets {
-			sec, err := i.oc.GetSecret(i.ns, s.Name)
+			sec, err := i.oc.GetSecret(ctx, i.ns, s.Name)
 			if err != nil {
 				return err
 			}
@@ -103,7 +192,7 @@ func serviceAccount(i configInformer, options ...SASecretOption) configOption {
 }
 
 func typeOSD() configOption {
-	return func(c *clusterclient.CreateClusterData) error {
+	return func(ctx context.Context, c *clusterclient.CreateClusterData) error {
 		c.Type = "OSD"
 
 		return nil
@@ -111,7 +200,7 @@ func typeOSD() configOption {
 }
 
 func tokenProvider() configOption {
-	return func(c *clusterclient.CreateClusterData) error {
+	return func(ctx context.Context, c *clusterclient.CreateClusterData) error {
 		tokenProviderID := uuid.NewV4().String()
 		c.TokenProviderID = &tokenProviderID
 
@@ -1,14 +1,21 @@
 package cluster
 
 import (
+	"context"
 	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
 	"github.com/fabric8-services/toolchain-operator/pkg/apis"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
 	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/oidc"
+	configv1 "github.com/openshift/api/config/v1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 
@@ -27,7 +34,7 @@ func TestConfigOption(t *testing.T) {
 		var ageSeconds int32
 		oc := &oauthv1.OAuthClient{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: config.OAuthClientName,
+				Name: config.DefaultOAuthClientName,
 			},
 			Secret:                   "oauthsecret",
 			GrantMethod:              oauthv1.GrantHandlerAuto,
@@ -35,16 +42,16 @@ func TestConfigOption(t *testing.T) {
 			AccessTokenMaxAgeSeconds: &ageSeconds,
 		}
 
-		err = cl.CreateOAuthClient(oc)
+		err = cl.CreateOAuthClient(context.Background(), oc)
 		require.NoError(t, err)
 
-		informer := configInformer{cl, "test-configInformer", "test-cluster"}
+		informer := configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{OIDC: oidc.NewOAuthClientProvisioner(cl, config.DefaultOAuthClientName, nil, component.Ignore)}}
 
 		clusterData := &clusterclient.CreateClusterData{}
 		OauthClientOption := oauthClient(informer)
 
 		// when
-		err = OauthClientOption(clusterData)
+		err = OauthClientOption(context.Background(), clusterData)
 		require.NoError(t, err)
 
 		// then
@@ -60,22 +67,22 @@ func TestConfigOption(t *testing.T) {
 			ns := "config-test"
 			sa := &corev1.ServiceAccount{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      config.SAName,
+					Name:      config.DefaultSAName,
 					Namespace: ns,
 				},
 			}
-			err := cl.CreateServiceAccount(sa)
+			err := cl.CreateServiceAccount(context.Background(), sa)
 			require.NoError(t, err)
 
 			// create secrets for sa as we are using fake client
 			saSecretOptions := test.SASecretOption(t, cl, ns)
-			informer := configInformer{cl, ns, "test-cluster"}
+			informer := configInformer{cl, ns, config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}}
 
 			clusterData := &clusterclient.CreateClusterData{}
 			SAOption := serviceAccount(informer, saSecretOptions)
 
 			// when
-			err = SAOption(clusterData)
+			err = SAOption(context.Background(), clusterData)
 			require.NoError(t, err)
 
 			// then
@@ -83,6 +90,37 @@ func TestConfigOption(t *testing.T) {
 			assert.Equal(t, clusterData.ServiceAccountToken, "mysatoken")
 		})
 
+		t.Run("token secret created by operator", func(t *testing.T) {
+			// given
+			ns := "config-test"
+			cl := client.NewClient(fake.NewFakeClient())
+
+			sa := &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      config.DefaultSAName,
+					Namespace: ns,
+				},
+			}
+			err := cl.CreateServiceAccount(context.Background(), sa)
+			require.NoError(t, err)
+
+			// no secret referred by sa, as newer API servers don't populate them
+			err = cl.CreateSecret(context.Background(), test.Secret("toolchain-sre-token", ns, "myownedtoken", corev1.SecretTypeServiceAccountToken))
+			require.NoError(t, err)
+
+			informer := configInformer{cl, ns, config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}}
+
+			clusterData := &clusterclient.CreateClusterData{}
+			SAOption := serviceAccount(informer)
+
+			// when
+			err = SAOption(context.Background(), clusterData)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, "myownedtoken", clusterData.ServiceAccountToken)
+		})
+
 		t.Run("no secret ref", func(t *testing.T) {
 			// given
 			ns := "config-test"
@@ -90,20 +128,20 @@ func TestConfigOption(t *testing.T) {
 
 			sa := &corev1.ServiceAccount{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      config.SAName,
+					Name:      config.DefaultSAName,
 					Namespace: ns,
 				},
 			}
-			err := cl.CreateServiceAccount(sa)
+			err := cl.CreateServiceAccount(context.Background(), sa)
 			require.NoError(t, err)
 
-			informer := configInformer{cl, ns, "test-cluster"}
+			informer := configInformer{cl, ns, config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}}
 
 			clusterData := &clusterclient.CreateClusterData{}
 			SAOption := serviceAccount(informer)
 
 			// when
-			err = SAOption(clusterData)
+			err = SAOption(context.Background(), clusterData)
 
 			// then
 			assert.EqualError(t, err, "couldn't find any secret reference for sa toolchain-sre")
@@ -115,18 +153,18 @@ func TestConfigOption(t *testing.T) {
 
 			sa := &corev1.ServiceAccount{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      config.SAName,
+					Name:      config.DefaultSAName,
 					Namespace: "config-test",
 				},
 			}
-			err := cl.CreateServiceAccount(sa)
+			err := cl.CreateServiceAccount(context.Background(), sa)
 			require.NoError(t, err)
 
 			// create secrets for sa as we are using fake client
-			err = cl.CreateSecret(test.Secret("toolchain-sre-6756s", "config-test", "mydockertoken", corev1.SecretTypeDockercfg))
+			err = cl.CreateSecret(context.Background(), test.Secret("toolchain-sre-6756s", "config-test", "mydockertoken", corev1.SecretTypeDockercfg))
 			require.NoError(t, err)
 
-			informer := configInformer{cl, "config-test", "test-cluster"}
+			informer := configInformer{cl, "config-test", config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}}
 
 			clusterData := &clusterclient.CreateClusterData{}
 			SAOption := serviceAccount(informer, func(sa *corev1.ServiceAccount) {
@@ -136,7 +174,7 @@ func TestConfigOption(t *testing.T) {
 			})
 
 			// when
-			err = SAOption(clusterData)
+			err = SAOption(context.Background(), clusterData)
 
 			// then
 			assert.EqualError(t, err, "couldn't find any secret reference for sa toolchain-sre of type kubernetes.io/service-account-token")
@@ -150,42 +188,157 @@ func TestConfigOption(t *testing.T) {
 		require.NoError(t, err)
 
 		cl := client.NewClient(fake.NewFakeClient())
-		informer := configInformer{cl, "test-configInformer", "test-cluster"}
+		informer := configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}}
 		clusterData := &clusterclient.CreateClusterData{}
 		urlOption := clusterNameAndAPIURL(informer)
 
 		// when
-		err = urlOption(clusterData)
+		err = urlOption(context.Background(), clusterData)
 		require.NoError(t, err)
 
 		// then
 		assert.Equal(t, clusterData.APIURL, "https://api.test-cluster.openshift.com/")
 	})
 
+	t.Run("cluster url from infrastructure", func(t *testing.T) {
+		// given
+		err := apis.AddToScheme(scheme.Scheme)
+		require.NoError(t, err)
+
+		cl := client.NewClient(fake.NewFakeClient(&configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     configv1.InfrastructureStatus{APIServerURL: "https://api.dev11.devcluster.openshift.com:6443"},
+		}))
+		clusterData := &clusterclient.CreateClusterData{}
+
+		// when
+		err = clusterNameAndAPIURL(configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}})(context.Background(), clusterData)
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, "https://api.dev11.devcluster.openshift.com:6443", clusterData.APIURL)
+		assert.Equal(t, "dev11", clusterData.Name)
+
+		t.Run("not read without config api", func(t *testing.T) {
+			// given
+			clusterData := &clusterclient.CreateClusterData{}
+
+			// when
+			err = clusterNameAndAPIURL(configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.Capabilities{Route: true}, Platform{}})(context.Background(), clusterData)
+			require.NoError(t, err)
+
+			// then
+			assert.Equal(t, "https://api.test-cluster.openshift.com/", clusterData.APIURL)
+			assert.Equal(t, "test-cluster", clusterData.Name)
+		})
+
+		t.Run("configured on kubernetes", func(t *testing.T) {
+			// given
+			clusterData := &clusterclient.CreateClusterData{}
+
+			// when
+			err = clusterNameAndAPIURL(configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.Capabilities{}, Platform{APIURL: "https://api.k8s.example.com:6443"}})(context.Background(), clusterData)
+			require.NoError(t, err)
+
+			// then
+			assert.Equal(t, "https://api.k8s.example.com:6443", clusterData.APIURL)
+			assert.Equal(t, "test-cluster", clusterData.Name)
+		})
+	})
+
 	t.Run("app dns", func(t *testing.T) {
 		// given
 		err := apis.AddToScheme(scheme.Scheme)
 		require.NoError(t, err)
 		cl := client.NewClient(fake.NewFakeClient())
-		informer := configInformer{cl, "test-configInformer", "test-cluster"}
+		informer := configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}}
 		clusterData := &clusterclient.CreateClusterData{}
 		appDNSOption := appDNS(informer, withRouteHost("foo-dipakpawar231.8a09.starter-us-east-2.openshiftapps.com"))
 
 		// when
-		err = appDNSOption(clusterData)
+		err = appDNSOption(context.Background(), clusterData)
 		require.NoError(t, err)
 
 		// then
 		assert.Equal(t, clusterData.AppDNS, "8a09.starter-us-east-2.openshiftapps.com")
 	})
 
+	t.Run("app dns read-only", func(t *testing.T) {
+		// given
+		err := apis.AddToScheme(scheme.Scheme)
+		require.NoError(t, err)
+		cl := client.NewClient(fake.NewFakeClient())
+		openShift3 := capabilities.Capabilities{Route: true, OAuth: true, OpenShiftVersion: "3"}
+		informer := configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", openShift3, Platform{}}
+		clusterData := &clusterclient.CreateClusterData{}
+
+		// when
+		err = readOnlyAppDNS(informer)(context.Background(), clusterData)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, clusterData.AppDNS)
+		routes := &routev1.RouteList{}
+		require.NoError(t, cl.List(context.Background(), &crclient.ListOptions{}, routes))
+		assert.Empty(t, routes.Items, "route probe created")
+	})
+
+	t.Run("app dns from ingress config", func(t *testing.T) {
+		// given
+		err := apis.AddToScheme(scheme.Scheme)
+		require.NoError(t, err)
+		cl := client.NewClient(fake.NewFakeClient(&configv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: ConfigName},
+			Spec:       configv1.IngressSpec{Domain: "apps.dev11.devcluster.openshift.com"},
+		}))
+		informer := configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}}
+		clusterData := &clusterclient.CreateClusterData{}
+
+		// when
+		err = appDNS(informer, withRouteHost("foo-dipakpawar231.8a09.starter-us-east-2.openshiftapps.com"))(context.Background(), clusterData)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "apps.dev11.devcluster.openshift.com", clusterData.AppDNS)
+	})
+
+	t.Run("app dns without routes", func(t *testing.T) {
+		t.Run("not configured", func(t *testing.T) {
+			// given
+			cl := client.NewClient(fake.NewFakeClient())
+			informer := configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.Capabilities{}, Platform{}}
+			clusterData := &clusterclient.CreateClusterData{}
+
+			// when
+			err := appDNS(informer)(context.Background(), clusterData)
+
+			// then
+			assert.EqualError(t, err, "domain of applications isn't known on cluster without routes, set 'ingressDomain'")
+			assert.Empty(t, clusterData.AppDNS)
+		})
+
+		t.Run("configured", func(t *testing.T) {
+			// given
+			cl := client.NewClient(fake.NewFakeClient())
+			informer := configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.Capabilities{}, Platform{IngressDomain: "apps.k8s.example.com"}}
+			clusterData := &clusterclient.CreateClusterData{}
+
+			// when
+			err := appDNS(informer)(context.Background(), clusterData)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, "apps.k8s.example.com", clusterData.AppDNS)
+		})
+	})
+
 	t.Run("token provider", func(t *testing.T) {
 		// given
 		clusterData := &clusterclient.CreateClusterData{}
 		tokenProviderOption := tokenProvider()
 
 		// when
-		err := tokenProviderOption(clusterData)
+		err := tokenProviderOption(context.Background(), clusterData)
 		require.NoError(t, err)
 
 		// then
@@ -199,7 +352,7 @@ func TestConfigOption(t *testing.T) {
 		typeOSDOption := typeOSD()
 
 		// when
-		err := typeOSDOption(clusterData)
+		err := typeOSDOption(context.Background(), clusterData)
 		require.NoError(t, err)
 
 		// then
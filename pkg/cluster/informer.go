@@ -1,8 +1,13 @@
 package cluster
 
 import (
+	"context"
+
 	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/oidc"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
 
@@ -11,32 +16,69 @@ var log = logf.Log.WithName("cluster_config_informer")
 type configInformer struct {
 	oc          client.Client
 	ns          string
+	saName      string
 	clusterName string
+	caps        capabilities.Capabilities
+	platform    Platform
+}
+
+// Platform supplies configuration of the cluster which can't be read from OpenShift APIs it doesn't serve
+type Platform struct {
+	// APIURL is the URL of the API server registered if the cluster doesn't serve config.openshift.io, it's formed from
+	// the cluster name if empty
+	APIURL string
+	// IngressDomain is the domain of applications registered if the cluster doesn't serve routes
+	IngressDomain string
+	// OIDC provisions the client auth service logs in users with
+	OIDC oidc.Provisioner
 }
 
 type ConfigInformer interface {
-	Inform(options ...SASecretOption) (*clusterclient.CreateClusterData, error)
+	Inform(ctx context.Context, options ...SASecretOption) (*clusterclient.CreateClusterData, error)
+}
+
+// NewConfigInformer returns ConfigInformer reading configuration of the cluster with the given capabilities, so that
+// only APIs served by it are called. The rest is supplied by the given platform. Token of the Service Account of the
+// given name from the given namespace is registered
+func NewConfigInformer(oc client.Client, ns, saName string, clusterName string, caps capabilities.Capabilities, platform Platform) ConfigInformer {
+	return configInformer{oc, ns, saName, clusterName, caps, platform}
+}
+
+// NewReadOnlyConfigInformer returns ConfigInformer like NewConfigInformer does, which never creates anything on the
+// cluster. App DNS detected only by probing with a route is left empty, see UnresolvedAppDNS
+func NewReadOnlyConfigInformer(oc client.Client, ns, saName string, clusterName string, caps capabilities.Capabilities, platform Platform) ConfigInformer {
+	return readOnlyConfigInformer{configInformer{oc, ns, saName, clusterName, caps, platform}}
+}
+
+func (i configInformer) Inform(ctx context.Context, options ...SASecretOption) (*clusterclient.CreateClusterData, error) {
+	return i.inform(ctx, appDNS(i), options...)
+}
+
+type readOnlyConfigInformer struct {
+	configInformer
 }
 
-func NewConfigInformer(oc client.Client, ns string, clusterName string) ConfigInformer {
-	return configInformer{oc, ns, clusterName}
+func (i readOnlyConfigInformer) Inform(ctx context.Context, options ...SASecretOption) (*clusterclient.CreateClusterData, error) {
+	return i.inform(ctx, readOnlyAppDNS(i.configInformer), options...)
 }
 
-func (i configInformer) Inform(options ...SASecretOption) (*clusterclient.CreateClusterData, error) {
-	return buildClusterConfiguration(
-		appDNS(i),
-		clusterNameAndAPIURL(i),
-		oauthClient(i),
-		serviceAccount(i, options...),
-		tokenProvider(),
-		typeOSD(),
+func (i configInformer) inform(ctx context.Context, appDNS configOption, options ...SASecretOption) (*clusterclient.CreateClusterData, error) {
+	return buildClusterConfiguration(ctx,
+		traced("appDNS", appDNS),
+		traced("clusterNameAndAPIURL", clusterNameAndAPIURL(i)),
+		traced("oauthClient", oauthClient(i)),
+		traced("serviceAccount", serviceAccount(i, options...)),
+		traced("tokenProvider", tokenProvider()),
+		traced("typeOSD", typeOSD()),
 	)
 }
 
-func buildClusterConfiguration(opts ...configOption) (*clusterclient.CreateClusterData, error) {
+func buildClusterConfiguration(ctx context.Context, opts ...configOption) (_ *clusterclient.CreateClusterData, err error) {
+	ctx, span := tracing.Start(ctx, "buildClusterConfiguration")
+	defer tracing.End(span, &err)
 	var cluster clusterclient.CreateClusterData
 	for _, opt := range opts {
-		err := opt(&cluster)
+		err := opt(ctx, &cluster)
 		if err != nil {
 			return nil, err
 		}
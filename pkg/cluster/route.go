@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	routev1 "github.com/openshift/api/route/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -10,7 +11,7 @@ import (
 const RouteName = "toolchain-route"
 
 // routingSubDomain returns default routing sub-domain configured in openshift master. For more info check https://bit.ly/2Dj2kfh
-func routingSubDomain(i configInformer, options ...RouteOption) (string, error) {
+func routingSubDomain(ctx context.Context, i configInformer, options ...RouteOption) (string, error) {
 	route := &routev1.Route{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      RouteName,
@@ -35,12 +36,12 @@ func routingSubDomain(i configInformer, options ...RouteOption) (string, error)
 		opt(route)
 	}
 
-	if err := i.oc.CreateRoute(route); err != nil {
+	if err := i.oc.CreateRoute(ctx, route); err != nil {
 		return "", err
 	}
 
 	defer func() {
-		if err := i.oc.DeleteRoute(route); err != nil {
+		if err := i.oc.DeleteRoute(ctx, route); err != nil {
 			log.Error(err, "failed to delete route", "RouteName", RouteName)
 		}
 	}()
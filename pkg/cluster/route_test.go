@@ -1,8 +1,11 @@
 package cluster
 
 import (
+	"context"
 	"github.com/fabric8-services/toolchain-operator/pkg/apis"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
 	"github.com/magiconair/properties/assert"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/stretchr/testify/require"
@@ -16,14 +19,14 @@ func TestRoutingSubDomain(t *testing.T) {
 	err := apis.AddToScheme(scheme.Scheme)
 	require.NoError(t, err)
 	cl := client.NewClient(fake.NewFakeClient())
-	i := configInformer{cl, "test-configInformer", "test-cluster"}
+	i := configInformer{cl, "test-configInformer", config.DefaultSAName, "test-cluster", capabilities.OpenShift4, Platform{}}
 
 	// when
-	sd, err := routingSubDomain(i, withRouteHost("foo-dipakpawar231.8a09.starter-us-east-2.openshiftapps.com"))
+	sd, err := routingSubDomain(context.Background(), i, withRouteHost("foo-dipakpawar231.8a09.starter-us-east-2.openshiftapps.com"))
 
 	//then
 	require.NoError(t, err)
-	_, err = cl.GetRoute("test-configInformer", RouteName)
+	_, err = cl.GetRoute(context.Background(), "test-configInformer", RouteName)
 	require.Error(t, err, "couldn't delete route")
 	require.EqualError(t, err, "routes.route.openshift.io \"toolchain-route\" not found")
 
@@ -0,0 +1,195 @@
+package cluster
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-common/httpsupport"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
+	errs "github.com/pkg/errors"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newTransport returns transport for calls to auth and cluster service. It trusts additional CA bundle, presents client
+// certificate and goes through proxy if configured, and fails if connection to remote service couldn't be established
+// or if remote service didn't respond within configured timeouts
+func newTransport(config Config) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	connectTimeout := config.GetHTTPConnectTimeout()
+	return &http.Transport{
+		Proxy: proxyFunc(config),
+		DialContext: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: config.GetHTTPResponseTimeout(),
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}, nil
+}
+
+func newTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if caBundle := config.GetCABundle(); len(caBundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			log.Info("couldn't load system cert pool, trusting given CA bundle only", "error", err.Error())
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, errs.New("couldn't find any valid PEM encoded certificate in CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(config.GetClientCertificate()) > 0 {
+		cert, err := tls.X509KeyPair(config.GetClientCertificate(), config.GetClientKey())
+		if err != nil {
+			return nil, errs.Wrapf(err, "invalid client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// proxyFunc returns proxy configured in spec, falling back to proxy environment variables if there is none
+func proxyFunc(config Config) func(*http.Request) (*url.URL, error) {
+	if config.GetHTTPProxy() == "" && config.GetHTTPSProxy() == "" {
+		return http.ProxyFromEnvironment
+	}
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  config.GetHTTPProxy(),
+		HTTPSProxy: config.GetHTTPSProxy(),
+		NoProxy:    config.GetNoProxy(),
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// clients caches HTTP clients of auth and cluster service, so that connections are reused across reconciles and probes
+var clients = &clientCache{entries: map[string]cachedClient{}}
+
+// clientCache keeps single HTTP client per ToolChainEnabler. The client is replaced once the configuration of the
+// ToolChainEnabler changes, e.g. when client certificate is rotated or cluster service is moved, and it's evicted once
+// the ToolChainEnabler is deleted
+type clientCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedClient
+}
+
+type cachedClient struct {
+	fingerprint string
+	client      *http.Client
+	transport   *http.Transport
+}
+
+// newHTTPClient returns HTTP client for calls to auth and cluster service of the given configuration. Options given by
+// caller, e.g. transport of tests, are applied to a copy of the cached client, so that they don't leak to other calls.
+// Requests of the client are traced whatever transport is set by the options
+func newHTTPClient(config Config, options ...httpsupport.HTTPClientOption) (*http.Client, error) {
+	cached, err := clients.get(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(options) == 0 {
+		return cached, nil
+	}
+	client := *cached
+	for _, opt := range append(options, withTracing()) {
+		opt(&client)
+	}
+	return &client, nil
+}
+
+// withTracing starts a client span for each request of the client and propagates trace context in its headers
+func withTracing() httpsupport.HTTPClientOption {
+	return func(client *http.Client) {
+		client.Transport = tracing.Transport(client.Transport)
+	}
+}
+
+// EvictHTTPClient drops HTTP client of the ToolChainEnabler with the given namespace and name, closing its idle
+// connections. It's called once the ToolChainEnabler is deleted
+func EvictHTTPClient(owner string) {
+	clients.evict(owner)
+}
+
+func (c *clientCache) get(config Config) (*http.Client, error) {
+	key := config.GetOwner()
+	if key == "" {
+		key = config.GetAuthServiceURL() + " " + config.GetClusterServiceURL() + " " + config.GetClientID()
+	}
+	fingerprint := transportFingerprint(config)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, found := c.entries[key]; found {
+		if cached.fingerprint == fingerprint {
+			return cached.client, nil
+		}
+		c.drop(key)
+	}
+
+	transport, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: tracing.Transport(transport)}
+	c.entries[key] = cachedClient{fingerprint: fingerprint, client: client, transport: transport}
+	return client, nil
+}
+
+func (c *clientCache) evict(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drop(key)
+}
+
+// drop removes the client of the given key, connections it established aren't reused, requests in flight are finished
+func (c *clientCache) drop(key string) {
+	cached, found := c.entries[key]
+	if !found {
+		return
+	}
+	cached.transport.CloseIdleConnections()
+	delete(c.entries, key)
+}
+
+// transportFingerprint returns digest of the remote services and of all the settings newTransport depends on
+func transportFingerprint(config Config) string {
+	h := sha256.New()
+	for _, v := range [][]byte{
+		[]byte(config.GetAuthServiceURL()),
+		[]byte(config.GetClusterServiceURL()),
+		[]byte(config.GetClientID()),
+		config.GetCABundle(),
+		config.GetClientCertificate(),
+		config.GetClientKey(),
+		[]byte(config.GetHTTPProxy()),
+		[]byte(config.GetHTTPSProxy()),
+		[]byte(config.GetNoProxy()),
+		[]byte(config.GetHTTPConnectTimeout().String()),
+		[]byte(config.GetHTTPResponseTimeout().String()),
+	} {
+		h.Write(v)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
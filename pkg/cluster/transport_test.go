@@ -0,0 +1,215 @@
+package cluster
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-common/httpsupport"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	t.Run("trust ca bundle", func(t *testing.T) {
+		// given
+		c := newConfig()
+		c.CABundle = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+		tr, err := newTransport(c)
+		require.NoError(t, err)
+
+		// when
+		res, err := (&http.Client{Transport: tr}).Get(srv.URL)
+
+		// then
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("unknown authority without ca bundle", func(t *testing.T) {
+		// given
+		tr, err := newTransport(newConfig())
+		require.NoError(t, err)
+
+		// when
+		_, err = (&http.Client{Transport: tr}).Get(srv.URL)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "certificate signed by unknown authority")
+	})
+
+	t.Run("invalid ca bundle", func(t *testing.T) {
+		// given
+		c := newConfig()
+		c.CABundle = []byte("invalid")
+
+		// when
+		_, err := newTransport(c)
+
+		// then
+		assert.EqualError(t, err, "couldn't find any valid PEM encoded certificate in CA bundle")
+	})
+
+	t.Run("invalid client certificate", func(t *testing.T) {
+		// given
+		c := newConfig()
+		c.ClientCertificate = []byte("invalid")
+		c.ClientKey = []byte("invalid")
+
+		// when
+		_, err := newTransport(c)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid client certificate")
+	})
+}
+
+func TestProxyFunc(t *testing.T) {
+	// given
+	c := newConfig()
+	c.HTTPProxy = "http://proxy:3128"
+	c.HTTPSProxy = "http://secure-proxy:3128"
+	c.NoProxy = ".svc,auth"
+	proxy := proxyFunc(c)
+
+	t.Run("https", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "https://cluster.openshift.io/api/clusters", nil)
+		require.NoError(t, err)
+
+		u, err := proxy(req)
+
+		require.NoError(t, err)
+		require.NotNil(t, u)
+		assert.Equal(t, "secure-proxy:3128", u.Host)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://cluster.openshift.io/api/clusters", nil)
+		require.NoError(t, err)
+
+		u, err := proxy(req)
+
+		require.NoError(t, err)
+		require.NotNil(t, u)
+		assert.Equal(t, "proxy:3128", u.Host)
+	})
+
+	t.Run("no proxy", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://auth/api/token", nil)
+		require.NoError(t, err)
+
+		u, err := proxy(req)
+
+		require.NoError(t, err)
+		assert.Nil(t, u)
+	})
+}
+
+func TestNewHTTPClient(t *testing.T) {
+
+	t.Run("cached per configuration", func(t *testing.T) {
+		// given
+		c := newConfig()
+		c.ClusterURL = "http://cluster-cached"
+
+		// when
+		first, err := newHTTPClient(c)
+		require.NoError(t, err)
+		second, err := newHTTPClient(c)
+		require.NoError(t, err)
+
+		// then
+		assert.True(t, first == second)
+		assert.True(t, http.DefaultClient != first)
+	})
+
+	t.Run("replaced once transport settings change", func(t *testing.T) {
+		// given
+		c := newConfig()
+		c.ClusterURL = "http://cluster-replaced"
+		first, err := newHTTPClient(c)
+		require.NoError(t, err)
+
+		// when
+		c.HTTPSProxy = "http://secure-proxy:3128"
+		second, err := newHTTPClient(c)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, first != second)
+		assert.True(t, first.Transport != second.Transport)
+	})
+
+	t.Run("replaced once cluster service changes", func(t *testing.T) {
+		// given
+		c := newConfig()
+		c.Owner = "toolchain-enabler/moved"
+		c.ClusterURL = "http://cluster-moved"
+		first, err := newHTTPClient(c)
+		require.NoError(t, err)
+
+		// when
+		c.ClusterURL = "http://cluster-moved-elsewhere"
+		second, err := newHTTPClient(c)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, first != second)
+		assert.Len(t, entriesOf("toolchain-enabler/moved"), 1)
+	})
+
+	t.Run("evicted once owner is deleted", func(t *testing.T) {
+		// given
+		c := newConfig()
+		c.Owner = "toolchain-enabler/deleted"
+		first, err := newHTTPClient(c)
+		require.NoError(t, err)
+
+		// when
+		EvictHTTPClient("toolchain-enabler/deleted")
+
+		// then
+		assert.Empty(t, entriesOf("toolchain-enabler/deleted"))
+		second, err := newHTTPClient(c)
+		require.NoError(t, err)
+		assert.True(t, first != second)
+	})
+
+	t.Run("options applied to copy", func(t *testing.T) {
+		// given
+		c := newConfig()
+		c.ClusterURL = "http://cluster-options"
+		cached, err := newHTTPClient(c)
+		require.NoError(t, err)
+		transport := cached.Transport
+
+		// when
+		client, err := newHTTPClient(c, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, tracing.Transport(http.DefaultTransport), client.Transport)
+		assert.Equal(t, transport, cached.Transport)
+	})
+}
+
+// entriesOf returns the cached clients of the given owner
+func entriesOf(owner string) []cachedClient {
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+	var entries []cachedClient
+	if cached, found := clients.entries[owner]; found {
+		entries = append(entries, cached)
+	}
+	return entries
+}
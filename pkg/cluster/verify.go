@@ -0,0 +1,161 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
+	"github.com/fabric8-services/fabric8-common/goasupport"
+	"github.com/fabric8-services/fabric8-common/httpsupport"
+	"github.com/fabric8-services/toolchain-operator/pkg/redact"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+// registeredCluster is the cluster configuration stored in cluster management service
+type registeredCluster struct {
+	APIURL                 string `json:"api-url"`
+	AppDNS                 string `json:"app-dns"`
+	AuthClientID           string `json:"auth-client-id"`
+	AuthClientSecret       string `json:"auth-client-secret"`
+	ServiceAccountUsername string `json:"service-account-username"`
+	CapacityExhausted      bool   `json:"capacity-exhausted"`
+}
+
+// Verification is the result of verification of the cluster registered in cluster management service
+type Verification struct {
+	// Found is false if the cluster isn't registered in cluster management service
+	Found bool
+	// Mismatches lists fields of the registered cluster which don't match the expected cluster configuration
+	Mismatches []string
+	// Reregistered is true if the cluster has been registered again as it was missing or didn't match
+	Reregistered bool
+}
+
+// VerifyCluster fetches the cluster registered in cluster service and registers it again if it's missing or doesn't
+// match the given cluster configuration
+func (s *clusterService) VerifyCluster(ctx context.Context, data *clusterclient.CreateClusterData, options ...httpsupport.HTTPClientOption) (Verification, error) {
+	remoteClusterService, err := s.signedClient(ctx, options...)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	v, err := s.compareCluster(ctx, remoteClusterService, data)
+	if err != nil {
+		return v, err
+	}
+	if v.Found {
+		if len(v.Mismatches) == 0 {
+			return v, nil
+		}
+		log.Info("registered cluster doesn't match cluster configuration", "cluster", data.APIURL, "mismatches", v.Mismatches)
+	} else {
+		log.Info("cluster isn't registered in cluster management service", "cluster", data.APIURL)
+	}
+
+	if err := s.createCluster(ctx, remoteClusterService, data); err != nil {
+		return v, err
+	}
+	v.Reregistered = true
+	return v, nil
+}
+
+// CompareCluster fetches the cluster registered in cluster service and compares it with the given cluster
+// configuration, the cluster is never registered again
+func (s *clusterService) CompareCluster(ctx context.Context, data *clusterclient.CreateClusterData, options ...httpsupport.HTTPClientOption) (Verification, error) {
+	remoteClusterService, err := s.signedClient(ctx, options...)
+	if err != nil {
+		return Verification{}, err
+	}
+	return s.compareCluster(ctx, remoteClusterService, data)
+}
+
+func (s clusterService) compareCluster(ctx context.Context, remoteClusterService *clusterclient.Client, data *clusterclient.CreateClusterData) (Verification, error) {
+	var v Verification
+	registered, err := s.getCluster(ctx, remoteClusterService, data.APIURL)
+	if err != nil {
+		return v, err
+	}
+	if registered != nil {
+		v.Found = true
+		v.Mismatches = compare(data, registered)
+	}
+	return v, nil
+}
+
+// getCluster returns the cluster registered with the given API URL or nil if it isn't registered
+func (s clusterService) getCluster(ctx context.Context, remoteClusterService *clusterclient.Client, apiURL string) (_ *registeredCluster, err error) {
+	ctx, span := tracing.Start(ctx, "ShowCluster", trace.StringAttribute("cluster.api_url", apiURL))
+	defer tracing.End(span, &err)
+	clusterURL := s.config.GetClusterServiceURL()
+	u := strings.TrimSuffix(clusterURL, "/") + "/api/clusters/auth?cluster-url=" + url.QueryEscape(apiURL)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for registered cluster %s", apiURL)
+	}
+	if remoteClusterService.JWTSigner != nil {
+		if err := remoteClusterService.JWTSigner.Sign(req); err != nil {
+			return nil, errors.Wrapf(err, "failed to sign request for registered cluster %s", apiURL)
+		}
+	}
+
+	res, err := remoteClusterService.Do(goasupport.ForwardContextRequestID(ctx), req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get cluster configuration for cluster %s", apiURL)
+	}
+	defer func() {
+		if err := httpsupport.CloseResponse(res); err != nil {
+			log.Error(err, "error during closing response body when getting cluster configuration")
+		}
+	}()
+
+	bodyString, err := httpsupport.ReadBody(res.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read response while getting cluster configuration")
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, errors.Errorf("received unexpected response code while getting cluster configuration from cluster management service. Response status: %s. Response body: %s", res.Status, redact.Text(bodyString))
+	}
+
+	var payload struct {
+		Data *registeredCluster `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(bodyString), &payload); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse cluster configuration of cluster %s", apiURL)
+	}
+	return payload.Data, nil
+}
+
+// compare returns names of the fields of the registered cluster which don't match the expected cluster configuration
+func compare(expected *clusterclient.CreateClusterData, actual *registeredCluster) []string {
+	var mismatches []string
+	if trailingSlash(expected.APIURL) != trailingSlash(actual.APIURL) {
+		mismatches = append(mismatches, "api-url")
+	}
+	if expected.AppDNS != actual.AppDNS {
+		mismatches = append(mismatches, "app-dns")
+	}
+	if expected.AuthClientID != actual.AuthClientID {
+		mismatches = append(mismatches, "auth-client-id")
+	}
+	if expected.AuthClientSecret != actual.AuthClientSecret {
+		mismatches = append(mismatches, "auth-client-secret")
+	}
+	if expected.ServiceAccountUsername != actual.ServiceAccountUsername {
+		mismatches = append(mismatches, "service-account-username")
+	}
+	return mismatches
+}
+
+// trailingSlash adds trailing slash to the given URL, as cluster service stores URLs with trailing slash
+func trailingSlash(u string) string {
+	return strings.TrimSuffix(u, "/") + "/"
+}
@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-common/httpsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+const registeredClusterBody = `{"data":{"api-url":"https://api.dsaas-stage.openshift.com/","app-dns":"8a09.starter-us-east-2.openshiftapps.com","auth-client-id":"codeready-toolchain","auth-client-secret":"oauthsecret","name":"dsaas-stage","service-account-username":"system:serviceaccount:config-test:toolchain-sre","type":"OSD"}}`
+
+func TestVerifyCluster(t *testing.T) {
+	c := newConfig()
+	i := dummyClusterConfigInformer{clusterName: c.ClusterName}
+	clusterData, err := i.Inform(context.Background())
+	require.NoError(t, err)
+
+	t.Run("matched", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			MatchParam("cluster-url", "https://api.dsaas-stage.openshift.com/").
+			MatchHeader("Authorization", "Bearer "+TOKEN).
+			Reply(200).
+			BodyString(registeredClusterBody)
+
+		// when
+		v, err := NewClusterService(c).VerifyCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, v.Found)
+		assert.Empty(t, v.Mismatches)
+		assert.False(t, v.Reregistered)
+		assert.True(t, gock.IsDone(), "pending mocks: %v", gock.Pending())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(404)
+		gock.New("http://cluster").
+			Post("api/clusters").
+			MatchHeader("Authorization", "Bearer "+TOKEN).
+			Reply(201)
+
+		// when
+		v, err := NewClusterService(c).VerifyCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, v.Found)
+		assert.True(t, v.Reregistered)
+		assert.True(t, gock.IsDone(), "pending mocks: %v", gock.Pending())
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(200).
+			BodyString(`{"data":{"api-url":"https://api.dsaas-stage.openshift.com","app-dns":"changed","auth-client-id":"codeready-toolchain","auth-client-secret":"changed","service-account-username":"system:serviceaccount:config-test:toolchain-sre"}}`)
+		gock.New("http://cluster").
+			Post("api/clusters").
+			MatchHeader("Authorization", "Bearer "+TOKEN).
+			Reply(201)
+
+		// when
+		v, err := NewClusterService(c).VerifyCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, v.Found)
+		assert.Equal(t, []string{"app-dns", "auth-client-secret"}, v.Mismatches)
+		assert.True(t, v.Reregistered)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(500).
+			BodyString("something went wrong")
+
+		// when
+		_, err := NewClusterService(c).VerifyCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		assert.EqualError(t, err, "received unexpected response code while getting cluster configuration from cluster management service. Response status: 500 Internal Server Error. Response body: something went wrong")
+	})
+}
+
+func TestCompareCluster(t *testing.T) {
+	c := newConfig()
+	i := dummyClusterConfigInformer{clusterName: c.ClusterName}
+	clusterData, err := i.Inform(context.Background())
+	require.NoError(t, err)
+
+	t.Run("mismatch isn't registered again", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(200).
+			BodyString(`{"data":{"api-url":"https://api.dsaas-stage.openshift.com","app-dns":"changed","auth-client-id":"codeready-toolchain","auth-client-secret":"oauthsecret","service-account-username":"system:serviceaccount:config-test:toolchain-sre"}}`)
+
+		// when
+		v, err := NewClusterService(c).CompareCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, v.Found)
+		assert.Equal(t, []string{"app-dns"}, v.Mismatches)
+		assert.False(t, v.Reregistered)
+		assert.True(t, gock.IsDone(), "pending mocks: %v", gock.Pending())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		// given
+		defer gock.OffAll()
+		setupGockForAuth()
+		gock.New("http://cluster").
+			Get("api/clusters/auth").
+			Reply(404)
+
+		// when
+		v, err := NewClusterService(c).CompareCluster(context.Background(), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, v.Found)
+		assert.False(t, v.Reregistered)
+	})
+}
@@ -0,0 +1,407 @@
+package component
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	errs "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("component")
+
+const (
+	// OwnerNameLabel is the name of namespaced owner of cluster-scoped resource
+	OwnerNameLabel = "codeready.openshift.io/owner-name"
+	// OwnerNamespaceLabel is the namespace of namespaced owner of cluster-scoped resource
+	OwnerNamespaceLabel = "codeready.openshift.io/owner-namespace"
+	// AdoptedAnnotation records when existing resource was adopted and which of its fields were changed
+	AdoptedAnnotation = "codeready.openshift.io/adopted"
+)
+
+// Adoption decides what happens to existing resource which isn't owned by the operator, e.g. as it was created
+// manually before the operator was deployed
+type Adoption string
+
+const (
+	// Ignore leaves the existing resource as it is, unowned and unreconciled
+	Ignore Adoption = "Ignore"
+	// Adopt takes ownership of the existing resource and converges it to the desired state
+	Adopt Adoption = "Adopt"
+	// Fail leaves the existing resource as it is and returns NotOwnedError
+	Fail Adoption = "Fail"
+)
+
+// AdoptionRecord is the value of AdoptedAnnotation
+type AdoptionRecord struct {
+	Time    metav1.Time `json:"time"`
+	Changed []string    `json:"changed"`
+}
+
+// NotOwnedError is returned when existing resource isn't owned by the operator and it's not allowed to adopt it
+type NotOwnedError struct {
+	Description string
+}
+
+func (e NotOwnedError) Error() string {
+	return e.Description + " already exists and isn't owned by the operator"
+}
+
+// IsNotOwned returns true if the error is caused by existing resource which isn't owned by the operator
+func IsNotOwned(err error) bool {
+	_, ok := errs.Cause(err).(NotOwnedError)
+	return ok
+}
+
+// Object is a kubernetes resource provisioned as a component
+type Object interface {
+	metav1.Object
+	runtime.Object
+}
+
+// Ownership sets owner of the desired resource before it's created or updated
+type Ownership func(obj metav1.Object) error
+
+// ControlledBy returns Ownership which sets the given owner as the controller of the resource, so that the resource
+// is garbage collected together with its owner
+func ControlledBy(owner metav1.Object, scheme *runtime.Scheme) Ownership {
+	return func(obj metav1.Object) error {
+		return controllerutil.SetControllerReference(owner, obj, scheme)
+	}
+}
+
+// LabeledBy returns Ownership which labels cluster-scoped resource with the given namespaced owner. Garbage collector
+// doesn't honour namespaced owners of cluster-scoped resources, so labeled resources have to be deleted explicitly
+func LabeledBy(owner metav1.Object) Ownership {
+	return func(obj metav1.Object) error {
+		Label(obj, owner)
+		return nil
+	}
+}
+
+// Label sets owner labels of the given owner on the resource and removes owner references to it, which are ignored on
+// cluster-scoped resources. It returns true if anything has changed
+func Label(obj, owner metav1.Object) bool {
+	changed := false
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range OwnerLabels(owner) {
+		if labels[k] != v {
+			labels[k] = v
+			changed = true
+		}
+	}
+	obj.SetLabels(labels)
+
+	var refs []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID != owner.GetUID() {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) != len(obj.GetOwnerReferences()) {
+		obj.SetOwnerReferences(refs)
+		changed = true
+	}
+	return changed
+}
+
+// OwnerLabels returns labels tracking the given namespaced owner of cluster-scoped resource
+func OwnerLabels(owner metav1.Object) map[string]string {
+	return map[string]string{
+		OwnerNameLabel:      owner.GetName(),
+		OwnerNamespaceLabel: owner.GetNamespace(),
+	}
+}
+
+// OwnedBy returns true if the existing resource is either controlled by or labeled with the given owner
+func OwnedBy(owner metav1.Object) func(existing Object) bool {
+	return func(existing Object) bool {
+		if metav1.IsControlledBy(existing, owner) {
+			return true
+		}
+		labels := existing.GetLabels()
+		return labels[OwnerNameLabel] == owner.GetName() && labels[OwnerNamespaceLabel] == owner.GetNamespace()
+	}
+}
+
+// Unowned leaves the resource without any owner, e.g. for resources shared by all ToolChainEnablers
+func Unowned(metav1.Object) error {
+	return nil
+}
+
+// Component describes a resource provisioned by the operator
+type Component struct {
+	// Description identifies the resource in logs and errors, e.g. "service account toolchain-sre"
+	Description string
+	// Desired builds desired state of the resource
+	Desired func() (Object, error)
+	// Ownership sets owner of the desired resource. The resource is left without owner if nil
+	Ownership Ownership
+	// Get returns current state of the resource or NotFound error if it doesn't exist
+	Get func(ctx context.Context) (Object, error)
+	// Create creates the resource from its desired state
+	Create func(ctx context.Context, obj Object) error
+	// Mutate copies fields managed by the operator from desired state to the existing resource and returns true if
+	// anything has changed. Existing resource is never updated if nil
+	Mutate func(existing, desired Object) bool
+	// Update updates the existing resource, required if Mutate is set
+	Update func(ctx context.Context, obj Object) error
+	// Delete deletes the resource
+	Delete func(ctx context.Context, obj Object) error
+	// Ready returns true if the existing resource can be used. The resource is ready as soon as it exists if nil
+	Ready func(existing Object) bool
+	// Owned returns true if the existing resource is owned by the operator. Any existing resource is considered owned
+	// if nil
+	Owned func(existing Object) bool
+	// Adoption decides what happens to existing resource which isn't owned, Ignore if not set
+	Adoption Adoption
+}
+
+// Ensure creates resource of the given component if it doesn't exist, or updates it if it has drifted from the
+// desired state
+func Ensure(ctx context.Context, c Component) error {
+	desired, err := c.desired()
+	if err != nil {
+		return err
+	}
+
+	existing, err := c.Get(ctx)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("creating", "component", c.Description)
+			if err := c.Create(ctx, desired); err != nil {
+				return errs.Wrapf(err, "failed to create %s", c.Description)
+			}
+
+			log.Info(c.Description + " created successfully")
+			return nil
+		}
+		return errs.Wrapf(err, "failed to get %s", c.Description)
+	}
+
+	if c.Owned != nil && !c.Owned(existing) {
+		return c.adopt(ctx, existing, desired)
+	}
+
+	if c.Mutate == nil || !c.Mutate(existing, desired) {
+		log.Info(c.Description + " already exists")
+		return nil
+	}
+
+	log.Info("updating", "component", c.Description)
+	if err := c.Update(ctx, existing); err != nil {
+		return errs.Wrapf(err, "failed to update %s", c.Description)
+	}
+	log.Info(c.Description + " updated successfully")
+
+	return nil
+}
+
+// Diff returns how the existing resource of the given component differs from its desired state without changing
+// anything, empty if it doesn't
+func Diff(ctx context.Context, c Component) (string, error) {
+	desired, err := c.desired()
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := c.Get(ctx)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return c.Description + " doesn't exist", nil
+		}
+		return "", errs.Wrapf(err, "failed to get %s", c.Description)
+	}
+
+	if c.Owned != nil && !c.Owned(existing) {
+		return c.Description + " isn't owned by the operator", nil
+	}
+	if c.Mutate == nil {
+		return "", nil
+	}
+	mutated := existing.DeepCopyObject().(Object)
+	if !c.Mutate(mutated, desired) {
+		return "", nil
+	}
+	changed, err := changedFields(existing, mutated)
+	if err != nil {
+		return "", err
+	}
+	if len(changed) == 0 {
+		return c.Description + " differs from the desired state", nil
+	}
+	return fmt.Sprintf("%s differs from the desired state in %s", c.Description, strings.Join(changed, ", ")), nil
+}
+
+// Delete deletes resource of the given component if it exists
+func Delete(ctx context.Context, c Component) error {
+	desired, err := c.Desired()
+	if err != nil {
+		return err
+	}
+
+	if err := c.Delete(ctx, desired); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return errs.Wrapf(err, "failed to delete %s", c.Description)
+	}
+	log.Info(c.Description + " deleted successfully")
+
+	return nil
+}
+
+// DeleteOwned deletes resource of the given component if it exists and is owned by the operator, resource which isn't
+// owned is left as it is
+func DeleteOwned(ctx context.Context, c Component) error {
+	existing, err := c.Get(ctx)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return errs.Wrapf(err, "failed to get %s", c.Description)
+	}
+	if c.Owned != nil && !c.Owned(existing) {
+		log.Info(c.Description + " isn't owned by the operator, it's not deleted")
+		return nil
+	}
+
+	if err := c.Delete(ctx, existing); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return errs.Wrapf(err, "failed to delete %s", c.Description)
+	}
+	log.Info(c.Description + " deleted successfully")
+
+	return nil
+}
+
+// IsReady returns true if resource of the given component exists and is ready to be used
+func IsReady(ctx context.Context, c Component) (bool, error) {
+	existing, err := c.Get(ctx)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errs.Wrapf(err, "failed to get %s", c.Description)
+	}
+
+	if c.Ready == nil {
+		return true, nil
+	}
+	return c.Ready(existing), nil
+}
+
+// adopt applies adoption policy of the component to the existing resource which isn't owned. Adopted resource gets the
+// owner, is converged to the desired state and annotated with fields which have been changed
+func (c Component) adopt(ctx context.Context, existing, desired Object) error {
+	switch c.Adoption {
+	case Adopt:
+	case Fail:
+		return NotOwnedError{Description: c.Description}
+	default:
+		log.Info(c.Description + " already exists and isn't owned by the operator, leaving it as it is")
+		return nil
+	}
+	if c.Update == nil {
+		return errs.Errorf("%s can't be adopted as it's never updated", c.Description)
+	}
+
+	original := existing.DeepCopyObject()
+	if c.Ownership != nil {
+		if err := c.Ownership(existing); err != nil {
+			return errs.Wrapf(err, "failed to adopt %s", c.Description)
+		}
+	}
+	if c.Mutate != nil {
+		c.Mutate(existing, desired)
+	}
+	changed, err := changedFields(original, existing)
+	if err != nil {
+		return errs.Wrapf(err, "failed to adopt %s", c.Description)
+	}
+	record, err := json.Marshal(AdoptionRecord{Time: metav1.Now(), Changed: changed})
+	if err != nil {
+		return errs.Wrapf(err, "failed to adopt %s", c.Description)
+	}
+	annotations := existing.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AdoptedAnnotation] = string(record)
+	existing.SetAnnotations(annotations)
+
+	log.Info("adopting", "component", c.Description, "changed", changed)
+	if err := c.Update(ctx, existing); err != nil {
+		return errs.Wrapf(err, "failed to adopt %s", c.Description)
+	}
+	log.Info(c.Description + " adopted successfully")
+
+	return nil
+}
+
+// changedFields returns sorted paths of top-level fields, and labels, annotations and owner references of metadata
+// which differ between the original and the changed resource
+func changedFields(original, changed runtime.Object) ([]string, error) {
+	o, err := runtime.DefaultUnstructuredConverter.ToUnstructured(original)
+	if err != nil {
+		return nil, err
+	}
+	c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(changed)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []string{}
+	for _, key := range []string{"labels", "annotations", "ownerReferences"} {
+		if !reflect.DeepEqual(metadata(o)[key], metadata(c)[key]) {
+			fields = append(fields, "metadata."+key)
+		}
+	}
+	keys := map[string]bool{}
+	for key := range o {
+		keys[key] = true
+	}
+	for key := range c {
+		keys[key] = true
+	}
+	for key := range keys {
+		if key != "metadata" && !reflect.DeepEqual(o[key], c[key]) {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func metadata(obj map[string]interface{}) map[string]interface{} {
+	m, _ := obj["metadata"].(map[string]interface{})
+	return m
+}
+
+// desired builds desired state of the resource and sets its owner
+func (c Component) desired() (Object, error) {
+	obj, err := c.Desired()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Ownership != nil {
+		if err := c.Ownership(obj); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
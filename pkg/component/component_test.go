@@ -0,0 +1,424 @@
+package component
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	name      = "toolchain"
+	namespace = "codeready-toolchain"
+)
+
+func TestComponent(t *testing.T) {
+
+	t.Run("Ensure", func(t *testing.T) {
+		t.Run("not exists", func(t *testing.T) {
+			//given
+			cl := fake.NewFakeClient()
+
+			//when
+			err := Ensure(context.Background(), configMapComponent(cl, "value"))
+
+			//then
+			require.NoError(t, err)
+			assertConfigMap(t, cl, "value")
+		})
+
+		t.Run("exists", func(t *testing.T) {
+			//given
+			cl := fake.NewFakeClient(configMap("value"))
+			c := configMapComponent(cl, "changed")
+			c.Mutate = nil
+
+			//when
+			err := Ensure(context.Background(), c)
+
+			//then
+			require.NoError(t, err)
+			assertConfigMap(t, cl, "value")
+		})
+
+		t.Run("drifted", func(t *testing.T) {
+			//given
+			cl := fake.NewFakeClient(configMap("value"))
+
+			//when
+			err := Ensure(context.Background(), configMapComponent(cl, "changed"))
+
+			//then
+			require.NoError(t, err)
+			assertConfigMap(t, cl, "changed")
+		})
+
+		t.Run("owned", func(t *testing.T) {
+			//given
+			cl := fake.NewFakeClient()
+			c := configMapComponent(cl, "value")
+			c.Ownership = func(obj metav1.Object) error {
+				obj.SetOwnerReferences([]metav1.OwnerReference{{Name: "owner"}})
+				return nil
+			}
+
+			//when
+			err := Ensure(context.Background(), c)
+
+			//then
+			require.NoError(t, err)
+			cm := &corev1.ConfigMap{}
+			err = cl.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, cm)
+			require.NoError(t, err)
+			require.Len(t, cm.OwnerReferences, 1)
+			assert.Equal(t, "owner", cm.OwnerReferences[0].Name)
+		})
+
+		t.Run("not owned", func(t *testing.T) {
+			owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "owner-uid"}}
+			ownedComponent := func(cl client.Client, adoption Adoption) Component {
+				c := configMapComponent(cl, "changed")
+				c.Ownership = LabeledBy(owner)
+				c.Owned = OwnedBy(owner)
+				c.Adoption = adoption
+				return c
+			}
+
+			t.Run("ignore", func(t *testing.T) {
+				//given
+				cl := fake.NewFakeClient(configMap("value"))
+
+				//when
+				err := Ensure(context.Background(), ownedComponent(cl, ""))
+
+				//then
+				require.NoError(t, err)
+				cm := assertConfigMap(t, cl, "value")
+				assert.Empty(t, cm.Labels)
+				assert.NotContains(t, cm.Annotations, AdoptedAnnotation)
+			})
+
+			t.Run("adopt", func(t *testing.T) {
+				//given
+				cl := fake.NewFakeClient(configMap("value"))
+
+				//when
+				err := Ensure(context.Background(), ownedComponent(cl, Adopt))
+
+				//then
+				require.NoError(t, err)
+				cm := assertConfigMap(t, cl, "changed")
+				assert.Equal(t, OwnerLabels(owner), cm.Labels)
+				record := AdoptionRecord{}
+				require.NoError(t, json.Unmarshal([]byte(cm.Annotations[AdoptedAnnotation]), &record))
+				assert.Equal(t, []string{"data", "metadata.labels"}, record.Changed)
+				assert.False(t, record.Time.IsZero())
+			})
+
+			t.Run("adopt unchanged", func(t *testing.T) {
+				//given
+				cl := fake.NewFakeClient(configMap("changed"))
+
+				//when
+				err := Ensure(context.Background(), ownedComponent(cl, Adopt))
+
+				//then
+				require.NoError(t, err)
+				cm := assertConfigMap(t, cl, "changed")
+				record := AdoptionRecord{}
+				require.NoError(t, json.Unmarshal([]byte(cm.Annotations[AdoptedAnnotation]), &record))
+				assert.Equal(t, []string{"metadata.labels"}, record.Changed)
+			})
+
+			t.Run("fail", func(t *testing.T) {
+				//given
+				cl := fake.NewFakeClient(configMap("value"))
+
+				//when
+				err := Ensure(context.Background(), ownedComponent(cl, Fail))
+
+				//then
+				assert.EqualError(t, err, "configmap toolchain already exists and isn't owned by the operator")
+				assert.True(t, IsNotOwned(err))
+				cm := assertConfigMap(t, cl, "value")
+				assert.Empty(t, cm.Labels)
+			})
+
+			t.Run("owned", func(t *testing.T) {
+				//given
+				existing := configMap("value")
+				existing.Labels = OwnerLabels(owner)
+				cl := fake.NewFakeClient(existing)
+
+				//when
+				err := Ensure(context.Background(), ownedComponent(cl, Fail))
+
+				//then
+				require.NoError(t, err)
+				cm := assertConfigMap(t, cl, "changed")
+				assert.NotContains(t, cm.Annotations, AdoptedAnnotation)
+			})
+		})
+
+		t.Run("fail", func(t *testing.T) {
+			//given
+			c := configMapComponent(fake.NewFakeClient(), "value")
+			c.Get = func(ctx context.Context) (Object, error) {
+				return nil, errors.New("something went wrong")
+			}
+
+			//when
+			err := Ensure(context.Background(), c)
+
+			//then
+			assert.EqualError(t, err, "failed to get configmap toolchain: something went wrong")
+		})
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		t.Run("not exists", func(t *testing.T) {
+			//when
+			diff, err := Diff(context.Background(), configMapComponent(fake.NewFakeClient(), "value"))
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, "configmap toolchain doesn't exist", diff)
+		})
+
+		t.Run("in sync", func(t *testing.T) {
+			//when
+			diff, err := Diff(context.Background(), configMapComponent(fake.NewFakeClient(configMap("value")), "value"))
+
+			//then
+			require.NoError(t, err)
+			assert.Empty(t, diff)
+		})
+
+		t.Run("drifted", func(t *testing.T) {
+			//given
+			cl := fake.NewFakeClient(configMap("value"))
+
+			//when
+			diff, err := Diff(context.Background(), configMapComponent(cl, "changed"))
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, "configmap toolchain differs from the desired state in data", diff)
+			assertConfigMap(t, cl, "value")
+		})
+
+		t.Run("not owned", func(t *testing.T) {
+			//given
+			c := configMapComponent(fake.NewFakeClient(configMap("value")), "value")
+			c.Owned = func(existing Object) bool {
+				return false
+			}
+
+			//when
+			diff, err := Diff(context.Background(), c)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, "configmap toolchain isn't owned by the operator", diff)
+		})
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		t.Run("exists", func(t *testing.T) {
+			//given
+			cl := fake.NewFakeClient(configMap("value"))
+
+			//when
+			err := Delete(context.Background(), configMapComponent(cl, "value"))
+
+			//then
+			require.NoError(t, err)
+			ready, err := IsReady(context.Background(), configMapComponent(cl, "value"))
+			require.NoError(t, err)
+			assert.False(t, ready)
+		})
+
+		t.Run("not exists", func(t *testing.T) {
+			//when
+			err := Delete(context.Background(), configMapComponent(fake.NewFakeClient(), "value"))
+
+			//then
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("DeleteOwned", func(t *testing.T) {
+		owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace}}
+
+		t.Run("owned", func(t *testing.T) {
+			//given
+			owned := configMap("value")
+			owned.Labels = OwnerLabels(owner)
+			cl := fake.NewFakeClient(owned)
+			c := configMapComponent(cl, "value")
+			c.Owned = OwnedBy(owner)
+
+			//when
+			err := DeleteOwned(context.Background(), c)
+
+			//then
+			require.NoError(t, err)
+			ready, err := IsReady(context.Background(), c)
+			require.NoError(t, err)
+			assert.False(t, ready)
+		})
+
+		t.Run("not owned", func(t *testing.T) {
+			//given
+			cl := fake.NewFakeClient(configMap("value"))
+			c := configMapComponent(cl, "value")
+			c.Owned = OwnedBy(owner)
+
+			//when
+			err := DeleteOwned(context.Background(), c)
+
+			//then
+			require.NoError(t, err)
+			assertConfigMap(t, cl, "value")
+		})
+
+		t.Run("not exists", func(t *testing.T) {
+			//when
+			err := DeleteOwned(context.Background(), configMapComponent(fake.NewFakeClient(), "value"))
+
+			//then
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("IsReady", func(t *testing.T) {
+		t.Run("exists", func(t *testing.T) {
+			//when
+			ready, err := IsReady(context.Background(), configMapComponent(fake.NewFakeClient(configMap("value")), "value"))
+
+			//then
+			require.NoError(t, err)
+			assert.True(t, ready)
+		})
+
+		t.Run("not ready", func(t *testing.T) {
+			//given
+			c := configMapComponent(fake.NewFakeClient(configMap("")), "value")
+			c.Ready = func(existing Object) bool {
+				return existing.(*corev1.ConfigMap).Data["key"] != ""
+			}
+
+			//when
+			ready, err := IsReady(context.Background(), c)
+
+			//then
+			require.NoError(t, err)
+			assert.False(t, ready)
+		})
+	})
+}
+
+func configMap(value string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string]string{"key": value},
+	}
+}
+
+func configMapComponent(cl client.Client, value string) Component {
+	return Component{
+		Description: "configmap " + name,
+		Desired: func() (Object, error) {
+			return configMap(value), nil
+		},
+		Get: func(ctx context.Context) (Object, error) {
+			cm := &corev1.ConfigMap{}
+			if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cm); err != nil {
+				return nil, err
+			}
+			return cm, nil
+		},
+		Create: func(ctx context.Context, obj Object) error {
+			return cl.Create(ctx, obj)
+		},
+		Mutate: func(existing, desired Object) bool {
+			e, d := existing.(*corev1.ConfigMap), desired.(*corev1.ConfigMap)
+			if e.Data["key"] == d.Data["key"] {
+				return false
+			}
+			e.Data = d.Data
+			return true
+		},
+		Update: func(ctx context.Context, obj Object) error {
+			return cl.Update(ctx, obj)
+		},
+		Delete: func(ctx context.Context, obj Object) error {
+			return cl.Delete(ctx, obj)
+		},
+	}
+}
+
+func assertConfigMap(t *testing.T, cl client.Client, value string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{}
+	err := cl.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, cm)
+	require.NoError(t, err)
+	assert.Equal(t, value, cm.Data["key"])
+	return cm
+}
+
+func TestLabel(t *testing.T) {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "owner-uid"}}
+
+	t.Run("replaces owner reference with labels", func(t *testing.T) {
+		//given
+		obj := configMap("value")
+		obj.OwnerReferences = []metav1.OwnerReference{{Name: "owner", UID: "owner-uid"}, {Name: "other", UID: "other-uid"}}
+
+		//when
+		changed := Label(obj, owner)
+
+		//then
+		assert.True(t, changed)
+		assert.Equal(t, "owner", obj.Labels[OwnerNameLabel])
+		assert.Equal(t, namespace, obj.Labels[OwnerNamespaceLabel])
+		require.Len(t, obj.OwnerReferences, 1)
+		assert.Equal(t, "other", obj.OwnerReferences[0].Name)
+	})
+
+	t.Run("owned", func(t *testing.T) {
+		controlled := configMap("value")
+		controlled.OwnerReferences = []metav1.OwnerReference{{Name: "owner", UID: "owner-uid", Controller: &[]bool{true}[0]}}
+		labeled := configMap("value")
+		labeled.Labels = OwnerLabels(owner)
+		other := configMap("value")
+		other.Labels = map[string]string{OwnerNameLabel: "other", OwnerNamespaceLabel: namespace}
+
+		assert.True(t, OwnedBy(owner)(controlled))
+		assert.True(t, OwnedBy(owner)(labeled))
+		assert.False(t, OwnedBy(owner)(other))
+		assert.False(t, OwnedBy(owner)(configMap("value")))
+	})
+
+	t.Run("already labeled", func(t *testing.T) {
+		//given
+		obj := configMap("value")
+		obj.Labels = OwnerLabels(owner)
+
+		//when
+		changed := Label(obj, owner)
+
+		//then
+		assert.False(t, changed)
+	})
+}
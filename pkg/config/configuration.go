@@ -1,28 +1,66 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"net/url"
+	"time"
 
 	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
 	errs "github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
-	TCClientID      = "tc.client.id"
-	TCClientSecret  = "tc.client.secret"
-	Name            = "toolchain-enabler"
-	SAName          = "toolchain-sre"
-	OAuthClientName = "codeready-toolchain"
+	TCClientID     = "tc.client.id"
+	TCClientSecret = "tc.client.secret"
+	CABundleKey    = "ca-bundle.crt"
+	Name           = "toolchain-enabler"
+
+	// DefaultSAName is the name of Service Account used by toolchain unless the operator ConfigMap sets another one
+	DefaultSAName = "toolchain-sre"
+	// DefaultOAuthClientName is the name of OAuthClient used by auth service unless the operator ConfigMap sets another
+	// one
+	DefaultOAuthClientName = "codeready-toolchain"
+
+	// DefaultHTTPConnectTimeout is used when neither spec nor the operator ConfigMap set timeout for establishing
+	// connection to auth and cluster service
+	DefaultHTTPConnectTimeout = 10 * time.Second
+	// DefaultHTTPResponseTimeout is used when neither spec nor the operator ConfigMap set timeout for waiting on auth
+	// and cluster service response
+	DefaultHTTPResponseTimeout = 30 * time.Second
+	// DefaultResyncPeriod is used when neither spec nor the operator ConfigMap set period of verification of the
+	// registered cluster
+	DefaultResyncPeriod = 10 * time.Minute
 )
 
 type ToolchainConfig struct {
+	// Owner is namespace and name of the ToolChainEnabler the configuration is created from, connections to auth and
+	// cluster service are kept per owner
+	Owner string
+
 	AuthURL      string
 	ClusterURL   string
 	ClusterName  string
 	ClientID     string
 	ClientSecret string
+
+	HTTPConnectTimeout  time.Duration
+	HTTPResponseTimeout time.Duration
+	ResyncPeriod        time.Duration
+
+	CABundle          []byte
+	ClientCertificate []byte
+	ClientKey         []byte
+	HTTPProxy         string
+	HTTPSProxy        string
+	NoProxy           string
+}
+
+func (c ToolchainConfig) GetOwner() string {
+	return c.Owner
 }
 
 func (c ToolchainConfig) GetClusterServiceURL() string {
@@ -45,7 +83,83 @@ func (c ToolchainConfig) GetClusterName() string {
 	return c.ClusterName
 }
 
-func Create(spec codereadyv1alpha1.ToolChainEnablerSpec, secret *v1.Secret) (tcConfig ToolchainConfig, err error) {
+func (c ToolchainConfig) GetHTTPConnectTimeout() time.Duration {
+	return c.HTTPConnectTimeout
+}
+
+func (c ToolchainConfig) GetHTTPResponseTimeout() time.Duration {
+	return c.HTTPResponseTimeout
+}
+
+func (c ToolchainConfig) GetResyncPeriod() time.Duration {
+	return c.ResyncPeriod
+}
+
+func (c ToolchainConfig) GetCABundle() []byte {
+	return c.CABundle
+}
+
+func (c ToolchainConfig) GetClientCertificate() []byte {
+	return c.ClientCertificate
+}
+
+func (c ToolchainConfig) GetClientKey() []byte {
+	return c.ClientKey
+}
+
+func (c ToolchainConfig) GetHTTPProxy() string {
+	return c.HTTPProxy
+}
+
+func (c ToolchainConfig) GetHTTPSProxy() string {
+	return c.HTTPSProxy
+}
+
+func (c ToolchainConfig) GetNoProxy() string {
+	return c.NoProxy
+}
+
+// Load gets the secrets and the configmap the spec of the given ToolChainEnabler refers to from its namespace and
+// creates toolchain configuration from them, timeouts and resync period which spec doesn't set are taken from the
+// given operator configuration
+func Load(ctx context.Context, cl client.Client, tce *codereadyv1alpha1.ToolChainEnabler, defaults OperatorConfig) (tcConfig ToolchainConfig, err error) {
+	namespace, spec := tce.Namespace, tce.Spec
+	if spec.ToolchainSecretName == "" {
+		return tcConfig, errs.New("'toolchainSecretName' is empty")
+	}
+	secret, err := cl.GetSecret(ctx, namespace, spec.ToolchainSecretName)
+	if err != nil {
+		return tcConfig, errs.Wrapf(err, "failed to get secret '%s'", spec.ToolchainSecretName)
+	}
+
+	var caBundle *v1.ConfigMap
+	if spec.CABundleConfigMap != "" {
+		caBundle, err = cl.GetConfigMap(ctx, namespace, spec.CABundleConfigMap)
+		if err != nil {
+			return tcConfig, errs.Wrapf(err, "failed to get configmap '%s'", spec.CABundleConfigMap)
+		}
+	}
+
+	var clientCert *v1.Secret
+	if spec.ClientCertificateSecret != "" {
+		clientCert, err = cl.GetSecret(ctx, namespace, spec.ClientCertificateSecret)
+		if err != nil {
+			return tcConfig, errs.Wrapf(err, "failed to get secret '%s'", spec.ClientCertificateSecret)
+		}
+	}
+
+	tcConfig, err = Create(spec, secret, caBundle, clientCert, defaults)
+	if err != nil {
+		return tcConfig, err
+	}
+	tcConfig.Owner = namespace + "/" + tce.Name
+	return tcConfig, nil
+}
+
+// Create creates toolchain configuration from the given spec and secrets. caBundle and clientCert are optional and
+// expected to be nil if spec doesn't refer to them. Timeouts and resync period which spec doesn't set are taken from
+// the given operator configuration
+func Create(spec codereadyv1alpha1.ToolChainEnablerSpec, secret *v1.Secret, caBundle *v1.ConfigMap, clientCert *v1.Secret, defaults OperatorConfig) (tcConfig ToolchainConfig, err error) {
 	if err = validateURL(spec.AuthURL, "auth service"); err != nil {
 		return tcConfig, err
 	}
@@ -59,16 +173,81 @@ func Create(spec codereadyv1alpha1.ToolChainEnablerSpec, secret *v1.Secret) (tcC
 		return tcConfig, errs.New(fmt.Sprintf("'%s' is empty in secret '%s'", TCClientSecret, spec.ToolchainSecretName))
 	}
 
+	connectTimeout, err := timeout(spec.HTTPConnectTimeout, defaults.HTTPConnectTimeout, "httpConnectTimeout")
+	if err != nil {
+		return tcConfig, err
+	}
+	responseTimeout, err := timeout(spec.HTTPResponseTimeout, defaults.HTTPResponseTimeout, "httpResponseTimeout")
+	if err != nil {
+		return tcConfig, err
+	}
+	resyncPeriod, err := timeout(spec.ResyncPeriod, defaults.ResyncPeriod, "resyncPeriod")
+	if err != nil {
+		return tcConfig, err
+	}
+
 	tcConfig = ToolchainConfig{
-		AuthURL:      spec.AuthURL,
-		ClusterURL:   spec.ClusterURL,
-		ClusterName:  spec.ClusterName,
-		ClientID:     string(secret.Data[TCClientID]),
-		ClientSecret: string(secret.Data[TCClientSecret]),
+		AuthURL:             spec.AuthURL,
+		ClusterURL:          spec.ClusterURL,
+		ClusterName:         spec.ClusterName,
+		ClientID:            string(secret.Data[TCClientID]),
+		ClientSecret:        string(secret.Data[TCClientSecret]),
+		HTTPConnectTimeout:  connectTimeout,
+		HTTPResponseTimeout: responseTimeout,
+		ResyncPeriod:        resyncPeriod,
+	}
+
+	if caBundle != nil {
+		if caBundle.Data[CABundleKey] == "" {
+			return tcConfig, errs.New(fmt.Sprintf("'%s' is empty in configmap '%s'", CABundleKey, caBundle.Name))
+		}
+		tcConfig.CABundle = []byte(caBundle.Data[CABundleKey])
 	}
+
+	if clientCert != nil {
+		if len(clientCert.Data[v1.TLSCertKey]) <= 0 {
+			return tcConfig, errs.New(fmt.Sprintf("'%s' is empty in secret '%s'", v1.TLSCertKey, clientCert.Name))
+		}
+		if len(clientCert.Data[v1.TLSPrivateKeyKey]) <= 0 {
+			return tcConfig, errs.New(fmt.Sprintf("'%s' is empty in secret '%s'", v1.TLSPrivateKeyKey, clientCert.Name))
+		}
+		tcConfig.ClientCertificate = clientCert.Data[v1.TLSCertKey]
+		tcConfig.ClientKey = clientCert.Data[v1.TLSPrivateKeyKey]
+	}
+
+	if spec.Proxy != nil {
+		if err = validateProxyURL(spec.Proxy.HTTPProxy, "httpProxy"); err != nil {
+			return tcConfig, err
+		}
+		if err = validateProxyURL(spec.Proxy.HTTPSProxy, "httpsProxy"); err != nil {
+			return tcConfig, err
+		}
+		tcConfig.HTTPProxy = spec.Proxy.HTTPProxy
+		tcConfig.HTTPSProxy = spec.Proxy.HTTPSProxy
+		tcConfig.NoProxy = spec.Proxy.NoProxy
+	}
+
 	return tcConfig, nil
 }
 
+func validateProxyURL(proxyURL, field string) error {
+	if proxyURL == "" {
+		return nil
+	}
+	return validateURL(proxyURL, field)
+}
+
+// timeout returns the timeout set in spec or the given default one if it's not set
+func timeout(d *metav1.Duration, defaultTimeout time.Duration, field string) (time.Duration, error) {
+	if d == nil {
+		return defaultTimeout, nil
+	}
+	if d.Duration <= 0 {
+		return 0, errs.New(fmt.Sprintf("'%s' must be greater than zero, got '%s'", field, d.Duration))
+	}
+	return d.Duration, nil
+}
+
 func validateURL(serviceURL, serviceName string) error {
 	if serviceURL == "" {
 		return errs.New(fmt.Sprintf("'%s' url is empty", serviceName))
@@ -1,10 +1,17 @@
 package config
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestValidateURL(t *testing.T) {
@@ -41,3 +48,199 @@ func TestValidateURL(t *testing.T) {
 	})
 
 }
+
+func TestTimeout(t *testing.T) {
+
+	t.Run("default", func(t *testing.T) {
+		d, err := timeout(nil, DefaultHTTPConnectTimeout, "httpConnectTimeout")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultHTTPConnectTimeout, d)
+	})
+
+	t.Run("from spec", func(t *testing.T) {
+		d, err := timeout(&metav1.Duration{Duration: 5 * time.Second}, DefaultHTTPConnectTimeout, "httpConnectTimeout")
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, d)
+	})
+
+	t.Run("not positive", func(t *testing.T) {
+		_, err := timeout(&metav1.Duration{}, DefaultHTTPResponseTimeout, "httpResponseTimeout")
+		require.EqualError(t, err, "'httpResponseTimeout' must be greater than zero, got '0s'")
+	})
+
+}
+
+func TestCreate(t *testing.T) {
+	spec := codereadyv1alpha1.ToolChainEnablerSpec{
+		AuthURL:             "http://auth",
+		ClusterURL:          "http://cluster",
+		ClusterName:         "dsaas-stage",
+		ToolchainSecretName: "toolchain",
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "toolchain"},
+		Data: map[string][]byte{
+			TCClientID:     []byte("id"),
+			TCClientSecret: []byte("secret"),
+		},
+	}
+
+	t.Run("ca bundle and client certificate", func(t *testing.T) {
+		// given
+		caBundle := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "ca"},
+			Data:       map[string]string{CABundleKey: "ca"},
+		}
+		clientCert := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "cert"},
+			Data: map[string][]byte{
+				v1.TLSCertKey:       []byte("cert"),
+				v1.TLSPrivateKeyKey: []byte("key"),
+			},
+		}
+
+		// when
+		c, err := Create(spec, secret, caBundle, clientCert, DefaultOperatorConfig())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []byte("ca"), c.GetCABundle())
+		assert.Equal(t, []byte("cert"), c.GetClientCertificate())
+		assert.Equal(t, []byte("key"), c.GetClientKey())
+	})
+
+	t.Run("empty ca bundle", func(t *testing.T) {
+		// given
+		caBundle := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ca"}}
+
+		// when
+		_, err := Create(spec, secret, caBundle, nil, DefaultOperatorConfig())
+
+		// then
+		require.EqualError(t, err, "'ca-bundle.crt' is empty in configmap 'ca'")
+	})
+
+	t.Run("missing client key", func(t *testing.T) {
+		// given
+		clientCert := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "cert"},
+			Data:       map[string][]byte{v1.TLSCertKey: []byte("cert")},
+		}
+
+		// when
+		_, err := Create(spec, secret, nil, clientCert, DefaultOperatorConfig())
+
+		// then
+		require.EqualError(t, err, "'tls.key' is empty in secret 'cert'")
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		// given
+		s := spec
+		s.Proxy = &codereadyv1alpha1.ProxySpec{HTTPSProxy: "http://proxy:3128", NoProxy: ".svc"}
+
+		// when
+		c, err := Create(s, secret, nil, nil, DefaultOperatorConfig())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "http://proxy:3128", c.GetHTTPSProxy())
+		assert.Equal(t, ".svc", c.GetNoProxy())
+	})
+
+	t.Run("invalid proxy", func(t *testing.T) {
+		// given
+		s := spec
+		s.Proxy = &codereadyv1alpha1.ProxySpec{HTTPProxy: "proxy"}
+
+		// when
+		_, err := Create(s, secret, nil, nil, DefaultOperatorConfig())
+
+		// then
+		require.EqualError(t, err, "invalid url 'proxy' (missing scheme or host?) for: httpProxy")
+	})
+
+	t.Run("resync period", func(t *testing.T) {
+		// given
+		s := spec
+		s.ResyncPeriod = &metav1.Duration{Duration: time.Minute}
+
+		// when
+		c, err := Create(s, secret, nil, nil, DefaultOperatorConfig())
+		def, defErr := Create(spec, secret, nil, nil, DefaultOperatorConfig())
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, defErr)
+		assert.Equal(t, time.Minute, c.GetResyncPeriod())
+		assert.Equal(t, DefaultResyncPeriod, def.GetResyncPeriod())
+	})
+
+	t.Run("defaults of operator", func(t *testing.T) {
+		// given
+		s := spec
+		s.HTTPConnectTimeout = &metav1.Duration{Duration: time.Second}
+		defaults := DefaultOperatorConfig()
+		defaults.HTTPConnectTimeout = 3 * time.Second
+		defaults.ResyncPeriod = time.Hour
+
+		// when
+		c, err := Create(s, secret, nil, nil, defaults)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, c.GetHTTPConnectTimeout())
+		assert.Equal(t, DefaultHTTPResponseTimeout, c.GetHTTPResponseTimeout())
+		assert.Equal(t, time.Hour, c.GetResyncPeriod())
+	})
+}
+
+func TestLoad(t *testing.T) {
+	tce := &codereadyv1alpha1.ToolChainEnabler{
+		ObjectMeta: metav1.ObjectMeta{Name: "toolchain-enabler", Namespace: "toolchain-enabler"},
+		Spec: codereadyv1alpha1.ToolChainEnablerSpec{
+			AuthURL:             "http://auth",
+			ClusterURL:          "http://cluster",
+			ClusterName:         "dsaas-stage",
+			ToolchainSecretName: "toolchain",
+			CABundleConfigMap:   "ca",
+		},
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "toolchain", Namespace: "toolchain-enabler"},
+		Data: map[string][]byte{
+			TCClientID:     []byte("id"),
+			TCClientSecret: []byte("secret"),
+		},
+	}
+
+	t.Run("from secret and configmap", func(t *testing.T) {
+		// given
+		caBundle := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "toolchain-enabler"},
+			Data:       map[string]string{CABundleKey: "ca"},
+		}
+		cl := client.NewClient(fake.NewFakeClient(secret, caBundle))
+
+		// when
+		c, err := Load(context.Background(), cl, tce, DefaultOperatorConfig())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "id", c.GetClientID())
+		assert.Equal(t, []byte("ca"), c.GetCABundle())
+		assert.Equal(t, "toolchain-enabler/toolchain-enabler", c.GetOwner())
+	})
+
+	t.Run("missing configmap", func(t *testing.T) {
+		// given
+		cl := client.NewClient(fake.NewFakeClient(secret))
+
+		// when
+		_, err := Load(context.Background(), cl, tce, DefaultOperatorConfig())
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get configmap 'ca'")
+	})
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	errs "github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	toolscache "k8s.io/client-go/tools/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("config")
+
+// OperatorConfigMapName is the name of the optional ConfigMap configuring the operator, it's read from the namespace
+// the operator watches
+const OperatorConfigMapName = "toolchain-operator-config"
+
+// keys of the operator ConfigMap
+const (
+	RegistrationRetryPeriodKey  = "registration-retry-period"
+	PermissionsRecheckPeriodKey = "permissions-recheck-period"
+	DriftCheckPeriodKey         = "drift-check-period"
+	ReconcileTimeoutKey         = "reconcile-timeout"
+	HTTPConnectTimeoutKey       = "http-connect-timeout"
+	HTTPResponseTimeoutKey      = "http-response-timeout"
+	ResyncPeriodKey             = "resync-period"
+	ServiceAccountNameKey       = "service-account-name"
+	OAuthClientNameKey          = "oauth-client-name"
+	InfraNamespaceKey           = "infra-namespace"
+)
+
+const (
+	// DefaultRegistrationRetryPeriod is the period of registering the cluster again after a call to auth or cluster
+	// service failed
+	DefaultRegistrationRetryPeriod = 5 * time.Second
+	// DefaultPermissionsRecheckPeriod is the period of checking permissions again while some are missing, as granting
+	// them doesn't trigger any event the operator watches
+	DefaultPermissionsRecheckPeriod = 30 * time.Second
+	// DefaultDriftCheckPeriod is the period of checking drift of resources while reconciliation is paused, as changes
+	// of cluster-scoped resources aren't always caught by watches
+	DefaultDriftCheckPeriod = 5 * time.Minute
+	// DefaultReconcileTimeout is the deadline for single reconcile including calls to API server, auth and cluster
+	// service
+	DefaultReconcileTimeout = 2 * time.Minute
+	// DefaultInfraNamespace is the namespace of online-registration service account
+	DefaultInfraNamespace = "openshift-infra"
+)
+
+// OperatorConfig holds tunables of the operator. Names of resources are read only when the operator starts, as renaming
+// them on the fly would leave resources registered in cluster service behind, the rest applies to the next reconcile
+type OperatorConfig struct {
+	RegistrationRetryPeriod  time.Duration `json:"registrationRetryPeriod"`
+	PermissionsRecheckPeriod time.Duration `json:"permissionsRecheckPeriod"`
+	DriftCheckPeriod         time.Duration `json:"driftCheckPeriod"`
+	ReconcileTimeout         time.Duration `json:"reconcileTimeout"`
+
+	// HTTPConnectTimeout, HTTPResponseTimeout and ResyncPeriod are used by ToolChainEnablers which don't set them
+	HTTPConnectTimeout  time.Duration `json:"httpConnectTimeout"`
+	HTTPResponseTimeout time.Duration `json:"httpResponseTimeout"`
+	ResyncPeriod        time.Duration `json:"resyncPeriod"`
+
+	ServiceAccountName string `json:"serviceAccountName"`
+	OAuthClientName    string `json:"oauthClientName"`
+	InfraNamespace     string `json:"infraNamespace"`
+}
+
+// DefaultOperatorConfig returns configuration of the operator used while there is no operator ConfigMap
+func DefaultOperatorConfig() OperatorConfig {
+	return OperatorConfig{
+		RegistrationRetryPeriod:  DefaultRegistrationRetryPeriod,
+		PermissionsRecheckPeriod: DefaultPermissionsRecheckPeriod,
+		DriftCheckPeriod:         DefaultDriftCheckPeriod,
+		ReconcileTimeout:         DefaultReconcileTimeout,
+		HTTPConnectTimeout:       DefaultHTTPConnectTimeout,
+		HTTPResponseTimeout:      DefaultHTTPResponseTimeout,
+		ResyncPeriod:             DefaultResyncPeriod,
+		ServiceAccountName:       DefaultSAName,
+		OAuthClientName:          DefaultOAuthClientName,
+		InfraNamespace:           DefaultInfraNamespace,
+	}
+}
+
+// ParseOperatorConfig creates configuration of the operator from the given ConfigMap, keys it doesn't set keep their
+// defaults. Defaults are returned if the ConfigMap is nil. All the invalid keys are reported at once
+func ParseOperatorConfig(cm *v1.ConfigMap) (OperatorConfig, error) {
+	c := DefaultOperatorConfig()
+	if cm == nil {
+		return c, nil
+	}
+
+	durations := map[string]*time.Duration{
+		RegistrationRetryPeriodKey:  &c.RegistrationRetryPeriod,
+		PermissionsRecheckPeriodKey: &c.PermissionsRecheckPeriod,
+		DriftCheckPeriodKey:         &c.DriftCheckPeriod,
+		ReconcileTimeoutKey:         &c.ReconcileTimeout,
+		HTTPConnectTimeoutKey:       &c.HTTPConnectTimeout,
+		HTTPResponseTimeoutKey:      &c.HTTPResponseTimeout,
+		ResyncPeriodKey:             &c.ResyncPeriod,
+	}
+	names := map[string]*string{
+		ServiceAccountNameKey: &c.ServiceAccountName,
+		OAuthClientNameKey:    &c.OAuthClientName,
+		InfraNamespaceKey:     &c.InfraNamespace,
+	}
+
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		value := strings.TrimSpace(cm.Data[key])
+		if d, found := durations[key]; found {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("'%s' is not a duration: '%s'", key, value))
+				continue
+			}
+			if parsed <= 0 {
+				problems = append(problems, fmt.Sprintf("'%s' must be greater than zero, got '%s'", key, value))
+				continue
+			}
+			*d = parsed
+			continue
+		}
+		if n, found := names[key]; found {
+			if messages := validation.IsDNS1123Label(value); len(messages) > 0 {
+				problems = append(problems, fmt.Sprintf("'%s' is not a valid name: %s", key, strings.Join(messages, ", ")))
+				continue
+			}
+			*n = value
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("unknown key '%s'", key))
+	}
+	if len(problems) > 0 {
+		return DefaultOperatorConfig(), errs.Errorf("invalid configmap '%s': %s", cm.Name, strings.Join(problems, "; "))
+	}
+	return c, nil
+}
+
+// LoadOperatorConfig reads configuration of the operator from the operator ConfigMap in the given namespace, the
+// default configuration is returned if there is no such ConfigMap
+func LoadOperatorConfig(ctx context.Context, cl client.Client, namespace string) (OperatorConfig, error) {
+	cm, err := cl.GetConfigMap(ctx, namespace, OperatorConfigMapName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return DefaultOperatorConfig(), nil
+		}
+		return DefaultOperatorConfig(), errs.Wrapf(err, "failed to get configmap '%s'", OperatorConfigMapName)
+	}
+	return ParseOperatorConfig(cm)
+}
+
+// RestartRequired returns keys of settings which differ from the given configuration and apply only once the operator
+// is restarted, as the cache of infra namespace is bound to it at startup. Other names apply to the next reconcile,
+// which deletes resources of the previous names
+func (c OperatorConfig) RestartRequired(other OperatorConfig) []string {
+	var keys []string
+	if c.InfraNamespace != other.InfraNamespace {
+		keys = append(keys, InfraNamespaceKey)
+	}
+	return keys
+}
+
+// OperatorConfigStore keeps configuration of the operator read from the operator ConfigMap last time
+type OperatorConfigStore struct {
+	namespace string
+	onChange  func(current, previous OperatorConfig)
+	mu        sync.RWMutex
+	current   OperatorConfig
+}
+
+// NewOperatorConfigStore returns store of configuration read from the operator ConfigMap in the given namespace,
+// starting with the given configuration. onChange, if set, is called whenever the configuration changes
+func NewOperatorConfigStore(namespace string, initial OperatorConfig, onChange func(current, previous OperatorConfig)) *OperatorConfigStore {
+	return &OperatorConfigStore{namespace: namespace, onChange: onChange, current: initial}
+}
+
+// StaticOperatorConfig returns store which always returns the given configuration, e.g. in tests
+func StaticOperatorConfig(c OperatorConfig) *OperatorConfigStore {
+	return &OperatorConfigStore{current: c}
+}
+
+// Get returns configuration read last time, or the default configuration if the store is nil
+func (s *OperatorConfigStore) Get() OperatorConfig {
+	if s == nil {
+		return DefaultOperatorConfig()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Update replaces configuration with the one of the given ConfigMap, nil ConfigMap restores the defaults. Invalid
+// ConfigMap is reported and the configuration read last time is kept, so that a typo doesn't reset the operator
+func (s *OperatorConfigStore) Update(cm *v1.ConfigMap) error {
+	c, err := ParseOperatorConfig(cm)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	previous := s.current
+	s.current = c
+	s.mu.Unlock()
+	if c != previous && s.onChange != nil {
+		s.onChange(c, previous)
+	}
+	return nil
+}
+
+// EventHandler returns handler of events of ConfigMaps in the namespace of the store which updates the configuration
+// whenever the operator ConfigMap is created, changed or deleted
+func (s *OperatorConfigStore) EventHandler() toolscache.ResourceEventHandler {
+	update := func(obj interface{}, deleted bool) {
+		cm, ok := obj.(*v1.ConfigMap)
+		if !ok {
+			// the last state of deleted ConfigMap may be unknown
+			if tombstone, isTombstone := obj.(toolscache.DeletedFinalStateUnknown); isTombstone {
+				cm, ok = tombstone.Obj.(*v1.ConfigMap)
+			}
+		}
+		if !ok || cm.Name != OperatorConfigMapName || cm.Namespace != s.namespace {
+			return
+		}
+		if deleted {
+			cm = nil
+		}
+		if err := s.Update(cm); err != nil {
+			log.Error(err, "operator configuration not reloaded, the previous one is kept")
+		}
+	}
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { update(obj, false) },
+		UpdateFunc: func(_, obj interface{}) { update(obj, false) },
+		DeleteFunc: func(obj interface{}) { update(obj, true) },
+	}
+}
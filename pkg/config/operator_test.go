@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func operatorConfigMap(data map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: OperatorConfigMapName, Namespace: "toolchain-enabler"},
+		Data:       data,
+	}
+}
+
+func TestParseOperatorConfig(t *testing.T) {
+
+	t.Run("defaults", func(t *testing.T) {
+		//when
+		c, err := ParseOperatorConfig(nil)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, DefaultOperatorConfig(), c)
+		assert.Equal(t, 5*time.Second, c.RegistrationRetryPeriod)
+		assert.Equal(t, "toolchain-sre", c.ServiceAccountName)
+		assert.Equal(t, "openshift-infra", c.InfraNamespace)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		//given
+		cm := operatorConfigMap(map[string]string{
+			RegistrationRetryPeriodKey: "15s",
+			ResyncPeriodKey:            " 1h ",
+			ServiceAccountNameKey:      "toolchain-sa",
+		})
+
+		//when
+		c, err := ParseOperatorConfig(cm)
+
+		//then
+		require.NoError(t, err)
+		expected := DefaultOperatorConfig()
+		expected.RegistrationRetryPeriod = 15 * time.Second
+		expected.ResyncPeriod = time.Hour
+		expected.ServiceAccountName = "toolchain-sa"
+		assert.Equal(t, expected, c)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		//given
+		cm := operatorConfigMap(map[string]string{
+			ReconcileTimeoutKey:   "0s",
+			DriftCheckPeriodKey:   "5 minutes",
+			InfraNamespaceKey:     "Openshift_Infra",
+			"http-connect-timout": "5s",
+		})
+
+		//when
+		c, err := ParseOperatorConfig(cm)
+
+		//then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid configmap 'toolchain-operator-config': ")
+		assert.Contains(t, err.Error(), "'drift-check-period' is not a duration: '5 minutes'")
+		assert.Contains(t, err.Error(), "'infra-namespace' is not a valid name: ")
+		assert.Contains(t, err.Error(), "unknown key 'http-connect-timout'")
+		assert.Contains(t, err.Error(), "'reconcile-timeout' must be greater than zero, got '0s'")
+		assert.Equal(t, DefaultOperatorConfig(), c)
+	})
+
+	t.Run("restart required", func(t *testing.T) {
+		//given
+		renamed := DefaultOperatorConfig()
+		renamed.OAuthClientName = "toolchain"
+		renamed.InfraNamespace = "infra"
+		renamed.ResyncPeriod = time.Hour
+
+		//then
+		assert.Equal(t, []string{InfraNamespaceKey}, renamed.RestartRequired(DefaultOperatorConfig()))
+		assert.Empty(t, DefaultOperatorConfig().RestartRequired(DefaultOperatorConfig()))
+	})
+}
+
+func TestLoadOperatorConfig(t *testing.T) {
+
+	t.Run("no configmap", func(t *testing.T) {
+		//given
+		cl := client.NewClient(fake.NewFakeClient())
+
+		//when
+		c, err := LoadOperatorConfig(context.Background(), cl, "toolchain-enabler")
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, DefaultOperatorConfig(), c)
+	})
+
+	t.Run("from configmap", func(t *testing.T) {
+		//given
+		cl := client.NewClient(fake.NewFakeClient(operatorConfigMap(map[string]string{PermissionsRecheckPeriodKey: "1m"})))
+
+		//when
+		c, err := LoadOperatorConfig(context.Background(), cl, "toolchain-enabler")
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, c.PermissionsRecheckPeriod)
+	})
+}
+
+func TestOperatorConfigStore(t *testing.T) {
+
+	t.Run("nil", func(t *testing.T) {
+		var s *OperatorConfigStore
+		assert.Equal(t, DefaultOperatorConfig(), s.Get())
+	})
+
+	t.Run("reloaded on events", func(t *testing.T) {
+		//given
+		var changes []OperatorConfig
+		s := NewOperatorConfigStore("toolchain-enabler", DefaultOperatorConfig(), func(current, _ OperatorConfig) {
+			changes = append(changes, current)
+		})
+		h := s.EventHandler()
+
+		//when
+		h.OnAdd(operatorConfigMap(map[string]string{RegistrationRetryPeriodKey: "10s"}))
+
+		//then
+		assert.Equal(t, 10*time.Second, s.Get().RegistrationRetryPeriod)
+		require.Len(t, changes, 1)
+
+		t.Run("invalid kept", func(t *testing.T) {
+			//when
+			h.OnUpdate(nil, operatorConfigMap(map[string]string{RegistrationRetryPeriodKey: "soon"}))
+
+			//then
+			assert.Equal(t, 10*time.Second, s.Get().RegistrationRetryPeriod)
+			assert.Len(t, changes, 1)
+		})
+
+		t.Run("other configmap ignored", func(t *testing.T) {
+			//given
+			other := operatorConfigMap(map[string]string{RegistrationRetryPeriodKey: "1m"})
+			other.Name = "ca"
+
+			//when
+			h.OnUpdate(nil, other)
+
+			//then
+			assert.Equal(t, 10*time.Second, s.Get().RegistrationRetryPeriod)
+		})
+
+		t.Run("name changes applied", func(t *testing.T) {
+			//when
+			err := s.Update(operatorConfigMap(map[string]string{RegistrationRetryPeriodKey: "10s", ServiceAccountNameKey: "toolchain", InfraNamespaceKey: "infra"}))
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, "toolchain", s.Get().ServiceAccountName)
+			assert.Equal(t, "infra", s.Get().InfraNamespace)
+			require.Len(t, changes, 2)
+		})
+
+		t.Run("defaults restored once deleted", func(t *testing.T) {
+			//when
+			h.OnDelete(toolscache.DeletedFinalStateUnknown{Obj: operatorConfigMap(nil)})
+
+			//then
+			assert.Equal(t, DefaultOperatorConfig(), s.Get())
+			assert.Len(t, changes, 3)
+		})
+	})
+}
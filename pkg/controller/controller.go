@@ -1,17 +1,21 @@
 package controller
 
 import (
-	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/election"
+	"github.com/fabric8-services/toolchain-operator/pkg/health"
+	"github.com/fabric8-services/toolchain-operator/pkg/online_registration"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 // AddToManagerFuncs is a list of functions to add all Controllers to the Manager
-var AddToManagerFuncs []func(manager.Manager, cache.Cache) error
+var AddToManagerFuncs []func(manager.Manager, online_registration.InfraCache, *health.Watchdog, *election.Elector, *capabilities.Detector, *config.OperatorConfigStore) error
 
 // AddToManager adds all Controllers to the Manager
-func AddToManager(m manager.Manager, c cache.Cache) error {
+func AddToManager(m manager.Manager, c online_registration.InfraCache, w *health.Watchdog, e *election.Elector, d *capabilities.Detector, o *config.OperatorConfigStore) error {
 	for _, f := range AddToManagerFuncs {
-		if err := f(m, c); err != nil {
+		if err := f(m, c, w, e, d, o); err != nil {
 			return err
 		}
 	}
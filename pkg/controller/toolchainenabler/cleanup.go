@@ -0,0 +1,147 @@
+package toolchainenabler
+
+import (
+	"context"
+	"fmt"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	"github.com/fabric8-services/toolchain-operator/pkg/online_registration"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	errs "github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterResourcesFinalizer holds back deletion of ToolChainEnabler until cluster-scoped resources labeled with it are
+// deleted
+const ClusterResourcesFinalizer = "codeready.openshift.io/cluster-resources"
+
+// clusterResources returns lists of cluster-scoped resources labeled with their owner ToolChainEnabler in the order they
+// are deleted: OAuthClient first, so that no user logs in with the cluster anymore, then ClusterRoleBindings granting
+// roles to toolchain-sre
+func clusterResources() []runtime.Object {
+	return []runtime.Object{
+		&oauthv1.OAuthClientList{},
+		&rbacv1.ClusterRoleBindingList{},
+	}
+}
+
+// deleteClusterResources deletes cluster-scoped resources labeled with the given ToolChainEnabler, except for
+// online-registration resources shared with other ToolChainEnablers, see releaseOnlineRegistration
+func deleteClusterResources(ctx context.Context, cl crclient.Client, tce *codereadyv1alpha1.ToolChainEnabler) error {
+	selector := labels.SelectorFromSet(component.OwnerLabels(tce))
+	for _, list := range clusterResources() {
+		objs, err := listLabeled(ctx, cl, selector, list)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			if online_registration.IsShared(obj) {
+				continue
+			}
+			if err := deleteLabeled(ctx, cl, obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// releaseOnlineRegistration deletes online-registration resources from the given infra namespace unless any
+// ToolChainEnabler other than the given one enables them. The given ToolChainEnabler is nil if it doesn't exist anymore
+func releaseOnlineRegistration(ctx context.Context, cl crclient.Client, tce *codereadyv1alpha1.ToolChainEnabler, infraNamespace string) error {
+	enabled, err := onlineRegistrationEnabled(ctx, cl, tce)
+	if err != nil || enabled {
+		return err
+	}
+	return online_registration.Delete(ctx, client.NewClient(cl), infraNamespace)
+}
+
+// onlineRegistrationEnabled returns true if any ToolChainEnabler other than the given one enables online-registration.
+// ToolChainEnablers being deleted don't count, their finalizer releases online-registration once they're gone
+func onlineRegistrationEnabled(ctx context.Context, cl crclient.Client, except *codereadyv1alpha1.ToolChainEnabler) (bool, error) {
+	tces := &codereadyv1alpha1.ToolChainEnablerList{}
+	if err := cl.List(ctx, &crclient.ListOptions{}, tces); err != nil {
+		return false, errs.Wrapf(err, "failed to list ToolChainEnablers")
+	}
+	for _, tce := range tces.Items {
+		if except != nil && tce.Namespace == except.Namespace && tce.Name == except.Name {
+			continue
+		}
+		if tce.DeletionTimestamp == nil && tce.Spec.OnlineRegistration.IsEnabled() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SweepOrphans deletes cluster-scoped resources labeled with ToolChainEnabler from the given namespace which doesn't
+// exist anymore, e.g. as it was deleted while the operator wasn't running. Online-registration resources are deleted
+// from the given infra namespace only if no remaining ToolChainEnabler enables them
+func SweepOrphans(ctx context.Context, cl crclient.Client, namespace, infraNamespace string) error {
+	selector, err := labels.Parse(fmt.Sprintf("%s,%s=%s", component.OwnerNameLabel, component.OwnerNamespaceLabel, namespace))
+	if err != nil {
+		return err
+	}
+	for _, list := range clusterResources() {
+		objs, err := listLabeled(ctx, cl, selector, list)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return err
+			}
+			owner := types.NamespacedName{
+				Namespace: accessor.GetLabels()[component.OwnerNamespaceLabel],
+				Name:      accessor.GetLabels()[component.OwnerNameLabel],
+			}
+			err = cl.Get(ctx, owner, &codereadyv1alpha1.ToolChainEnabler{})
+			if err == nil {
+				continue
+			}
+			if !errors.IsNotFound(err) {
+				return errs.Wrapf(err, "failed to get ToolChainEnabler %s", owner)
+			}
+			if online_registration.IsShared(obj) {
+				if err := releaseOnlineRegistration(ctx, cl, nil, infraNamespace); err != nil {
+					return err
+				}
+				continue
+			}
+			log.Info("deleting orphaned resource", "type", fmt.Sprintf("%T", obj), "name", accessor.GetName(), "owner", owner.String())
+			if err := deleteLabeled(ctx, cl, obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// listLabeled lists resources of the given list type matching the label selector
+func listLabeled(ctx context.Context, cl crclient.Client, selector labels.Selector, list runtime.Object) ([]runtime.Object, error) {
+	if err := cl.List(ctx, &crclient.ListOptions{LabelSelector: selector}, list); err != nil {
+		if meta.IsNoMatchError(err) {
+			// e.g. OAuthClients on a cluster other than Openshift
+			return nil, nil
+		}
+		return nil, errs.Wrapf(err, "failed to list %T labeled with %s", list, selector)
+	}
+	return meta.ExtractList(list)
+}
+
+func deleteLabeled(ctx context.Context, cl crclient.Client, obj runtime.Object) error {
+	if err := cl.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		accessor, _ := meta.Accessor(obj)
+		return errs.Wrapf(err, "failed to delete %T %s", obj, accessor.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package toolchainenabler
+
+import (
+	"context"
+	"reflect"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	"github.com/fabric8-services/toolchain-operator/pkg/oidc"
+	"github.com/fabric8-services/toolchain-operator/pkg/satoken"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// serviceAccountComponent declares Service Account of the given name used by toolchain to access the cluster in the
+// given namespace
+func serviceAccountComponent(cl client.Client, tce *codereadyv1alpha1.ToolChainEnabler, namespace, saName string, ownership component.Ownership) component.Component {
+	return component.Component{
+		Description: "service account " + saName,
+		Desired: func() (component.Object, error) {
+			return &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      saName,
+					Namespace: namespace,
+				},
+			}, nil
+		},
+		Ownership: ownership,
+		Get: func(ctx context.Context) (component.Object, error) {
+			return cl.GetServiceAccount(ctx, namespace, saName)
+		},
+		Create: func(ctx context.Context, obj component.Object) error {
+			return cl.CreateServiceAccount(ctx, obj.(*corev1.ServiceAccount))
+		},
+		// only adopted service account is updated to set its owner
+		Update: func(ctx context.Context, obj component.Object) error {
+			return cl.Update(ctx, obj)
+		},
+		Delete: func(ctx context.Context, obj component.Object) error {
+			return cl.DeleteServiceAccount(ctx, obj.(*corev1.ServiceAccount))
+		},
+		Owned:    component.OwnedBy(tce),
+		Adoption: adoption(tce),
+	}
+}
+
+// tokenSecretComponent declares Secret holding token of Service Account of the given name used by toolchain. The token
+// is either populated by the API server, or minted via TokenRequest API and renewed once it's due
+func tokenSecretComponent(cl client.Client, tce *codereadyv1alpha1.ToolChainEnabler, namespace, saName string, ownership component.Ownership, tokens *satoken.Provider) component.Component {
+	name := satoken.SecretName(saName)
+	return component.Component{
+		Description: "secret " + name,
+		Desired: func() (component.Object, error) {
+			return tokens.Desired(namespace, saName), nil
+		},
+		Ownership: ownership,
+		Get: func(ctx context.Context) (component.Object, error) {
+			return cl.GetSecret(ctx, namespace, name)
+		},
+		Create: func(ctx context.Context, obj component.Object) error {
+			if tokens.Bounded() {
+				if err := tokens.Mint(obj.(*corev1.Secret), saName); err != nil {
+					return err
+				}
+			}
+			return cl.CreateSecret(ctx, obj.(*corev1.Secret))
+		},
+		// token populated by the API server is never changed
+		Mutate: func(existing, desired component.Object) bool {
+			return tokens.Bounded() && tokens.Due(existing.(*corev1.Secret))
+		},
+		Update: func(ctx context.Context, obj component.Object) error {
+			if err := tokens.Mint(obj.(*corev1.Secret), saName); err != nil {
+				return err
+			}
+			return cl.Update(ctx, obj)
+		},
+		Delete: func(ctx context.Context, obj component.Object) error {
+			return cl.Delete(ctx, obj)
+		},
+		Ready: func(existing component.Object) bool {
+			return satoken.Token(existing.(*corev1.Secret)) != ""
+		},
+		Owned:    component.OwnedBy(tce),
+		Adoption: adoption(tce),
+	}
+}
+
+// toolchainComponents declares resources used by toolchain in the order they are ensured, with Service Account of the
+// given name and its token in the given namespace and resources of OIDC client of the given provisioner
+func toolchainComponents(cl client.Client, tce *codereadyv1alpha1.ToolChainEnabler, namespace, saName string, ownership component.Ownership, tokens *satoken.Provider, oidcClient oidc.Provisioner) []component.Component {
+	components := []component.Component{
+		serviceAccountComponent(cl, tce, namespace, saName, ownership),
+		tokenSecretComponent(cl, tce, namespace, saName, ownership, tokens),
+	}
+	components = append(components, clusterRoleBindingComponents(cl, tce, saName, namespace)...)
+	return append(components, oidcClient.Components()...)
+}
+
+// clusterRoleBindingComponents declares ClusterRoleBindings of cluster roles required by toolchain for Service Account
+func clusterRoleBindingComponents(cl client.Client, tce *codereadyv1alpha1.ToolChainEnabler, saName, namespace string) []component.Component {
+	// currently we have defined ClusterRole dsaas-cluster-admin which needs to be create before running this operator.
+	return []component.Component{
+		clusterRoleBindingComponent(cl, tce, SelfProvisioner, "self-provisioner", saName, namespace),
+		clusterRoleBindingComponent(cl, tce, DsaasClusterAdmin, "dsaas-cluster-admin", saName, namespace),
+	}
+}
+
+// clusterRoleBindingComponent declares ClusterRoleBinding of the given cluster role for Service Account
+func clusterRoleBindingComponent(cl client.Client, tce *codereadyv1alpha1.ToolChainEnabler, name, role, saName, namespace string) component.Component {
+	return component.Component{
+		Description: "clusterrolebinding " + name,
+		Desired: func() (component.Object, error) {
+			return &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+				},
+				Subjects: []rbacv1.Subject{
+					{
+						Kind:      "ServiceAccount",
+						APIGroup:  "",
+						Name:      saName,
+						Namespace: namespace,
+					},
+				},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: "rbac.authorization.k8s.io",
+					Kind:     "ClusterRole",
+					Name:     role,
+				},
+			}, nil
+		},
+		// cluster-scoped resource isn't garbage collected with namespaced owner, so it's deleted by the finalizer
+		Ownership: component.LabeledBy(tce),
+		Get: func(ctx context.Context) (component.Object, error) {
+			return cl.GetClusterRoleBinding(ctx, name)
+		},
+		Create: func(ctx context.Context, obj component.Object) error {
+			return cl.CreateClusterRoleBinding(ctx, obj.(*rbacv1.ClusterRoleBinding))
+		},
+		// role reference can't be changed, so only subjects and owner labels are kept in sync
+		Mutate: func(existing, desired component.Object) bool {
+			e, d := existing.(*rbacv1.ClusterRoleBinding), desired.(*rbacv1.ClusterRoleBinding)
+			labeled := component.Label(e, tce)
+			if reflect.DeepEqual(e.Subjects, d.Subjects) {
+				return labeled
+			}
+			e.Subjects = d.Subjects
+			return true
+		},
+		Update: func(ctx context.Context, obj component.Object) error {
+			return cl.Update(ctx, obj)
+		},
+		Delete: func(ctx context.Context, obj component.Object) error {
+			return cl.DeleteClusterRoleBinding(ctx, obj.(*rbacv1.ClusterRoleBinding))
+		},
+		Owned:    component.OwnedBy(tce),
+		Adoption: adoption(tce),
+	}
+}
+
+// adoption returns how existing resources which aren't owned by the operator are handled, they're ignored by default
+func adoption(tce *codereadyv1alpha1.ToolChainEnabler) component.Adoption {
+	switch tce.Spec.AdoptionPolicy {
+	case codereadyv1alpha1.AdoptionPolicyAdopt:
+		return component.Adopt
+	case codereadyv1alpha1.AdoptionPolicyFail:
+		return component.Fail
+	default:
+		return component.Ignore
+	}
+}
@@ -0,0 +1,47 @@
+package toolchainenabler
+
+import (
+	"context"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	errs "github.com/pkg/errors"
+)
+
+func hasFinalizer(tce *codereadyv1alpha1.ToolChainEnabler, finalizer string) bool {
+	for _, f := range tce.Finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// addFinalizer adds the given finalizer to ToolChainEnabler if it isn't there yet
+func (r ReconcileToolChainEnabler) addFinalizer(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, finalizer string) error {
+	if hasFinalizer(tce, finalizer) {
+		return nil
+	}
+	tce.Finalizers = append(tce.Finalizers, finalizer)
+	if err := r.client.Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to add finalizer %s to %s/%s", finalizer, tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+// removeFinalizer removes the given finalizer from ToolChainEnabler if it's there
+func (r ReconcileToolChainEnabler) removeFinalizer(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, finalizer string) error {
+	if !hasFinalizer(tce, finalizer) {
+		return nil
+	}
+	var finalizers []string
+	for _, f := range tce.Finalizers {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	tce.Finalizers = finalizers
+	if err := r.client.Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to remove finalizer %s from %s/%s", finalizer, tce.Namespace, tce.Name)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package toolchainenabler
+
+import (
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-common/httpsupport"
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/health"
+	errs "github.com/pkg/errors"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterServiceCheck returns check failing if cluster service configured by any ToolChainEnabler in the given
+// namespace isn't reachable, timeouts not set by ToolChainEnablers are taken from the given operator configuration
+func ClusterServiceCheck(cl client.Client, namespace string, operatorConfig *config.OperatorConfigStore, options ...httpsupport.HTTPClientOption) health.Checker {
+	return func(req *http.Request) error {
+		ctx := req.Context()
+		tces := &codereadyv1alpha1.ToolChainEnablerList{}
+		if err := cl.List(ctx, &crclient.ListOptions{Namespace: namespace}, tces); err != nil {
+			return errs.Wrapf(err, "failed to list ToolChainEnablers")
+		}
+
+		for _, tce := range tces.Items {
+			cfg, err := config.Load(ctx, cl, &tce, operatorConfig.Get())
+			if err != nil {
+				return err
+			}
+			if err := cluster.NewClusterService(cfg).Status(ctx, options...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package toolchainenabler
+
+import (
+	"context"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/election"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// referringToolChainEnablers maps configmap or secret to the ToolChainEnablers referring to it in their spec
+func referringToolChainEnablers(cl client.Client) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		tces := &codereadyv1alpha1.ToolChainEnablerList{}
+		if err := cl.List(context.Background(), &client.ListOptions{Namespace: obj.Meta.GetNamespace()}, tces); err != nil {
+			log.Error(err, "failed to list ToolChainEnablers", "namespace", obj.Meta.GetNamespace())
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, tce := range tces.Items {
+			if refersTo(tce.Spec, obj) {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: tce.Namespace, Name: tce.Name},
+				})
+			}
+		}
+		return requests
+	}
+}
+
+func refersTo(spec codereadyv1alpha1.ToolChainEnablerSpec, obj handler.MapObject) bool {
+	name := obj.Meta.GetName()
+	switch obj.Object.(type) {
+	case *corev1.ConfigMap:
+		// names of resources changed in operator configuration apply to the next reconcile
+		return name == spec.CABundleConfigMap || name == config.OperatorConfigMapName
+	case *corev1.Secret:
+		if name == spec.ToolchainSecretName || name == spec.ClientCertificateSecret {
+			return true
+		}
+		if spec.Kubernetes != nil && name == spec.Kubernetes.OIDCClientSecret {
+			return true
+		}
+		for _, m := range spec.Members {
+			if name == m.KubeconfigSecret {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// labeledOwner maps cluster-scoped resource to the ToolChainEnabler it's labeled with
+func labeledOwner() handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		labels := obj.Meta.GetLabels()
+		name, namespace := labels[component.OwnerNameLabel], labels[component.OwnerNamespaceLabel]
+		if name == "" || namespace == "" {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+	}
+}
+
+// allToolChainEnablers maps any object to all the ToolChainEnablers
+func allToolChainEnablers(cl client.Client) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		tces := &codereadyv1alpha1.ToolChainEnablerList{}
+		if err := cl.List(context.Background(), &client.ListOptions{}, tces); err != nil {
+			log.Error(err, "failed to list ToolChainEnablers")
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(tces.Items))
+		for _, tce := range tces.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: tce.Namespace, Name: tce.Name},
+			})
+		}
+		return requests
+	}
+}
+
+// leadershipAcquired is a source enqueueing all the ToolChainEnablers once leadership is acquired and the cache synced,
+// as events received by standby replica are skipped
+func leadershipAcquired(cl client.Client, c cache.Cache, elector *election.Elector) source.Func {
+	return func(_ handler.EventHandler, q workqueue.RateLimitingInterface, _ ...predicate.Predicate) error {
+		go func() {
+			<-elector.Leading()
+			c.WaitForCacheSync(make(chan struct{}))
+			for _, req := range allToolChainEnablers(cl)(handler.MapObject{}) {
+				q.Add(req)
+			}
+		}()
+		return nil
+	}
+}
+
+// specChanged filters out update events which neither change spec or pause annotations of ToolChainEnabler nor start
+// its deletion, e.g. status updates
+func specChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() ||
+				(e.MetaOld.GetDeletionTimestamp() == nil) != (e.MetaNew.GetDeletionTimestamp() == nil) ||
+				pauseChanged(e.MetaOld.GetAnnotations(), e.MetaNew.GetAnnotations())
+		},
+	}
+}
+
+// pauseChanged returns true if pause annotations differ, so that resumed reconciliation starts immediately
+func pauseChanged(before, after map[string]string) bool {
+	return before[PausedAnnotation] != after[PausedAnnotation] || before[PausedUntilAnnotation] != after[PausedUntilAnnotation]
+}
+
+// clusterConfigChanged filters events of cluster configuration objects to the ones named cluster which change the field
+// registered in cluster service, e.g. API server URL of Infrastructure after migration of the cluster
+func clusterConfigChanged() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return e.Meta.GetName() == cluster.ConfigName },
+		DeleteFunc: func(e event.DeleteEvent) bool { return e.Meta.GetName() == cluster.ConfigName },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaNew.GetName() == cluster.ConfigName && registeredField(e.ObjectOld) != registeredField(e.ObjectNew)
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return e.Meta.GetName() == cluster.ConfigName },
+	}
+}
+
+// registeredField returns the field of the given cluster configuration object which is registered in cluster service
+func registeredField(obj runtime.Object) string {
+	switch o := obj.(type) {
+	case *configv1.Infrastructure:
+		return o.Status.APIServerURL
+	case *configv1.Ingress:
+		return o.Spec.Domain
+	}
+	return ""
+}
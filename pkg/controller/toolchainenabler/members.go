@@ -0,0 +1,264 @@
+package toolchainenabler
+
+import (
+	"context"
+	"reflect"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/member"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
+	errs "github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// ReasonConnected is set when API server of member cluster is reachable
+	ReasonConnected = "Connected"
+	// ReasonKubeconfigNotFound is set when the Secret with kubeconfig of member cluster doesn't exist
+	ReasonKubeconfigNotFound = "KubeconfigNotFound"
+	// ReasonConnectionFailed is set when kubeconfig of member cluster is invalid or its API server isn't reachable
+	ReasonConnectionFailed = "ConnectionFailed"
+	// ReasonCleanupFailed is set when member cluster is reachable but resources provisioned on it can't be deleted
+	ReasonCleanupFailed = "CleanupFailed"
+)
+
+// reconcileMembers provisions member clusters of the ToolChainEnabler, registers them in cluster management service
+// and reports their state in status. Members removed from the spec are cleaned up, those which can't be are kept in
+// status until they are. Failure of a member is only reported in its status, so that neither the hub nor other members
+// are held back by it
+func (r ReconcileToolChainEnabler) reconcileMembers(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, cfg config.ToolchainConfig, service clusterManagement) error {
+	var statuses []codereadyv1alpha1.MemberStatus
+	specified := map[string]bool{}
+	for _, spec := range tce.Spec.Members {
+		specified[spec.Name] = true
+		status := codereadyv1alpha1.MemberStatus{Name: spec.Name}
+		if existing := tce.Status.GetMember(spec.Name); existing != nil {
+			status = *existing.DeepCopy()
+		}
+		r.reconcileMember(ctx, tce, spec, &status, cfg, service)
+		statuses = append(statuses, status)
+	}
+	for _, existing := range tce.Status.Members {
+		// nothing has been provisioned on members which have never been connected, and resources of a member renamed
+		// in the spec are those of the member of the new name
+		if specified[existing.Name] || existing.KubeconfigSecret == "" || connectedTo(statuses, existing.APIURL) {
+			continue
+		}
+		status := *existing.DeepCopy()
+		if !r.cleanupMember(ctx, tce, &status, service) {
+			statuses = append(statuses, status)
+		}
+	}
+
+	if reflect.DeepEqual(tce.Status.Members, statuses) {
+		return nil
+	}
+	tce.Status.Members = statuses
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update status of %s/%s", tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+// reconcileMember connects to the member cluster, ensures toolchain-sre service account, its role bindings and
+// OIDC client there and registers the member in cluster management service, setting conditions of the given status
+func (r ReconcileToolChainEnabler) reconcileMember(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, spec codereadyv1alpha1.MemberSpec, status *codereadyv1alpha1.MemberStatus, cfg config.ToolchainConfig, service clusterManagement) {
+	ctx, span := tracing.Start(ctx, "reconcileMember", trace.StringAttribute("member", spec.Name))
+	defer span.End()
+	reqLogger := log.WithValues("member", spec.Name)
+	m, reason, err := r.connectMember(ctx, tce, spec)
+	if err != nil {
+		reqLogger.Error(err, "failed to connect to member cluster")
+		status.SetCondition(conditionFalse(codereadyv1alpha1.MemberConnected, reason, err))
+		return
+	}
+	// owner references don't work across clusters, so resources on members are labeled with their owner
+	namespace := memberNamespace(tce, spec)
+	status.APIURL = m.Host
+	status.KubeconfigSecret = spec.KubeconfigSecret
+	status.Namespace = namespace
+	status.Capabilities = capabilitiesStatus(m.Capabilities)
+	status.SetCondition(conditionTrue(codereadyv1alpha1.MemberConnected, ReasonConnected))
+
+	operatorConfig := r.operatorConfig.Get()
+	oidcClient := oidcProvisioner(m.Client, r.client, tce, m.Capabilities, operatorConfig.OAuthClientName)
+	for _, c := range toolchainComponents(m.Client, tce, namespace, operatorConfig.ServiceAccountName, component.LabeledBy(tce), m.Tokens, oidcClient) {
+		if err := component.Ensure(ctx, c); err != nil {
+			reqLogger.Error(err, "failed to provision member cluster")
+			status.SetCondition(conditionFalse(codereadyv1alpha1.MemberProvisioned, ReasonProvisioningFailed, err))
+			return
+		}
+	}
+	status.SetCondition(conditionTrue(codereadyv1alpha1.MemberProvisioned, ReasonProvisioned))
+
+	// API URL of Kubernetes members is the one they're connected with, their app DNS is set in spec
+	memberPlatform := cluster.Platform{OIDC: oidcClient}
+	if m.Capabilities.Platform() == capabilities.PlatformKubernetes {
+		memberPlatform.APIURL = m.Host
+		memberPlatform.IngressDomain = spec.IngressDomain
+	}
+	data, err := cluster.NewConfigInformer(m.Client, namespace, operatorConfig.ServiceAccountName, spec.Name, m.Capabilities, memberPlatform).Inform(ctx)
+	if err == nil {
+		_, err = r.verifyClusterConfiguration(ctx, service, data)
+	}
+	if err != nil {
+		reqLogger.Error(err, "failed to register member cluster in cluster service", "cluster_service_url", cfg.GetClusterServiceURL())
+		status.SetCondition(conditionFalse(codereadyv1alpha1.ClusterRegistered, ReasonRegistrationFailed, err))
+		return
+	}
+	status.RegisteredAPIURL = data.APIURL
+	status.SetCondition(conditionTrue(codereadyv1alpha1.ClusterRegistered, ReasonRegistered))
+
+	// resources renamed in operator configuration are deleted only once the member is registered with the new ones
+	names := provisionedNames(operatorConfig, false)
+	if status.ProvisionedNames != nil {
+		if err := deleteRenamed(ctx, m.Client, tce, namespace, *status.ProvisionedNames, *names, m.Tokens, m.Capabilities.OAuth); err != nil {
+			reqLogger.Error(err, "failed to delete resources renamed in operator configuration from member cluster")
+			status.SetCondition(conditionFalse(codereadyv1alpha1.MemberProvisioned, ReasonProvisioningFailed, err))
+			return
+		}
+	}
+	status.ProvisionedNames = names
+}
+
+// memberServiceAccountName returns the name of Service Account provisioned on the member of the given status, the one
+// from the given operator configuration if the member doesn't tell
+func memberServiceAccountName(status *codereadyv1alpha1.MemberStatus, operatorConfig config.OperatorConfig) string {
+	if status != nil && status.ProvisionedNames != nil && status.ProvisionedNames.ServiceAccountName != "" {
+		return status.ProvisionedNames.ServiceAccountName
+	}
+	return operatorConfig.ServiceAccountName
+}
+
+// cleanupMember deregisters the member removed from the spec from cluster management service and deletes resources
+// provisioned on it. It returns false and sets CleanedUp condition of the given status if the member can't be cleaned
+// up, it's tried again on the next reconcile
+func (r ReconcileToolChainEnabler) cleanupMember(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, status *codereadyv1alpha1.MemberStatus, service clusterManagement) bool {
+	reqLogger := log.WithValues("member", status.Name)
+	spec := codereadyv1alpha1.MemberSpec{Name: status.Name, KubeconfigSecret: status.KubeconfigSecret, Namespace: status.Namespace}
+	m, reason, err := r.connectMember(ctx, tce, spec)
+	if err == nil && status.RegisteredAPIURL != "" {
+		reason = ReasonRegistrationFailed
+		err = service.DeleteCluster(ctx, status.RegisteredAPIURL)
+	}
+	if err == nil {
+		reason = ReasonCleanupFailed
+		err = deleteMemberCluster(ctx, tce, m, status.Namespace, memberServiceAccountName(status, r.operatorConfig.Get()))
+	}
+	if err != nil {
+		reqLogger.Error(err, "failed to clean up member cluster removed from the spec")
+		status.SetCondition(conditionFalse(codereadyv1alpha1.MemberCleanedUp, reason, err))
+		return false
+	}
+	reqLogger.Info("member cluster removed from the spec cleaned up")
+	return true
+}
+
+// connectMember returns member cluster of the given spec once its API server is reachable, or reason of the failure
+func (r ReconcileToolChainEnabler) connectMember(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, spec codereadyv1alpha1.MemberSpec) (*member.Member, string, error) {
+	secret, err := r.client.GetSecret(ctx, tce.Namespace, spec.KubeconfigSecret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			r.members.Forget(tce.Namespace, spec.KubeconfigSecret)
+			return nil, ReasonKubeconfigNotFound, errs.Errorf("secret %s with kubeconfig doesn't exist", spec.KubeconfigSecret)
+		}
+		return nil, ReasonConnectionFailed, errs.Wrapf(err, "failed to get secret %s", spec.KubeconfigSecret)
+	}
+	m, err := r.members.Get(secret)
+	if err != nil {
+		return nil, ReasonConnectionFailed, err
+	}
+	if m.Ping != nil {
+		if err := m.Ping(); err != nil {
+			return nil, ReasonConnectionFailed, errs.Wrapf(err, "API server %s of member cluster isn't reachable", m.Host)
+		}
+	}
+	return m, "", nil
+}
+
+// deleteMemberResources deletes resources labeled with the ToolChainEnabler from its member clusters, including
+// members removed from the spec which haven't been cleaned up yet. Members which can't be reached are skipped, so that
+// they don't hold back deletion of the ToolChainEnabler forever, the skipped cleanup is reported by CleanedUp condition
+// of the member
+func (r ReconcileToolChainEnabler) deleteMemberResources(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) error {
+	specs := append([]codereadyv1alpha1.MemberSpec(nil), tce.Spec.Members...)
+	for _, status := range tce.Status.Members {
+		if status.KubeconfigSecret != "" && memberSpec(tce, status.Name) == nil {
+			specs = append(specs, codereadyv1alpha1.MemberSpec{Name: status.Name, KubeconfigSecret: status.KubeconfigSecret, Namespace: status.Namespace})
+		}
+	}
+
+	skipped := false
+	for _, spec := range specs {
+		m, reason, err := r.connectMember(ctx, tce, spec)
+		if err != nil {
+			log.Error(err, "resources left on member cluster as it can't be reached", "member", spec.Name)
+			// nothing has been provisioned on members which have never been connected
+			if status := tce.Status.GetMember(spec.Name); status != nil && status.KubeconfigSecret != "" {
+				skipped = status.SetCondition(conditionFalse(codereadyv1alpha1.MemberCleanedUp, reason, err)) || skipped
+			}
+			continue
+		}
+		if err := deleteMemberCluster(ctx, tce, m, memberNamespace(tce, spec), memberServiceAccountName(tce.Status.GetMember(spec.Name), r.operatorConfig.Get())); err != nil {
+			return err
+		}
+	}
+	if !skipped {
+		return nil
+	}
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update status of %s/%s", tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+// deleteMemberCluster deletes resources labeled with the ToolChainEnabler from the given member cluster, with Service
+// Account of the given name from the given namespace
+func deleteMemberCluster(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, m *member.Member, namespace, saName string) error {
+	if err := deleteClusterResources(ctx, m.Client, tce); err != nil {
+		return err
+	}
+	ownership := component.LabeledBy(tce)
+	for _, c := range []component.Component{
+		tokenSecretComponent(m.Client, tce, namespace, saName, ownership, m.Tokens),
+		serviceAccountComponent(m.Client, tce, namespace, saName, ownership),
+	} {
+		if err := component.Delete(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memberSpec returns spec of the member of the given name or nil if the ToolChainEnabler has no such member
+func memberSpec(tce *codereadyv1alpha1.ToolChainEnabler, name string) *codereadyv1alpha1.MemberSpec {
+	for i := range tce.Spec.Members {
+		if tce.Spec.Members[i].Name == name {
+			return &tce.Spec.Members[i]
+		}
+	}
+	return nil
+}
+
+// connectedTo returns true if any of the given members is connected to API server of the given URL
+func connectedTo(statuses []codereadyv1alpha1.MemberStatus, apiURL string) bool {
+	for _, status := range statuses {
+		if status.APIURL == apiURL {
+			return true
+		}
+	}
+	return false
+}
+
+// memberNamespace returns namespace of toolchain-sre service account on the member cluster
+func memberNamespace(tce *codereadyv1alpha1.ToolChainEnabler, spec codereadyv1alpha1.MemberSpec) string {
+	if spec.Namespace != "" {
+		return spec.Namespace
+	}
+	return tce.Namespace
+}
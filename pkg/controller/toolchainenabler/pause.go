@@ -0,0 +1,73 @@
+package toolchainenabler
+
+import (
+	"context"
+	"time"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// PausedAnnotation set to "true" suspends reconciliation of ToolChainEnabler, e.g. while OAuthClient or role
+	// bindings are edited by hand during incident handling. Nothing is changed in the cluster and no call is made to
+	// auth and cluster service, resources which differ from their desired state are only reported in status
+	PausedAnnotation = "codeready.openshift.io/paused"
+	// PausedUntilAnnotation optionally sets time in RFC3339 format when the pause is lifted automatically
+	PausedUntilAnnotation = "codeready.openshift.io/paused-until"
+
+	// ReasonPausedByAnnotation is set when reconciliation is paused by PausedAnnotation
+	ReasonPausedByAnnotation = "PausedByAnnotation"
+)
+
+// pausedUntil returns true if reconciliation of the given ToolChainEnabler is paused at the given time, together with
+// the time when the pause expires, zero if it never does
+func pausedUntil(tce *codereadyv1alpha1.ToolChainEnabler, now time.Time) (bool, time.Time) {
+	if tce.Annotations[PausedAnnotation] != "true" {
+		return false, time.Time{}
+	}
+	value := tce.Annotations[PausedUntilAnnotation]
+	if value == "" {
+		return true, time.Time{}
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// better stay paused than interfere with manual changes
+		log.Error(err, "invalid expiry of the pause, reconciliation stays paused until the annotation is removed", "annotation", PausedUntilAnnotation, "value", value)
+		return true, time.Time{}
+	}
+	return now.Before(until), until
+}
+
+// reconcilePaused reports resources which differ from their desired state without changing anything and requeues the
+// request once the pause expires
+func (r ReconcileToolChainEnabler) reconcilePaused(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, until time.Time) (reconcile.Result, error) {
+	var drift []string
+	operatorConfig := r.operatorConfig.Get()
+	oidcClient := oidcProvisioner(r.client, r.client, tce, r.capabilities.Get(), operatorConfig.OAuthClientName)
+	for _, c := range toolchainComponents(r.client, tce, tce.Namespace, operatorConfig.ServiceAccountName, component.ControlledBy(tce, r.scheme), r.tokens, oidcClient) {
+		diff, err := component.Diff(ctx, c)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if diff != "" {
+			drift = append(drift, diff)
+		}
+	}
+
+	condition := conditionTrue(codereadyv1alpha1.Paused, ReasonPausedByAnnotation)
+	condition.Message = "paused until annotation " + PausedAnnotation + " is removed"
+	// changes of resources which aren't owned aren't watched, so drift is checked periodically
+	requeueAfter := operatorConfig.DriftCheckPeriod
+	if !until.IsZero() {
+		condition.Message = "paused until " + until.Format(time.RFC3339)
+		if remaining := time.Until(until); remaining < requeueAfter {
+			requeueAfter = remaining
+		}
+	}
+	if err := r.updatePauseStatus(ctx, tce, drift, &condition); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
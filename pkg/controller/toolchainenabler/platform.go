@@ -0,0 +1,37 @@
+package toolchainenabler
+
+import (
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/oidc"
+)
+
+// oidcProvisioner returns provisioner of OIDC client of the cluster with the given capabilities, OAuthClient of the
+// given name is created on clusters serving it, otherwise the client is read from the Secret set in spec, which is
+// looked up by the given client of the cluster the operator runs on
+func oidcProvisioner(cl, hub client.Client, tce *codereadyv1alpha1.ToolChainEnabler, caps capabilities.Capabilities, oauthClientName string) oidc.Provisioner {
+	if caps.OAuth {
+		return oidc.NewOAuthClientProvisioner(cl, oauthClientName, tce, adoption(tce))
+	}
+	var name string
+	if tce.Spec.Kubernetes != nil {
+		name = tce.Spec.Kubernetes.OIDCClientSecret
+	}
+	return oidc.NewSecretProvisioner(hub, tce.Namespace, name)
+}
+
+// Platform returns configuration of the cluster the operator runs on which can't be read from OpenShift APIs it doesn't
+// serve, together with provisioner of its OIDC client named by the given operator configuration
+func Platform(cl client.Client, tce *codereadyv1alpha1.ToolChainEnabler, caps capabilities.Capabilities, operatorConfig config.OperatorConfig) cluster.Platform {
+	p := cluster.Platform{OIDC: oidcProvisioner(cl, cl, tce, caps, operatorConfig.OAuthClientName)}
+	if caps.Platform() == capabilities.PlatformKubernetes {
+		if tce.Spec.Kubernetes != nil {
+			p.APIURL = tce.Spec.Kubernetes.APIURL
+			p.IngressDomain = tce.Spec.Kubernetes.IngressDomain
+		}
+	}
+	return p
+}
@@ -0,0 +1,73 @@
+package toolchainenabler
+
+import (
+	"context"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/oidc"
+	"github.com/fabric8-services/toolchain-operator/pkg/online_registration"
+	"github.com/fabric8-services/toolchain-operator/pkg/satoken"
+	errs "github.com/pkg/errors"
+)
+
+// provisionedNames returns names of resources provisioned with the given operator configuration, infra namespace is
+// set only if online-registration is provisioned
+func provisionedNames(c config.OperatorConfig, onlineRegistration bool) *codereadyv1alpha1.ProvisionedNames {
+	names := &codereadyv1alpha1.ProvisionedNames{ServiceAccountName: c.ServiceAccountName, OAuthClientName: c.OAuthClientName}
+	if onlineRegistration {
+		names.InfraNamespace = c.InfraNamespace
+	}
+	return names
+}
+
+// releaseRenamed deletes resources of the ToolChainEnabler provisioned with names which have been changed in operator
+// configuration since, then records the current names in its status. It's called once the cluster is registered with
+// the resources of the current names, so that the registered cluster never refers to deleted ones
+func (r ReconcileToolChainEnabler) releaseRenamed(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) error {
+	previous := tce.Status.ProvisionedNames
+	current := provisionedNames(r.operatorConfig.Get(), tce.Spec.OnlineRegistration.IsEnabled())
+	if previous != nil {
+		if err := deleteRenamed(ctx, r.client, tce, tce.Namespace, *previous, *current, r.tokens, r.capabilities.Get().OAuth); err != nil {
+			return err
+		}
+		// online-registration service account is shared by all the ToolChainEnablers, the first one to see the rename
+		// deletes it, its cluster role binding has been updated to bind the one of the current namespace already
+		if previous.InfraNamespace != "" && previous.InfraNamespace != r.operatorConfig.Get().InfraNamespace {
+			if err := online_registration.DeleteServiceAccount(ctx, client.NewClient(r.directClient()), previous.InfraNamespace); err != nil {
+				return err
+			}
+		}
+		if *previous == *current {
+			return nil
+		}
+	}
+	tce.Status.ProvisionedNames = current
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update status of %s/%s", tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+// deleteRenamed deletes Service Account with its token from the given namespace and OAuthClient, if the cluster serves
+// it, which the ToolChainEnabler owns under the previous names differing from the current ones
+func deleteRenamed(ctx context.Context, cl client.Client, tce *codereadyv1alpha1.ToolChainEnabler, namespace string, previous, current codereadyv1alpha1.ProvisionedNames, tokens *satoken.Provider, oauth bool) error {
+	var components []component.Component
+	if previous.ServiceAccountName != "" && previous.ServiceAccountName != current.ServiceAccountName {
+		// ownership isn't used while deleting
+		components = append(components,
+			tokenSecretComponent(cl, tce, namespace, previous.ServiceAccountName, nil, tokens),
+			serviceAccountComponent(cl, tce, namespace, previous.ServiceAccountName, nil))
+	}
+	if oauth && previous.OAuthClientName != "" && previous.OAuthClientName != current.OAuthClientName {
+		components = append(components, oidc.NewOAuthClientProvisioner(cl, previous.OAuthClientName, tce, adoption(tce)).Components()...)
+	}
+	for _, c := range components {
+		if err := component.DeleteOwned(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
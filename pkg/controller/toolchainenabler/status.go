@@ -0,0 +1,146 @@
+package toolchainenabler
+
+import (
+	"context"
+	"reflect"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/redact"
+	errs "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReasonProvisioned        = "Provisioned"
+	ReasonProvisioningFailed = "ProvisioningFailed"
+	ReasonRegistered         = "Registered"
+	ReasonRegistrationFailed = "RegistrationFailed"
+	ReasonReported           = "Reported"
+	ReasonReportingFailed    = "ReportingFailed"
+	// ReasonInsufficientPermissions holds back registration of the cluster as toolchain-sre lacks required permissions
+	ReasonInsufficientPermissions = "InsufficientPermissions"
+	// ReasonAuthorized is set when the operator has been granted all the permissions it needs
+	ReasonAuthorized = "Authorized"
+	// ReasonMissingPermissions holds back any change as the operator lacks permissions it needs
+	ReasonMissingPermissions = "MissingPermissions"
+	// ReasonResourceNotOwned is set when a resource exists but isn't owned by the operator and adoption policy is Fail
+	ReasonResourceNotOwned = "ResourceNotOwned"
+	// ReasonProjectTemplateConflict is set when another ToolChainEnabler provisions different project request template,
+	// which is shared by the cluster
+	ReasonProjectTemplateConflict = "ProjectTemplateConflict"
+)
+
+func conditionTrue(t codereadyv1alpha1.ConditionType, reason string) codereadyv1alpha1.Condition {
+	return codereadyv1alpha1.Condition{
+		Type:   t,
+		Status: corev1.ConditionTrue,
+		Reason: reason,
+	}
+}
+
+func conditionTrueWithMessage(t codereadyv1alpha1.ConditionType, reason string, err error) codereadyv1alpha1.Condition {
+	c := conditionTrue(t, reason)
+	c.Message = redact.Text(err.Error())
+	return c
+}
+
+// conditionFalse sets message of the given error, scrubbed of secrets echoed e.g. by auth or cluster service
+func conditionFalse(t codereadyv1alpha1.ConditionType, reason string, err error) codereadyv1alpha1.Condition {
+	return codereadyv1alpha1.Condition{
+		Type:    t,
+		Status:  corev1.ConditionFalse,
+		Reason:  reason,
+		Message: redact.Text(err.Error()),
+	}
+}
+
+// updateStatusCondition sets the given conditions and updates status of ToolChainEnabler if anything has been changed
+func (r ReconcileToolChainEnabler) updateStatusCondition(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, conditions ...codereadyv1alpha1.Condition) error {
+	changed := false
+	for _, c := range conditions {
+		if tce.Status.SetCondition(c) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update status of %s/%s", tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+// removeStatusCondition removes the condition of the given type and updates status of ToolChainEnabler if there was such condition
+func (r ReconcileToolChainEnabler) removeStatusCondition(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, t codereadyv1alpha1.ConditionType) error {
+	if !tce.Status.RemoveCondition(t) {
+		return nil
+	}
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update status of %s/%s", tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+// updateVerificationStatus records result of verification of the registered cluster together with the given conditions
+// and updates status of ToolChainEnabler
+func (r ReconcileToolChainEnabler) updateVerificationStatus(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, result codereadyv1alpha1.VerificationResult, message string, conditions ...codereadyv1alpha1.Condition) error {
+	for _, c := range conditions {
+		tce.Status.SetCondition(c)
+	}
+	tce.Status.Verification = &codereadyv1alpha1.VerificationStatus{
+		LastVerificationTime: metav1.Now(),
+		Result:               result,
+		Message:              redact.Text(message),
+	}
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update status of %s/%s", tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+// updatePauseStatus sets Paused condition and drift of resources, or removes both if condition is nil, and updates
+// status of ToolChainEnabler if anything has been changed
+func (r ReconcileToolChainEnabler) updatePauseStatus(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, drift []string, condition *codereadyv1alpha1.Condition) error {
+	changed := !reflect.DeepEqual(tce.Status.Drift, drift)
+	tce.Status.Drift = drift
+	if condition != nil {
+		changed = tce.Status.SetCondition(*condition) || changed
+	} else {
+		changed = tce.Status.RemoveCondition(codereadyv1alpha1.Paused) || changed
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update status of %s/%s", tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+// updateCapabilitiesStatus records the given capabilities of the cluster and updates status of ToolChainEnabler if
+// they've changed
+func (r ReconcileToolChainEnabler) updateCapabilitiesStatus(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, caps capabilities.Capabilities) error {
+	status := capabilitiesStatus(caps)
+	if reflect.DeepEqual(tce.Status.Capabilities, status) {
+		return nil
+	}
+	tce.Status.Capabilities = status
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update status of %s/%s", tce.Namespace, tce.Name)
+	}
+	return nil
+}
+
+func capabilitiesStatus(caps capabilities.Capabilities) *codereadyv1alpha1.CapabilitiesStatus {
+	return &codereadyv1alpha1.CapabilitiesStatus{
+		Config:           caps.Config,
+		Route:            caps.Route,
+		OAuth:            caps.OAuth,
+		TokenRequest:     caps.TokenRequest,
+		OpenShiftVersion: caps.OpenShiftVersion,
+		Platform:         string(caps.Platform()),
+	}
+}
@@ -0,0 +1,89 @@
+package toolchainenabler
+
+import (
+	"context"
+	"fmt"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/project"
+	errs "github.com/pkg/errors"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ProjectTemplateConflictError is returned when project request template of the ToolChainEnabler differs from the one
+// provisioned by another ToolChainEnabler. The template is shared by the cluster, so it's held by the oldest one
+type ProjectTemplateConflictError struct {
+	Holder string
+}
+
+func (e ProjectTemplateConflictError) Error() string {
+	return fmt.Sprintf("project request template differs from the one provisioned by ToolChainEnabler %s", e.Holder)
+}
+
+// IsProjectTemplateConflict returns true if the error is caused by project request template provisioned by another
+// ToolChainEnabler
+func IsProjectTemplateConflict(err error) bool {
+	_, ok := errs.Cause(err).(ProjectTemplateConflictError)
+	return ok
+}
+
+// projectTemplateHolder returns the ToolChainEnabler which provisions project request template, the oldest one setting
+// it which isn't being deleted, other than the excepted one. Nil is returned if there is none
+func projectTemplateHolder(ctx context.Context, cl crclient.Client, except *codereadyv1alpha1.ToolChainEnabler) (*codereadyv1alpha1.ToolChainEnabler, error) {
+	tces := &codereadyv1alpha1.ToolChainEnablerList{}
+	if err := cl.List(ctx, &crclient.ListOptions{}, tces); err != nil {
+		return nil, errs.Wrapf(err, "failed to list ToolChainEnablers")
+	}
+	var holder *codereadyv1alpha1.ToolChainEnabler
+	for i, tce := range tces.Items {
+		if except != nil && tce.Namespace == except.Namespace && tce.Name == except.Name {
+			continue
+		}
+		if tce.DeletionTimestamp != nil || tce.Spec.ProjectTemplate == nil {
+			continue
+		}
+		if holder == nil || older(&tces.Items[i], holder) {
+			holder = &tces.Items[i]
+		}
+	}
+	return holder, nil
+}
+
+// older returns true if the ToolChainEnabler has been created before the other one, ties are broken by namespace and
+// name so that all the reconciles agree on the same one
+func older(tce, other *codereadyv1alpha1.ToolChainEnabler) bool {
+	if !tce.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return tce.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	return tce.Namespace+"/"+tce.Name < other.Namespace+"/"+other.Name
+}
+
+// checkProjectTemplate returns ProjectTemplateConflictError if project request template of the given ToolChainEnabler
+// differs from the one of an older ToolChainEnabler, which holds the template
+func checkProjectTemplate(ctx context.Context, cl crclient.Client, tce *codereadyv1alpha1.ToolChainEnabler) error {
+	holder, err := projectTemplateHolder(ctx, cl, tce)
+	if err != nil {
+		return err
+	}
+	if holder == nil || older(tce, holder) || project.SameTemplate(holder.Spec.ProjectTemplate, tce.Spec.ProjectTemplate) {
+		return nil
+	}
+	return ProjectTemplateConflictError{Holder: holder.Namespace + "/" + holder.Name}
+}
+
+// releaseProjectTemplate removes project request template from the cluster unless another ToolChainEnabler sets it,
+// the template is taken over by that one then
+func (r ReconcileToolChainEnabler) releaseProjectTemplate(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) error {
+	holder, err := projectTemplateHolder(ctx, r.client, tce)
+	if err != nil {
+		return err
+	}
+	if holder == nil {
+		if err := project.Delete(ctx, r.directClient(), r.capabilities.Get()); err != nil {
+			return err
+		}
+	} else {
+		log.Info("project request template is kept for ToolChainEnabler " + holder.Namespace + "/" + holder.Name)
+	}
+	return r.removeFinalizer(ctx, tce, project.Finalizer)
+}
@@ -2,32 +2,42 @@ package toolchainenabler
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"fmt"
 
 	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	configv1 "github.com/openshift/api/config/v1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
 	"github.com/fabric8-services/fabric8-common/httpsupport"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/capacity"
 	"github.com/fabric8-services/toolchain-operator/pkg/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
 	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/election"
+	"github.com/fabric8-services/toolchain-operator/pkg/health"
+	"github.com/fabric8-services/toolchain-operator/pkg/member"
 	"github.com/fabric8-services/toolchain-operator/pkg/online_registration"
-	"github.com/fabric8-services/toolchain-operator/pkg/secret"
+	"github.com/fabric8-services/toolchain-operator/pkg/permissions"
+	"github.com/fabric8-services/toolchain-operator/pkg/project"
+	"github.com/fabric8-services/toolchain-operator/pkg/satoken"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	errs "github.com/pkg/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"go.opencensus.io/trace"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/workqueue"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -47,9 +57,22 @@ const (
 
 // Add creates a new ToolChainEnabler Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
-func Add(mgr manager.Manager, infraCache cache.Cache) error {
+func Add(mgr manager.Manager, infraCache online_registration.InfraCache, watchdog *health.Watchdog, elector *election.Elector, detector *capabilities.Detector, operatorConfig *config.OperatorConfigStore) error {
 
-	reconciler := &ReconcileToolChainEnabler{client: client.NewClient(mgr.GetClient()), scheme: mgr.GetScheme(), cache: infraCache}
+	// pick the way token of toolchain-sre is obtained based on capabilities of the API server. It's picked once, as
+	// well as the watched resources below, the operator exits to be restarted when capabilities driving them change
+	tokens, err := satoken.NewProvider(mgr.GetConfig(), detector.Get())
+	if err != nil {
+		return err
+	}
+
+	// resources outside of the watched namespace aren't cached, so they're read directly from the API server
+	direct, err := crclient.New(mgr.GetConfig(), crclient.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+
+	reconciler := &ReconcileToolChainEnabler{client: client.NewClient(mgr.GetClient()), direct: direct, scheme: mgr.GetScheme(), cache: infraCache, watchdog: watchdog, elector: elector, tokens: tokens, capabilities: detector, operatorConfig: operatorConfig, members: member.NewMembers(mgr.GetScheme()), reviews: permissions.NewReviews()}
 
 	// Create a new controller
 	c, err := controller.New("toolchainenabler-controller", mgr, controller.Options{Reconciler: reconciler})
@@ -57,8 +80,8 @@ func Add(mgr manager.Manager, infraCache cache.Cache) error {
 		return err
 	}
 
-	// Watch for changes to primary resource ToolChainEnabler
-	if err := c.Watch(&source.Kind{Type: &codereadyv1alpha1.ToolChainEnabler{}}, &handler.EnqueueRequestForObject{}); err != nil {
+	// Watch for changes to primary resource ToolChainEnabler, ignoring updates of its status
+	if err := c.Watch(&source.Kind{Type: &codereadyv1alpha1.ToolChainEnabler{}}, &handler.EnqueueRequestForObject{}, specChanged()); err != nil {
 		return err
 	}
 
@@ -72,26 +95,58 @@ func Add(mgr manager.Manager, infraCache cache.Cache) error {
 		return err
 	}
 
-	if err := c.Watch(&source.Kind{Type: &rbacv1.ClusterRoleBinding{}}, enqueueRequestForOwner); err != nil {
+	// cluster-scoped resources refer to their owner by labels
+	enqueueLabeledOwner := &handler.EnqueueRequestsFromMapFunc{ToRequests: labeledOwner()}
+
+	if err := c.Watch(&source.Kind{Type: &rbacv1.ClusterRoleBinding{}}, enqueueLabeledOwner); err != nil {
 		return err
 	}
 
-	if err := c.Watch(&source.Kind{Type: &oauthv1.OAuthClient{}}, enqueueRequestForOwner); err != nil {
+	// OAuthClient is created only on clusters serving it, OIDC client is provisioned elsewhere on plain Kubernetes
+	if detector.Get().OAuth {
+		if err := c.Watch(&source.Kind{Type: &oauthv1.OAuthClient{}}, enqueueLabeledOwner); err != nil {
+			return err
+		}
+	}
+
+	// token secret of toolchain-sre is populated by the API server once created
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, enqueueRequestForOwner); err != nil {
+		return err
+	}
+
+	// Watch for changes to secrets and configmaps referred in spec, so that i.e. rotated CA bundle or client certificate
+	// is picked up without waiting for another event
+	enqueueReferringRequests := &handler.EnqueueRequestsFromMapFunc{ToRequests: referringToolChainEnablers(mgr.GetClient())}
+
+	if err := c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueReferringRequests); err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, enqueueReferringRequests); err != nil {
 		return err
 	}
 
+	// API server URL and ingress domain registered in cluster service change e.g. after migration of the cluster, so
+	// all the ToolChainEnablers are requeued to register them again. Configuration objects exist only on OpenShift 4
+	if detector.Get().Config {
+		enqueueAllRequests := &handler.EnqueueRequestsFromMapFunc{ToRequests: allToolChainEnablers(mgr.GetClient())}
+		for _, obj := range []runtime.Object{&configv1.Infrastructure{}, &configv1.Ingress{}} {
+			if err := c.Watch(&source.Kind{Type: obj}, enqueueAllRequests, clusterConfigChanged()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Watch for changes to online-registration service account in openshift-infra namespace and requeue all the
+	// ToolChainEnablers as each of them reports state of online-registration resources. Events are received only
+	// once the cache is started, i.e. when online-registration is enabled
 	o := &corev1.ServiceAccount{}
 	obj := o.DeepCopyObject()
 	informer, err := infraCache.GetInformer(obj)
 	if err != nil {
 		return fmt.Errorf("failed to get informer for %v: %v", obj, err)
 	}
-	if err := c.Watch(&source.Informer{Informer: informer}, handler.Funcs{
-		CreateFunc:  func(e event.CreateEvent, q workqueue.RateLimitingInterface) { q.Add(reconcileRequest(e.Meta)) },
-		UpdateFunc:  func(e event.UpdateEvent, q workqueue.RateLimitingInterface) { q.Add(reconcileRequest(e.MetaNew)) },
-		DeleteFunc:  func(e event.DeleteEvent, q workqueue.RateLimitingInterface) { q.Add(reconcileRequest(e.Meta)) },
-		GenericFunc: func(e event.GenericEvent, q workqueue.RateLimitingInterface) { q.Add(reconcileRequest(e.Meta)) },
-	}, predicate.Funcs{
+	if err := c.Watch(&source.Informer{Informer: informer}, &handler.EnqueueRequestsFromMapFunc{ToRequests: allToolChainEnablers(mgr.GetClient())}, predicate.Funcs{
 		CreateFunc:  func(e event.CreateEvent) bool { return isOpenshiftInfraServiceAccount(e.Meta.GetName()) },
 		DeleteFunc:  func(e event.DeleteEvent) bool { return isOpenshiftInfraServiceAccount(e.Meta.GetName()) },
 		UpdateFunc:  func(e event.UpdateEvent) bool { return isOpenshiftInfraServiceAccount(e.MetaNew.GetName()) },
@@ -100,6 +155,11 @@ func Add(mgr manager.Manager, infraCache cache.Cache) error {
 		return fmt.Errorf("failed to create watch for %v: %v", obj, err)
 	}
 
+	// Requeue all the ToolChainEnablers once leadership is acquired, as events received by standby replica are skipped
+	if err := c.Watch(leadershipAcquired(mgr.GetClient(), mgr.GetCache(), elector), &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -107,13 +167,6 @@ func isOpenshiftInfraServiceAccount(name string) bool {
 	return name == online_registration.ServiceAccountName
 }
 
-func reconcileRequest(objMeta metav1.Object) reconcile.Request {
-	return reconcile.Request{NamespacedName: types.NamespacedName{
-		Name:      objMeta.GetName(),
-		Namespace: objMeta.GetNamespace(),
-	}}
-}
-
 var _ reconcile.Reconciler = &ReconcileToolChainEnabler{}
 
 // ReconcileToolChainEnabler reconciles a ToolChainEnabler object
@@ -123,277 +176,572 @@ type ReconcileToolChainEnabler struct {
 	client client.Client
 	scheme *runtime.Scheme
 
-	// maintaining secondary cache for openshift-infra namespace to do necessary actions for Service Account
-	cache cache.Cache
+	// direct reads and writes resources outside of the watched namespace, e.g. project request template in
+	// openshift-config namespace, without any cache. The client above is used if nil
+	direct crclient.Client
+
+	// maintaining secondary cache for openshift-infra namespace to do necessary actions for Service Account, it's
+	// started only when online-registration is enabled
+	cache online_registration.InfraCache
+
+	// watchdog tracks running reconciles for liveness probe of the operator
+	watchdog *health.Watchdog
+
+	// elector tells if the operator holds leadership, standby replicas only keep their caches warm. Nil if leader
+	// election is disabled
+	elector *election.Elector
+
+	// tokens provides token of toolchain-sre registered in cluster service, nil falls back to token Secret populated by
+	// the API server
+	tokens *satoken.Provider
+
+	// capabilities are APIs served by the cluster, detected at startup and refreshed periodically. Nil assumes
+	// OpenShift 4
+	capabilities *capabilities.Detector
+
+	// operatorConfig holds tunables of the operator read from the operator ConfigMap, reloaded whenever it changes.
+	// Nil uses the defaults
+	operatorConfig *config.OperatorConfigStore
+
+	// members provides clients of member clusters built from kubeconfig Secrets referred by ToolChainEnablers
+	members *member.Members
+
+	// reviews caches permissions reviewed for each ToolChainEnabler for permissions recheck period. Nil reviews them
+	// on every reconcile
+	reviews *permissions.Reviews
 }
 
 // Reconcile reads that state of the cluster for a ToolChainEnabler object and makes changes based on the state read
 // and what is in the ToolChainEnabler.Spec
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
-func (r *ReconcileToolChainEnabler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileToolChainEnabler) Reconcile(request reconcile.Request) (_ reconcile.Result, err error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	if !r.elector.IsLeader() {
+		reqLogger.Info("Skipping reconcile as leadership hasn't been acquired")
+		return reconcile.Result{}, nil
+	}
 	reqLogger.Info("Reconciling ToolChainEnabler")
+	defer r.watchdog.Start()()
+
+	// configuration reloaded in the middle of reconcile applies to the next one
+	operatorConfig := r.operatorConfig.Get()
+	ctx, cancel := context.WithTimeout(context.Background(), operatorConfig.ReconcileTimeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "Reconcile",
+		trace.StringAttribute("namespace", request.Namespace), trace.StringAttribute("name", request.Name))
+	defer tracing.End(span, &err)
 
 	// Fetch the ToolChainEnabler instance
 	instance := &codereadyv1alpha1.ToolChainEnabler{}
 	namespacedName := request.NamespacedName
 
-	// find toolchainenabler custom resource object for operator ns only and overwrite ns  for cluster scoped resources
-	// like OAuthClient, ClusterRoleBinding as you can't get namespace from it's request event
-	if request.Namespace != online_registration.Namespace {
-		if request.Namespace == "" {
-			log.Info(`couldn't find namespace in the request, getting it from env variable "WATCH_NAMESPACE"`)
-			ns, err := k8sutil.GetWatchNamespace()
-			if err != nil {
-				log.Error(err, "can't reconcile request coming from cluster scoped resources event")
-				return reconcile.Result{}, nil
-			}
-			namespacedName = types.NamespacedName{Namespace: ns, Name: request.Name}
+	// overwrite ns for cluster scoped resources like OAuthClient, ClusterRoleBinding as you can't get namespace from
+	// it's request event
+	if request.Namespace == "" {
+		log.Info(`couldn't find namespace in the request, getting it from env variable "WATCH_NAMESPACE"`)
+		ns, err := k8sutil.GetWatchNamespace()
+		if err != nil {
+			log.Error(err, "can't reconcile request coming from cluster scoped resources event")
+			return reconcile.Result{}, nil
 		}
-		if err := r.client.Get(context.TODO(), namespacedName, instance); err != nil {
-			if errors.IsNotFound(err) {
-				log.Info("Requeueing request doesn't start as couldn't find requested object or stopped as requested object could have been deleted")
-				// Request object not found, could have been deleted after reconcile request.
-				// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
-				// Return and don't requeue
-				return reconcile.Result{}, nil
-			}
-			// Error reading the object - requeue the request.
-			return reconcile.Result{}, err
+		namespacedName = types.NamespacedName{Namespace: ns, Name: request.Name}
+	}
+	if err := r.client.Get(ctx, namespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Requeueing request doesn't start as couldn't find requested object or stopped as requested object could have been deleted")
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Connections to auth and cluster service and permissions reviewed for deleted ToolChainEnabler aren't kept.
+			// Return and don't requeue
+			cluster.EvictHTTPClient(namespacedName.String())
+			r.reviews.Forget(namespacedName.String())
+			return reconcile.Result{}, nil
 		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
 	}
 
-	// create service account online-registration in openshift-infra namespace
-	if err := online_registration.EnsureServiceAccount(r.client, r.cache); err != nil {
+	// nothing is changed while paused, not even the ToolChainEnabler being deleted, drift of resources is only reported
+	if paused, until := pausedUntil(instance, time.Now()); paused {
+		reqLogger.Info("Reconciliation paused, only drift of resources is reported", "until", until)
+		return r.reconcilePaused(ctx, instance, until)
+	}
+	if err := r.updatePauseStatus(ctx, instance, nil, nil); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.updateCapabilitiesStatus(ctx, instance, r.capabilities.Get()); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// create clusterrolebinding online-registration to service account online-registration
-	if err := online_registration.EnsureClusterRoleBinding(r.client); err != nil {
+	// refuse to make any change until the operator is granted all the permissions, rather than leaving things half done
+	if err := r.preflight(ctx, instance); err != nil {
+		reqLogger.Error(err, "no changes will be made until missing permissions are granted to the operator")
+		if err := r.updateStatusCondition(ctx, instance, conditionFalse(codereadyv1alpha1.OperatorAuthorized, ReasonMissingPermissions, err)); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: operatorConfig.PermissionsRecheckPeriod}, nil
+	}
+	if err := r.updateStatusCondition(ctx, instance, conditionTrue(codereadyv1alpha1.OperatorAuthorized, ReasonAuthorized)); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if request.Namespace == online_registration.Namespace {
-		// do not reconcile as online-registration specific logic is already reconciled, it's been called by 'openshift-infra' ns informer
-		return reconcile.Result{}, nil
+	// project request template is shared by the cluster, so it's removed before ToolChainEnabler is deleted
+	if instance.DeletionTimestamp != nil {
+		return reconcile.Result{}, r.finalize(ctx, instance)
 	}
 
-	// Create SA
-	if err := r.ensureSA(instance); err != nil {
+	// cluster-scoped resources aren't garbage collected with ToolChainEnabler, so they're deleted by the finalizer
+	if err := r.addFinalizer(ctx, instance, ClusterResourcesFinalizer); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err := r.ensureClusterRoleBinding(instance, config.SAName, instance.Namespace); err != nil {
+	if err := r.ensureProjectTemplate(ctx, instance); err != nil {
+		if !IsProjectTemplateConflict(err) {
+			return reconcile.Result{}, err
+		}
+		reqLogger.Error(err, "no changes will be made until project request template matches the provisioned one")
+		if err := r.updateStatusCondition(ctx, instance, conditionTrueWithMessage(codereadyv1alpha1.Degraded, ReasonProjectTemplateConflict, err)); err != nil {
+			return reconcile.Result{}, err
+		}
+		// the holder of the template may be changed or deleted, which isn't watched by this ToolChainEnabler
+		return reconcile.Result{RequeueAfter: operatorConfig.PermissionsRecheckPeriod}, nil
+	}
+
+	// create or delete service account and clusterrolebinding online-registration in openshift-infra namespace
+	if err := r.ensureOnlineRegistration(ctx, instance); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err := r.ensureOAuthClient(instance); err != nil {
+	if err := r.ensureToolchainResources(ctx, instance); err != nil {
+		if !component.IsNotOwned(err) {
+			return reconcile.Result{}, err
+		}
+		reqLogger.Error(err, "resource won't be adopted as adoption policy is Fail")
+		if err := r.updateStatusCondition(ctx, instance, conditionTrueWithMessage(codereadyv1alpha1.Degraded, ReasonResourceNotOwned, err)); err != nil {
+			return reconcile.Result{}, err
+		}
+		// resources which aren't owned aren't watched
+		return reconcile.Result{RequeueAfter: operatorConfig.PermissionsRecheckPeriod}, nil
+	}
+	if err := r.removeStatusCondition(ctx, instance, codereadyv1alpha1.Degraded); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	cfg, err := createConfig(r.client, namespacedName.Namespace, instance.Spec)
+	// hold back registration until toolchain-sre is granted everything the toolchain needs
+	if err := r.checkPermissions(ctx, instance); err != nil {
+		log.Error(err, "cluster won't be registered until missing permissions are granted")
+		if err := r.updateStatusCondition(ctx, instance, conditionFalse(codereadyv1alpha1.ClusterRegistered, ReasonInsufficientPermissions, err)); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: operatorConfig.PermissionsRecheckPeriod}, nil
+	}
+
+	cfg, err := config.Load(ctx, r.client, instance, operatorConfig)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	clusterData, err := r.clusterInfo(namespacedName.Namespace, cfg)
+	// a single token of the service account is obtained for all the calls of cluster management service
+	service := cluster.NewClusterService(cfg)
+
+	// members are registered regardless of registration of this cluster, their failures are reported in their status
+	if err := r.reconcileMembers(ctx, instance, cfg, service); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	clusterData, err := r.clusterInfo(ctx, instance, cfg)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err := r.saveClusterConfiguration(clusterData, cfg); err != nil {
-		log.Error(err, "failed to save cluster configuration in cluster service", "cluster_service_url", cfg.GetClusterServiceURL())
-		// requeue after 5 seconds if failed while calling remote cluster service
-		return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+	token, err := r.tokenStatus(ctx, instance.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
-	reqLogger.Info("Skipping reconcile as cluster configuration has been updated to cluster management service successfully")
-	return reconcile.Result{}, nil
-}
+	// cluster service doesn't expose registered token, so renewed bounded token is registered explicitly
+	if tokenRenewed(instance.Status.ServiceAccountToken, token) {
+		reqLogger.Info("Registering renewed token of service account", "sa", operatorConfig.ServiceAccountName, "expiration", token.ExpirationTime)
+		if err := r.saveClusterConfiguration(ctx, service, clusterData); err != nil {
+			log.Error(err, "failed to register renewed token in cluster service", "cluster_service_url", cfg.GetClusterServiceURL())
+			if err := r.updateStatusCondition(ctx, instance, conditionFalse(codereadyv1alpha1.ClusterRegistered, ReasonRegistrationFailed, err)); err != nil {
+				return reconcile.Result{}, err
+			}
+			// requeue after registration retry period if failed while calling remote cluster service
+			return reconcile.Result{RequeueAfter: operatorConfig.RegistrationRetryPeriod}, nil
+		}
+	}
 
-// ensureSA creates Service Account if not exists
-func (r ReconcileToolChainEnabler) ensureSA(tce *codereadyv1alpha1.ToolChainEnabler) error {
-	sa := &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      config.SAName,
-			Namespace: tce.Namespace,
-		},
+	verification, err := r.verifyClusterConfiguration(ctx, service, clusterData)
+	if err != nil {
+		log.Error(err, "failed to verify cluster configuration in cluster service", "cluster_service_url", cfg.GetClusterServiceURL())
+		if err := r.updateVerificationStatus(ctx, instance, codereadyv1alpha1.VerificationFailed, err.Error(), conditionFalse(codereadyv1alpha1.ClusterRegistered, ReasonRegistrationFailed, err)); err != nil {
+			return reconcile.Result{}, err
+		}
+		// requeue after registration retry period if failed while calling remote cluster service
+		return reconcile.Result{RequeueAfter: operatorConfig.RegistrationRetryPeriod}, nil
 	}
 
-	// Set ToolChainEnabler instance as the owner and controller
-	if err := controllerutil.SetControllerReference(tce, sa, r.scheme); err != nil {
-		return err
+	result, message := verificationResult(verification)
+	instance.Status.ServiceAccountToken = token
+	if err := r.updateVerificationStatus(ctx, instance, result, message, conditionTrue(codereadyv1alpha1.ClusterRegistered, ReasonRegistered)); err != nil {
+		return reconcile.Result{}, err
 	}
 
-	if _, err := r.client.GetServiceAccount(tce.Namespace, config.SAName); err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("creating a new service account ", "namespace", sa.Namespace, "name", sa.Name)
-			if err := r.client.CreateServiceAccount(sa); err != nil {
-				return err
-			}
+	reqLogger.Info("Cluster configuration verified in cluster management service", "result", result, "resync_period", cfg.GetResyncPeriod())
 
-			log.Info(fmt.Sprintf("service account %s created successfully", config.SAName))
-			return nil
-		}
-		return errs.Wrapf(err, "failed to get service account %s", config.SAName)
+	// resources renamed in operator configuration are deleted only once the cluster is registered with the new ones
+	if err := r.releaseRenamed(ctx, instance); err != nil {
+		return reconcile.Result{}, err
 	}
-	log.Info(fmt.Sprintf("service account %s already exists", config.SAName))
 
-	return nil
+	if err := r.reportCapacity(ctx, instance, clusterData.APIURL, service); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter(cfg.GetResyncPeriod(), token)}, nil
 }
 
-// ensureClusterRoleBinding ensures ClusterRoleBinding for Service Account with required roles
-func (r *ReconcileToolChainEnabler) ensureClusterRoleBinding(tce *codereadyv1alpha1.ToolChainEnabler, saName, namespace string) error {
-	if err := r.bindSelfProvisionerRole(tce, saName, namespace); err != nil {
-		return err
+// tokenRenewed returns true if bounded token differs from the one registered in cluster service
+func tokenRenewed(registered, token *codereadyv1alpha1.TokenStatus) bool {
+	if token.Mechanism != codereadyv1alpha1.TokenMechanismTokenRequest || token.IssueTime == nil {
+		return false
 	}
+	return registered == nil || registered.IssueTime == nil || !registered.IssueTime.Equal(token.IssueTime)
+}
 
-	return r.bindDsaasClusterAdminRole(tce, saName, namespace)
+// requeueAfter returns the resync period, or shorter duration if bounded token should be renewed earlier
+func requeueAfter(resyncPeriod time.Duration, token *codereadyv1alpha1.TokenStatus) time.Duration {
+	if token.IssueTime == nil || token.ExpirationTime == nil {
+		return resyncPeriod
+	}
+	renewIn := time.Until(satoken.RenewTimeOf(token.IssueTime.Time, token.ExpirationTime.Time))
+	if renewIn < resyncPeriod {
+		if renewIn < 0 {
+			return time.Second
+		}
+		return renewIn
+	}
+	return resyncPeriod
 }
 
-// bindSelfProvisionerRole creates ClusterRoleBinding for Service Account with self-provisioner cluster role
-func (r *ReconcileToolChainEnabler) bindSelfProvisionerRole(tce *codereadyv1alpha1.ToolChainEnabler, saName, namespace string) error {
-	crb := &rbacv1.ClusterRoleBinding{
-		Subjects: []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				APIGroup:  "",
-				Name:      saName,
-				Namespace: namespace,
-			},
-		},
-		RoleRef: rbacv1.RoleRef{
-			APIGroup: "rbac.authorization.k8s.io",
-			Kind:     "ClusterRole",
-			Name:     "self-provisioner",
-		},
-	}
-
-	crb.SetName(SelfProvisioner)
-
-	// Set ToolChainEnabler instance as the owner and controller
-	if err := controllerutil.SetControllerReference(tce, crb, r.scheme); err != nil {
+// verificationResult describes the given verification of the registered cluster
+func verificationResult(v cluster.Verification) (codereadyv1alpha1.VerificationResult, string) {
+	switch {
+	case !v.Reregistered:
+		return codereadyv1alpha1.VerificationMatched, ""
+	case !v.Found:
+		return codereadyv1alpha1.VerificationReregistered, "cluster wasn't registered in cluster management service"
+	default:
+		return codereadyv1alpha1.VerificationReregistered, fmt.Sprintf("registered cluster didn't match: %s", strings.Join(v.Mismatches, ", "))
+	}
+}
+
+// ensureOnlineRegistration creates online-registration resources if any ToolChainEnabler enables them, or deletes them
+// otherwise. The state of the resources is reported in status of the given ToolChainEnabler if it enables them
+func (r ReconcileToolChainEnabler) ensureOnlineRegistration(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) (err error) {
+	ctx, span := tracing.Start(ctx, "ensureOnlineRegistration")
+	defer tracing.End(span, &err)
+	enabled, err := onlineRegistrationEnabled(ctx, r.client, nil)
+	if err != nil {
 		return err
 	}
-	if _, err := r.client.GetClusterRoleBinding(SelfProvisioner); err != nil {
-		if errors.IsNotFound(err) {
-			log.Info(`adding "self-provisioner" cluster role to `, "Service Account", saName)
-			if err := r.client.CreateClusterRoleBinding(crb); err != nil {
+	infraNamespace := r.operatorConfig.Get().InfraNamespace
+	if !enabled {
+		// the cache of openshift-infra namespace isn't started while online-registration is disabled
+		if err := online_registration.Delete(ctx, client.NewClient(r.directClient()), infraNamespace); err != nil {
+			return err
+		}
+		return r.removeStatusCondition(ctx, tce, codereadyv1alpha1.OnlineRegistrationReady)
+	}
+	if !tce.Spec.OnlineRegistration.IsEnabled() {
+		// resources are kept for other ToolChainEnabler
+		return r.removeStatusCondition(ctx, tce, codereadyv1alpha1.OnlineRegistrationReady)
+	}
+
+	err = r.cache.EnsureStarted(ctx)
+	if err == nil {
+		err = online_registration.EnsureServiceAccount(ctx, r.client, r.cache, infraNamespace, tce)
+	}
+	if err == nil {
+		err = online_registration.EnsureClusterRoleBinding(ctx, r.client, infraNamespace, tce)
+	}
+	if err != nil {
+		if statusErr := r.updateStatusCondition(ctx, tce, conditionFalse(codereadyv1alpha1.OnlineRegistrationReady, ReasonProvisioningFailed, err)); statusErr != nil {
+			log.Error(statusErr, "failed to report online-registration state")
+		}
+		return err
+	}
+	return r.updateStatusCondition(ctx, tce, conditionTrue(codereadyv1alpha1.OnlineRegistrationReady, ReasonProvisioned))
+}
+
+// ensureProjectTemplate renders project request template from the spec and sets it in project configuration of the
+// cluster, or removes it if it's not configured. The finalizer is kept as long as the template exists.
+// ProjectTemplateConflictError is returned if an older ToolChainEnabler provisions different template
+func (r ReconcileToolChainEnabler) ensureProjectTemplate(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) (err error) {
+	ctx, span := tracing.Start(ctx, "ensureProjectTemplate")
+	defer tracing.End(span, &err)
+	if tce.Spec.ProjectTemplate == nil {
+		if hasFinalizer(tce, project.Finalizer) {
+			if err := r.releaseProjectTemplate(ctx, tce); err != nil {
 				return err
 			}
+		}
+		return r.removeStatusCondition(ctx, tce, codereadyv1alpha1.ProjectTemplateReady)
+	}
 
-			log.Info(fmt.Sprintf("clusterrolebinding %s created successfully", SelfProvisioner))
-			return nil
+	// the template shared by the cluster isn't overwritten by a different one, nor removed once this one is deleted
+	if err := checkProjectTemplate(ctx, r.client, tce); err != nil {
+		if statusErr := r.updateStatusCondition(ctx, tce, conditionFalse(codereadyv1alpha1.ProjectTemplateReady, ReasonProjectTemplateConflict, err)); statusErr != nil {
+			log.Error(statusErr, "failed to report project request template state")
 		}
-		return errs.Wrapf(err, "failed to get clusterrolebinding %s", SelfProvisioner)
+		return err
+	}
+	if err := r.addFinalizer(ctx, tce, project.Finalizer); err != nil {
+		return err
+	}
+	if err := project.Ensure(ctx, r.directClient(), tce.Spec.ProjectTemplate, r.capabilities.Get()); err != nil {
+		if statusErr := r.updateStatusCondition(ctx, tce, conditionFalse(codereadyv1alpha1.ProjectTemplateReady, ReasonProvisioningFailed, err)); statusErr != nil {
+			log.Error(statusErr, "failed to report project request template state")
+		}
+		return err
+	}
+	return r.updateStatusCondition(ctx, tce, conditionTrue(codereadyv1alpha1.ProjectTemplateReady, ReasonProvisioned))
+}
+
+// reportCapacity computes capacity of the cluster used by pods and users and marks the cluster as exhausted in cluster
+// management service when it crosses a threshold of the spec. The mark is removed once capacity is no longer reported.
+// Failures are reported in CapacityReported condition rather than returned, the capacity is reported again on the next
+// resync. Status is updated only when the capacity changes
+func (r ReconcileToolChainEnabler) reportCapacity(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, apiURL string, service clusterManagement) error {
+	if tce.Spec.Capacity == nil {
+		if tce.Status.Capacity == nil {
+			return r.removeStatusCondition(ctx, tce, codereadyv1alpha1.CapacityReported)
+		}
+		if tce.Status.Capacity.Exhausted {
+			if _, err := service.UpdateCapacityExhausted(ctx, apiURL, false); err != nil {
+				log.Error(err, "failed to clear capacity exhausted flag in cluster management service")
+				return r.updateStatusCondition(ctx, tce, conditionFalse(codereadyv1alpha1.CapacityReported, ReasonReportingFailed, err))
+			}
+		}
+		tce.Status.Capacity = nil
+		tce.Status.RemoveCondition(codereadyv1alpha1.CapacityReported)
+		if err := r.client.Status().Update(ctx, tce); err != nil {
+			return errs.Wrapf(err, "failed to clear capacity status of %s/%s", tce.Namespace, tce.Name)
+		}
+		return nil
 	}
 
-	log.Info(fmt.Sprintf("clusterrolebinding %s already exists", SelfProvisioner))
+	usage, err := capacity.Compute(ctx, r.directClient())
+	if err != nil {
+		log.Error(err, "failed to compute cluster capacity")
+		return r.updateStatusCondition(ctx, tce, conditionFalse(codereadyv1alpha1.CapacityReported, ReasonReportingFailed, err))
+	}
+	status := capacity.Status(usage, *tce.Spec.Capacity)
+	changed, err := service.UpdateCapacityExhausted(ctx, apiURL, status.Exhausted)
+	if err != nil {
+		log.Error(err, "failed to report cluster capacity to cluster management service")
+		return r.updateStatusCondition(ctx, tce, conditionFalse(codereadyv1alpha1.CapacityReported, ReasonReportingFailed, err))
+	}
+	if changed {
+		log.Info("Capacity exhausted flag updated in cluster management service", "exhausted", status.Exhausted, "reasons", status.Reasons)
+	}
 
+	updated := tce.Status.SetCondition(conditionTrue(codereadyv1alpha1.CapacityReported, ReasonReported))
+	if capacity.Changed(tce.Status.Capacity, status) {
+		tce.Status.Capacity = status
+		updated = true
+	}
+	if !updated {
+		return nil
+	}
+	if err := r.client.Status().Update(ctx, tce); err != nil {
+		return errs.Wrapf(err, "failed to update capacity status of %s/%s", tce.Namespace, tce.Name)
+	}
 	return nil
 }
 
-// bindDsaasClusterAdminRole creates ClusterRoleBinding for Service Account with dsaas-cluster-admin cluster role
-func (r *ReconcileToolChainEnabler) bindDsaasClusterAdminRole(tce *codereadyv1alpha1.ToolChainEnabler, saName, namespace string) error {
-	// currently we have defined ClusterRole dsaas-cluster-admin which needs to be create before running this operator.
-	crb := &rbacv1.ClusterRoleBinding{
-		Subjects: []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				APIGroup:  "",
-				Name:      saName,
-				Namespace: namespace,
-			},
-		},
-		RoleRef: rbacv1.RoleRef{
-			APIGroup: "rbac.authorization.k8s.io",
-			Kind:     "ClusterRole",
-			Name:     "dsaas-cluster-admin",
-		},
-	}
-
-	crb.SetName(DsaasClusterAdmin)
-
-	// Set ToolChainEnabler instance as the owner and controller
-	if err := controllerutil.SetControllerReference(tce, crb, r.scheme); err != nil {
+// finalize removes resources shared by the cluster before the given ToolChainEnabler is deleted
+func (r ReconcileToolChainEnabler) finalize(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) error {
+	if hasFinalizer(tce, ClusterResourcesFinalizer) {
+		if err := releaseOnlineRegistration(ctx, r.directClient(), tce, r.operatorConfig.Get().InfraNamespace); err != nil {
+			return err
+		}
+		if err := deleteClusterResources(ctx, r.directClient(), tce); err != nil {
+			return err
+		}
+		if err := r.deleteMemberResources(ctx, tce); err != nil {
+			return err
+		}
+		if err := r.removeFinalizer(ctx, tce, ClusterResourcesFinalizer); err != nil {
+			return err
+		}
+	}
+	if !hasFinalizer(tce, project.Finalizer) {
+		return nil
+	}
+	return r.releaseProjectTemplate(ctx, tce)
+}
+
+func (r ReconcileToolChainEnabler) directClient() crclient.Client {
+	if r.direct == nil {
+		return r.client
+	}
+	return r.direct
+}
+
+// ensureToolchainResources ensures Service Account, its token, ClusterRoleBindings and OIDC client used by toolchain.
+// NotOwnedError is returned if any of them exists, isn't owned by the operator and adoption policy is Fail
+func (r ReconcileToolChainEnabler) ensureToolchainResources(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) (err error) {
+	ctx, span := tracing.Start(ctx, "ensureToolchainResources")
+	defer tracing.End(span, &err)
+	if err := r.ensureSA(ctx, tce); err != nil {
 		return err
 	}
-	if _, err := r.client.GetClusterRoleBinding(DsaasClusterAdmin); err != nil {
-		if errors.IsNotFound(err) {
-			log.Info(`adding "dsaas-cluster-admin" cluster role to `, "Service Account", saName)
-			if err := r.client.CreateClusterRoleBinding(crb); err != nil {
-				return err
-			}
+	if err := r.ensureSAToken(ctx, tce); err != nil {
+		return err
+	}
+	if err := r.ensureClusterRoleBinding(ctx, tce, r.operatorConfig.Get().ServiceAccountName, tce.Namespace); err != nil {
+		return err
+	}
+	return r.ensureOIDCClient(ctx, tce)
+}
 
-			log.Info(fmt.Sprintf("clusterrolebinding %s created successfully", DsaasClusterAdmin))
-			return nil
-		}
-		return errs.Wrapf(err, "failed to get clusterrolebinding %s", DsaasClusterAdmin)
+// ensureSA creates Service Account if not exists
+func (r ReconcileToolChainEnabler) ensureSA(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) (err error) {
+	ctx, span := tracing.Start(ctx, "ensureSA")
+	defer tracing.End(span, &err)
+	return component.Ensure(ctx, serviceAccountComponent(r.client, tce, tce.Namespace, r.operatorConfig.Get().ServiceAccountName, component.ControlledBy(tce, r.scheme)))
+}
+
+// ensureSAToken ensures Secret holding token of Service Account, renewing bounded token once it's due
+func (r ReconcileToolChainEnabler) ensureSAToken(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) (err error) {
+	ctx, span := tracing.Start(ctx, "ensureSAToken")
+	defer tracing.End(span, &err)
+	return component.Ensure(ctx, tokenSecretComponent(r.client, tce, tce.Namespace, r.operatorConfig.Get().ServiceAccountName, component.ControlledBy(tce, r.scheme), r.tokens))
+}
+
+// tokenStatus describes token of Service Account stored in the token Secret
+func (r ReconcileToolChainEnabler) tokenStatus(ctx context.Context, namespace string) (*codereadyv1alpha1.TokenStatus, error) {
+	status := &codereadyv1alpha1.TokenStatus{Mechanism: codereadyv1alpha1.TokenMechanismSecret}
+	if !r.tokens.Bounded() {
+		return status, nil
 	}
+	status.Mechanism = codereadyv1alpha1.TokenMechanismTokenRequest
 
-	log.Info(fmt.Sprintf("clusterrolebinding %s already exists", DsaasClusterAdmin))
+	name := satoken.SecretName(r.operatorConfig.Get().ServiceAccountName)
+	secret, err := r.client.GetSecret(ctx, namespace, name)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to get secret %s", name)
+	}
+	if issued := satoken.IssueTime(secret); issued != nil {
+		t := metav1.NewTime(*issued)
+		status.IssueTime = &t
+	}
+	if expiration := satoken.ExpirationTime(secret); expiration != nil {
+		t := metav1.NewTime(*expiration)
+		status.ExpirationTime = &t
+	}
+	return status, nil
+}
 
+// ensureClusterRoleBinding ensures ClusterRoleBinding for Service Account with required roles
+func (r *ReconcileToolChainEnabler) ensureClusterRoleBinding(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, saName, namespace string) (err error) {
+	ctx, span := tracing.Start(ctx, "ensureClusterRoleBinding", trace.StringAttribute("namespace", namespace))
+	defer tracing.End(span, &err)
+	for _, crb := range clusterRoleBindingComponents(r.client, tce, saName, namespace) {
+		if err := component.Ensure(ctx, crb); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// ensureOAuthClient creates OAuthClient if not exists
-func (r ReconcileToolChainEnabler) ensureOAuthClient(tce *codereadyv1alpha1.ToolChainEnabler) error {
-	randomString, err := secret.CreateRandomString(256)
+// checkPermissions returns an error listing permissions required by the toolchain which aren't granted to toolchain-sre
+func (r ReconcileToolChainEnabler) checkPermissions(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) error {
+	operatorConfig := r.operatorConfig.Get()
+	saName := operatorConfig.ServiceAccountName
+	user, groups := permissions.ServiceAccountUser(tce.Namespace, saName)
+	missing, err := r.reviews.Missing(ctx, r.client, tce.Namespace+"/"+tce.Name, operatorConfig.PermissionsRecheckPeriod, user, groups, permissions.RequiredOn(r.capabilities.Get().Platform()))
 	if err != nil {
-		return errs.Wrapf(err, "failed to generate random string to be used as secret for oauthclient")
+		return err
 	}
-	var ageSeconds int32
-	oc := &oauthv1.OAuthClient{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: config.OAuthClientName,
-		},
-		Secret:                   randomString,
-		GrantMethod:              oauthv1.GrantHandlerAuto,
-		RedirectURIs:             []string{"https://auth.openshift.io/"},
-		AccessTokenMaxAgeSeconds: &ageSeconds,
+	if len(missing) == 0 {
+		return nil
 	}
 
-	// Set ToolChainEnabler instance as the owner and controller
-	if err := controllerutil.SetControllerReference(tce, oc, r.scheme); err != nil {
+	return errs.Errorf("service account %s is missing permissions: %s", saName, permissions.Join(missing))
+}
+
+// preflight returns an error listing all the permissions the operator needs to reconcile the given ToolChainEnabler
+// which aren't granted to it
+func (r ReconcileToolChainEnabler) preflight(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) error {
+	operatorConfig := r.operatorConfig.Get()
+	required := permissions.Operator(tce.Namespace, operatorConfig.InfraNamespace, r.capabilities.Get().Platform(), Features(tce)...)
+	missing, err := r.reviews.MissingForSelf(ctx, r.client, tce.Namespace+"/"+tce.Name, operatorConfig.PermissionsRecheckPeriod, required)
+	if err != nil {
 		return err
 	}
+	if len(missing) == 0 {
+		return nil
+	}
 
-	if _, err = r.client.GetOAuthClient(config.OAuthClientName); err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("creating", "oauthclient", config.OAuthClientName)
-			if err := r.client.CreateOAuthClient(oc); err != nil {
-				return err
-			}
+	return errs.Errorf("operator is missing permissions: %s", permissions.Join(missing))
+}
 
-			log.Info(fmt.Sprintf("oauth client %s created successfully", config.OAuthClientName))
-			return nil
-		}
-		return errs.Wrapf(err, "failed to get oauthclient %s", config.OAuthClientName)
+// Features returns optional features enabled by the given ToolChainEnabler, including those which still need to be
+// cleaned up
+func Features(tce *codereadyv1alpha1.ToolChainEnabler) []permissions.Feature {
+	var features []permissions.Feature
+	if tce.Spec.OnlineRegistration.IsEnabled() {
+		features = append(features, permissions.OnlineRegistration)
 	}
+	if tce.Spec.ProjectTemplate != nil || hasFinalizer(tce, project.Finalizer) {
+		features = append(features, permissions.ProjectTemplate)
+	}
+	if tce.Spec.Capacity != nil {
+		features = append(features, permissions.Capacity)
+	}
+	if tce.Spec.AdoptionPolicy == codereadyv1alpha1.AdoptionPolicyAdopt {
+		features = append(features, permissions.Adoption)
+	}
+	return features
+}
 
-	log.Info(fmt.Sprintf("oauth client %s already exists", config.OAuthClientName))
-
+// ensureOIDCClient ensures resources of OIDC client, i.e. OAuthClient on OpenShift, none if the client is provisioned
+// outside of the cluster
+func (r ReconcileToolChainEnabler) ensureOIDCClient(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler) (err error) {
+	ctx, span := tracing.Start(ctx, "ensureOIDCClient")
+	defer tracing.End(span, &err)
+	for _, c := range oidcProvisioner(r.client, r.client, tce, r.capabilities.Get(), r.operatorConfig.Get().OAuthClientName).Components() {
+		if err := component.Ensure(ctx, c); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (r ReconcileToolChainEnabler) clusterInfo(ns string, cfg config.ToolchainConfig, options ...cluster.SASecretOption) (*clusterclient.CreateClusterData, error) {
-	i := cluster.NewConfigInformer(r.client, ns, cfg.GetClusterName())
-	return i.Inform(options...)
+func (r ReconcileToolChainEnabler) clusterInfo(ctx context.Context, tce *codereadyv1alpha1.ToolChainEnabler, cfg config.ToolchainConfig, options ...cluster.SASecretOption) (*clusterclient.CreateClusterData, error) {
+	caps := r.capabilities.Get()
+	operatorConfig := r.operatorConfig.Get()
+	i := cluster.NewConfigInformer(r.client, tce.Namespace, operatorConfig.ServiceAccountName, cfg.GetClusterName(), caps, Platform(r.client, tce, caps, operatorConfig))
+	return i.Inform(ctx, options...)
 }
 
-func (r ReconcileToolChainEnabler) saveClusterConfiguration(data *clusterclient.CreateClusterData, cfg config.ToolchainConfig, options ...httpsupport.HTTPClientOption) error {
-	service := cluster.NewClusterService(cfg)
-	return service.CreateCluster(context.Background(), data, options...)
+// clusterManagement is the cluster management service called by a single reconcile
+type clusterManagement interface {
+	CreateCluster(ctx context.Context, data *clusterclient.CreateClusterData, options ...httpsupport.HTTPClientOption) error
+	VerifyCluster(ctx context.Context, data *clusterclient.CreateClusterData, options ...httpsupport.HTTPClientOption) (cluster.Verification, error)
+	UpdateCapacityExhausted(ctx context.Context, apiURL string, exhausted bool, options ...httpsupport.HTTPClientOption) (bool, error)
+	DeleteCluster(ctx context.Context, apiURL string, options ...httpsupport.HTTPClientOption) error
 }
 
-func createConfig(client client.Client, namespaceName string, spec codereadyv1alpha1.ToolChainEnablerSpec) (tcConfig config.ToolchainConfig, err error) {
-	if spec.ToolchainSecretName == "" {
-		return tcConfig, errs.New(fmt.Sprintf("'%s' is empty", TCSecretName))
-	}
-	secret, err := client.GetSecret(namespaceName, spec.ToolchainSecretName)
-	if err != nil {
-		return tcConfig, errs.Wrapf(err, "failed to get secret '%s'", spec.ToolchainSecretName)
-	}
-	return config.Create(spec, secret)
+// verifyClusterConfiguration verifies cluster registered in cluster service and registers it again if it's missing or
+// doesn't match
+func (r ReconcileToolChainEnabler) verifyClusterConfiguration(ctx context.Context, service clusterManagement, data *clusterclient.CreateClusterData, options ...httpsupport.HTTPClientOption) (cluster.Verification, error) {
+	return service.VerifyCluster(ctx, data, options...)
+}
+
+func (r ReconcileToolChainEnabler) saveClusterConfiguration(ctx context.Context, service clusterManagement, data *clusterclient.CreateClusterData, options ...httpsupport.HTTPClientOption) error {
+	return service.CreateCluster(ctx, data, options...)
 }
@@ -1,31 +1,53 @@
 package toolchainenabler
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 
 	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
 	"github.com/fabric8-services/fabric8-common/httpsupport"
 	"github.com/fabric8-services/toolchain-operator/pkg/apis"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/capacity"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
 	. "github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/pkg/election"
+	"github.com/fabric8-services/toolchain-operator/pkg/member"
 	"github.com/fabric8-services/toolchain-operator/pkg/online_registration"
+	"github.com/fabric8-services/toolchain-operator/pkg/permissions"
+	"github.com/fabric8-services/toolchain-operator/pkg/project"
+	"github.com/fabric8-services/toolchain-operator/pkg/satoken"
+	"github.com/fabric8-services/toolchain-operator/pkg/tracing"
 	. "github.com/fabric8-services/toolchain-operator/test"
+	configv1 "github.com/openshift/api/config/v1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
+	templatev1 "github.com/openshift/api/template/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	gock "gopkg.in/h2non/gock.v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
@@ -46,6 +68,7 @@ func TestToolChainEnablerController(t *testing.T) {
 			Name:      Name,
 			Namespace: Namespace,
 		},
+		// online-registration is enabled unless disabled explicitly
 		Spec: codereadyv1alpha1.ToolChainEnablerSpec{},
 	}
 	// Objects to track in the fake client.
@@ -55,27 +78,654 @@ func TestToolChainEnablerController(t *testing.T) {
 
 	// Register operator types with the runtime scheme.
 	s := scheme.Scheme
-	s.AddKnownTypes(codereadyv1alpha1.SchemeGroupVersion, tce)
+	s.AddKnownTypes(codereadyv1alpha1.SchemeGroupVersion, tce, &codereadyv1alpha1.ToolChainEnablerList{})
 
 	cache := NewFakeCache(nil)
 
 	t.Run("Reconcile", func(t *testing.T) {
 		t.Run("without registering openshift specific resources", func(t *testing.T) {
 			//given
-			// Create a fake client to mock API calls.
+			// Create a fake client to mock API calls, granting all the permissions reviewed by the operator.
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(objs...)), map[string]string{})
+
+			// Create a ReconcileToolChainEnabler object with the scheme and fake client.
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache}
+
+			req := newReconcileRequest(Name)
+
+			//when
+			_, err := r.Reconcile(req)
+
+			//then
+			_, oautherr := cl.GetOAuthClient(context.Background(), DefaultOAuthClientName)
+			assert.EqualError(t, err, fmt.Sprintf("failed to get oauthclient %s: %s", DefaultOAuthClientName, oautherr))
+
+			// online-registration resources are reported in status
+			instance := &codereadyv1alpha1.ToolChainEnabler{}
+			err = cl.Get(context.Background(), req.NamespacedName, instance)
+			require.NoError(t, err)
+			assert.True(t, instance.Status.IsConditionTrue(codereadyv1alpha1.OnlineRegistrationReady))
+		})
+
+		t.Run("missing operator permissions", func(t *testing.T) {
+			//given
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(objs...)), map[string]string{"denied": "routes"})
+
+			// Create a ReconcileToolChainEnabler object with the scheme and fake client.
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache}
+
+			req := newReconcileRequest(Name)
+
+			//when
+			res, err := r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, reconcile.Result{RequeueAfter: DefaultPermissionsRecheckPeriod}, res)
+
+			// no partial changes are made
+			_, err = cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
+			assert.True(t, errors.IsNotFound(err), "sa %s created without required permissions", DefaultSAName)
+
+			instance := &codereadyv1alpha1.ToolChainEnabler{}
+			err = cl.Get(context.Background(), req.NamespacedName, instance)
+			require.NoError(t, err)
+			condition := instance.Status.GetCondition(codereadyv1alpha1.OperatorAuthorized)
+			require.NotNil(t, condition)
+			assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			assert.Equal(t, ReasonMissingPermissions, condition.Reason)
+			assert.Equal(t, "operator is missing permissions: create routes.route.openshift.io in codeready-toolchain, delete routes.route.openshift.io in codeready-toolchain", condition.Message)
+		})
+
+		t.Run("operator permissions reviewed once per recheck period", func(t *testing.T) {
+			//given
+			denied := map[string]string{"denied": "routes"}
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(objs...)), denied)
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache, reviews: permissions.NewReviews()}
+			req := newReconcileRequest(Name)
+			_, err := r.Reconcile(req)
+			require.NoError(t, err)
+
+			//when
+			delete(denied, "denied")
+			res, err := r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, reconcile.Result{RequeueAfter: DefaultPermissionsRecheckPeriod}, res, "permissions reviewed again within recheck period")
+
+			//when
+			r.reviews.Forget(req.NamespacedName.String())
+			r.Reconcile(req)
+
+			//then
+			instance := &codereadyv1alpha1.ToolChainEnabler{}
+			err = cl.Get(context.Background(), req.NamespacedName, instance)
+			require.NoError(t, err)
+			condition := instance.Status.GetCondition(codereadyv1alpha1.OperatorAuthorized)
+			require.NotNil(t, condition)
+			assert.Equal(t, corev1.ConditionTrue, condition.Status)
+		})
+
+		t.Run("skipped on standby replica", func(t *testing.T) {
+			//given
 			cl := client.NewClient(fake.NewFakeClient(objs...))
+			elector, err := election.New(&rest.Config{Host: "http://localhost"}, election.Config{
+				LockName:      "toolchain-enabler-lock",
+				Namespace:     Namespace,
+				Identity:      "standby",
+				LeaseDuration: election.DefaultLeaseDuration,
+				RenewDeadline: election.DefaultRenewDeadline,
+				RetryPeriod:   election.DefaultRetryPeriod,
+			})
+			require.NoError(t, err)
+
+			// Create a ReconcileToolChainEnabler object with the scheme and fake client.
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache, elector: elector}
+
+			//when
+			res, err := r.Reconcile(newReconcileRequest(Name))
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, reconcile.Result{}, res)
+			_, err = cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
+			assert.True(t, errors.IsNotFound(err), "sa %s created on standby replica", DefaultSAName)
+		})
+
+		t.Run("online-registration failure reported in status", func(t *testing.T) {
+			//given
+			errMsg := "something went wrong while getting clusterrolebinding"
+			m := make(map[string]string)
+			m["crb"] = errMsg
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(objs...)), m)
+
+			// Create a ReconcileToolChainEnabler object with the scheme and fake client.
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache}
+
+			req := newReconcileRequest(Name)
+
+			//when
+			_, err := r.Reconcile(req)
+
+			//then
+			require.Error(t, err)
+			instance := &codereadyv1alpha1.ToolChainEnabler{}
+			err = cl.Get(context.Background(), req.NamespacedName, instance)
+			require.NoError(t, err)
+			c := instance.Status.GetCondition(codereadyv1alpha1.OnlineRegistrationReady)
+			require.NotNil(t, c)
+			assert.Equal(t, corev1.ConditionFalse, c.Status)
+			assert.Equal(t, ReasonProvisioningFailed, c.Reason)
+			assert.Contains(t, c.Message, errMsg)
+		})
+
+		t.Run("online-registration disabled", func(t *testing.T) {
+			//given
+			disabled := tce.DeepCopy()
+			enabled := false
+			disabled.Spec.OnlineRegistration.Enabled = &enabled
+			disabled.Status.SetCondition(conditionTrue(codereadyv1alpha1.OnlineRegistrationReady, ReasonProvisioned))
+			sa := &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      online_registration.ServiceAccountName,
+					Namespace: DefaultInfraNamespace,
+					Labels:    component.OwnerLabels(disabled),
+				},
+			}
+			crb := &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   online_registration.ClusterRoleBindingName,
+					Labels: component.OwnerLabels(disabled),
+				},
+			}
+			cl := client.NewClient(fake.NewFakeClient(disabled, sa, crb))
 
 			// Create a ReconcileToolChainEnabler object with the scheme and fake client.
 			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache}
 
 			req := newReconcileRequest(Name)
 
+			//when
+			err := r.ensureOnlineRegistration(context.Background(), disabled)
+
+			//then
+			require.NoError(t, err)
+			_, err = cl.GetServiceAccount(context.Background(), DefaultInfraNamespace, online_registration.ServiceAccountName)
+			assert.True(t, errors.IsNotFound(err), "online-registration sa not deleted")
+			_, err = cl.GetClusterRoleBinding(context.Background(), online_registration.ClusterRoleBindingName)
+			assert.True(t, errors.IsNotFound(err), "online-registration clusterrolebinding not deleted")
+
+			instance := &codereadyv1alpha1.ToolChainEnabler{}
+			err = cl.Get(context.Background(), req.NamespacedName, instance)
+			require.NoError(t, err)
+			assert.Nil(t, instance.Status.GetCondition(codereadyv1alpha1.OnlineRegistrationReady))
+		})
+
+		t.Run("cluster registered in fake cluster service", func(t *testing.T) {
+			//given
+			require.NoError(t, apis.AddToScheme(s))
+			auth := NewFakeAuthService("bb6d043d-f243-458f-8498-2c18a12dcf47", "secret")
+			defer auth.Close()
+			clusterService := NewFakeClusterService(auth)
+			defer clusterService.Close()
+
+			registering := tce.DeepCopy()
+			registering.Spec = codereadyv1alpha1.ToolChainEnablerSpec{
+				AuthURL:             auth.URL,
+				ClusterURL:          clusterService.URL,
+				ClusterName:         "dsaas-stage",
+				ToolchainSecretName: "toolchain",
+			}
+			toolchainSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "toolchain", Namespace: Namespace},
+				Data: map[string][]byte{
+					TCClientID:     []byte("bb6d043d-f243-458f-8498-2c18a12dcf47"),
+					TCClientSecret: []byte("secret"),
+				},
+			}
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(registering, toolchainSecret)), map[string]string{})
+			tokens := &satoken.Provider{
+				Mechanism:  codereadyv1alpha1.TokenMechanismTokenRequest,
+				Expiration: time.Hour,
+				Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+					return "mysatoken", time.Now().Add(expiration), nil
+				},
+			}
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache, tokens: tokens, capabilities: capabilities.Static(capabilities.OpenShift4)}
+			req := newReconcileRequest(Name)
+			apiURL := "https://api.dsaas-stage.openshift.com/"
+
 			//when
 			_, err := r.Reconcile(req)
 
 			//then
-			_, oautherr := cl.GetOAuthClient(OAuthClientName)
-			assert.EqualError(t, err, fmt.Sprintf("failed to get oauthclient %s: %s", OAuthClientName, oautherr))
+			require.NoError(t, err)
+			registered := clusterService.AssertRegistered(t, apiURL)
+			assert.Equal(t, "mysatoken", registered.ServiceAccountToken)
+			assert.Equal(t, "system:serviceaccount:codeready-toolchain:toolchain-sre", registered.ServiceAccountUsername)
+			assert.Equal(t, DefaultOAuthClientName, registered.AuthClientID)
+			instance := getToolChainEnabler(t, cl)
+			assert.True(t, instance.Status.IsConditionTrue(codereadyv1alpha1.ClusterRegistered))
+			assert.Equal(t, &codereadyv1alpha1.CapabilitiesStatus{Config: true, Route: true, OAuth: true, TokenRequest: true, OpenShiftVersion: "4", Platform: "OpenShift"}, instance.Status.Capabilities)
+
+			//when cluster service fails
+			clusterService.Fail(http.MethodGet, "/api/clusters/auth", http.StatusInternalServerError, 1)
+			res, err := r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, reconcile.Result{RequeueAfter: DefaultRegistrationRetryPeriod}, res)
+			instance = getToolChainEnabler(t, cl)
+			assert.Equal(t, codereadyv1alpha1.VerificationFailed, instance.Status.Verification.Result)
+
+			//when retry period is reloaded from operator configmap
+			r.operatorConfig = NewOperatorConfigStore(Namespace, DefaultOperatorConfig(), nil)
+			err = r.operatorConfig.Update(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: OperatorConfigMapName, Namespace: Namespace},
+				Data:       map[string]string{RegistrationRetryPeriodKey: "1m"},
+			})
+			require.NoError(t, err)
+			clusterService.Fail(http.MethodGet, "/api/clusters/auth", http.StatusInternalServerError, 1)
+			res, err = r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, reconcile.Result{RequeueAfter: time.Minute}, res)
+
+			//when cluster is removed from cluster service
+			clusterService.Unregister(apiURL)
+			_, err = r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			clusterService.AssertRegistered(t, apiURL)
+			instance = getToolChainEnabler(t, cl)
+			assert.Equal(t, codereadyv1alpha1.VerificationReregistered, instance.Status.Verification.Result)
+			clusterService.AssertRequests(t, http.MethodPost, "/api/clusters", 2)
+		})
+
+		t.Run("reconcile traced", func(t *testing.T) {
+			//given
+			require.NoError(t, apis.AddToScheme(s))
+			auth := NewFakeAuthService("bb6d043d-f243-458f-8498-2c18a12dcf47", "secret")
+			defer auth.Close()
+			clusterService := NewFakeClusterService(auth)
+			defer clusterService.Close()
+
+			registering := tce.DeepCopy()
+			registering.Spec = codereadyv1alpha1.ToolChainEnablerSpec{
+				AuthURL:             auth.URL,
+				ClusterURL:          clusterService.URL,
+				ClusterName:         "dsaas-stage",
+				ToolchainSecretName: "toolchain",
+			}
+			toolchainSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "toolchain", Namespace: Namespace},
+				Data: map[string][]byte{
+					TCClientID:     []byte("bb6d043d-f243-458f-8498-2c18a12dcf47"),
+					TCClientSecret: []byte("secret"),
+				},
+			}
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(registering, toolchainSecret)), map[string]string{})
+			tokens := &satoken.Provider{
+				Mechanism:  codereadyv1alpha1.TokenMechanismTokenRequest,
+				Expiration: time.Hour,
+				Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+					return "mysatoken", time.Now().Add(expiration), nil
+				},
+			}
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache, tokens: tokens, capabilities: capabilities.Static(capabilities.OpenShift4)}
+			out := &bytes.Buffer{}
+			stop, err := tracing.Setup(tracing.Config{Exporter: tracing.ExporterStdout, Output: out})
+			require.NoError(t, err)
+
+			//when
+			_, err = r.Reconcile(newReconcileRequest(Name))
+			stop()
+
+			//then
+			require.NoError(t, err)
+			spans := map[string]map[string]interface{}{}
+			decoder := json.NewDecoder(out)
+			for decoder.More() {
+				span := map[string]interface{}{}
+				require.NoError(t, decoder.Decode(&span))
+				spans[span["name"].(string)] = span
+			}
+			root := spans["Reconcile"]
+			require.NotNil(t, root)
+			assert.Nil(t, root["parentSpanId"])
+			assert.Equal(t, map[string]interface{}{"namespace": Namespace, "name": Name}, root["attributes"])
+			for _, name := range []string{"ensureOnlineRegistration", "ensureToolchainResources", "ensureSA", "ensureSAToken",
+				"ensureClusterRoleBinding", "ensureOIDCClient", "buildClusterConfiguration", "appDNS", "serviceAccount",
+				"ServiceAccountToken", "ShowCluster", "CreateClusters", "HTTP POST"} {
+				require.Contains(t, spans, name)
+				assert.Equal(t, root["traceId"], spans[name]["traceId"], "span %s isn't part of the reconcile trace", name)
+			}
+			assert.Equal(t, root["spanId"], spans["ensureToolchainResources"]["parentSpanId"])
+			assert.Equal(t, spans["ensureToolchainResources"]["spanId"], spans["ensureSA"]["parentSpanId"])
+			assert.Equal(t, spans["buildClusterConfiguration"]["spanId"], spans["appDNS"]["parentSpanId"])
+		})
+
+		t.Run("capacity reported to fake cluster service", func(t *testing.T) {
+			//given
+			require.NoError(t, apis.AddToScheme(s))
+			auth := NewFakeAuthService("bb6d043d-f243-458f-8498-2c18a12dcf47", "secret")
+			defer auth.Close()
+			clusterService := NewFakeClusterService(auth)
+			defer clusterService.Close()
+
+			reporting := tce.DeepCopy()
+			reporting.Spec = codereadyv1alpha1.ToolChainEnablerSpec{
+				AuthURL:             auth.URL,
+				ClusterURL:          clusterService.URL,
+				ClusterName:         "dsaas-stage",
+				ToolchainSecretName: "toolchain",
+				Capacity:            &codereadyv1alpha1.CapacitySpec{MaxUserProjects: 1},
+			}
+			toolchainSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "toolchain", Namespace: Namespace},
+				Data: map[string][]byte{
+					TCClientID:     []byte("bb6d043d-f243-458f-8498-2c18a12dcf47"),
+					TCClientSecret: []byte("secret"),
+				},
+			}
+			userProject := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "john-dev", Annotations: map[string]string{capacity.RequesterAnnotation: "john"}}}
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(reporting, toolchainSecret, userProject)), map[string]string{})
+			tokens := &satoken.Provider{
+				Mechanism:  codereadyv1alpha1.TokenMechanismTokenRequest,
+				Expiration: time.Hour,
+				Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+					return "mysatoken", time.Now().Add(expiration), nil
+				},
+			}
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache, tokens: tokens, capabilities: capabilities.Static(capabilities.OpenShift4)}
+			req := newReconcileRequest(Name)
+			apiURL := "https://api.dsaas-stage.openshift.com/"
+
+			//when
+			_, err := r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.True(t, clusterService.AssertRegistered(t, apiURL).CapacityExhausted)
+			// registration and capacity share a single token of the service account
+			assert.Equal(t, 1, auth.Issued())
+			instance := getToolChainEnabler(t, cl)
+			assert.True(t, instance.Status.IsConditionTrue(codereadyv1alpha1.CapacityReported))
+			require.NotNil(t, instance.Status.Capacity)
+			assert.True(t, instance.Status.Capacity.Exhausted)
+
+			//when capacity doesn't change
+			lastUpdate := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			instance.Status.Capacity.LastUpdateTime = lastUpdate
+			require.NoError(t, cl.Status().Update(context.Background(), instance))
+			_, err = r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			instance = getToolChainEnabler(t, cl)
+			assert.True(t, lastUpdate.Equal(&instance.Status.Capacity.LastUpdateTime), "capacity status updated although it hasn't changed")
+
+			//when cluster service fails to update the capacity
+			instance.Spec.Capacity.MaxUserProjects = 2
+			require.NoError(t, cl.Update(context.Background(), instance))
+			clusterService.Fail(http.MethodPatch, "/api/clusters", http.StatusInternalServerError, 1)
+			res, err := r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.NotZero(t, res.RequeueAfter)
+			assert.True(t, clusterService.AssertRegistered(t, apiURL).CapacityExhausted)
+			instance = getToolChainEnabler(t, cl)
+			condition := instance.Status.GetCondition(codereadyv1alpha1.CapacityReported)
+			require.NotNil(t, condition)
+			assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			assert.Equal(t, ReasonReportingFailed, condition.Reason)
+			assert.True(t, instance.Status.Capacity.Exhausted)
+
+			//when cluster service recovers
+			_, err = r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.False(t, clusterService.AssertRegistered(t, apiURL).CapacityExhausted)
+			instance = getToolChainEnabler(t, cl)
+			assert.True(t, instance.Status.IsConditionTrue(codereadyv1alpha1.CapacityReported))
+			assert.False(t, instance.Status.Capacity.Exhausted)
+		})
+
+		t.Run("member clusters registered in fake cluster service", func(t *testing.T) {
+			//given
+			require.NoError(t, apis.AddToScheme(s))
+			auth := NewFakeAuthService("bb6d043d-f243-458f-8498-2c18a12dcf47", "secret")
+			defer auth.Close()
+			clusterService := NewFakeClusterService(auth)
+			defer clusterService.Close()
+
+			hub := tce.DeepCopy()
+			hub.Spec = codereadyv1alpha1.ToolChainEnablerSpec{
+				AuthURL:             auth.URL,
+				ClusterURL:          clusterService.URL,
+				ClusterName:         "dsaas-stage",
+				ToolchainSecretName: "toolchain",
+				Members: []codereadyv1alpha1.MemberSpec{
+					{Name: "member-1", KubeconfigSecret: "member-1-kubeconfig", Namespace: "toolchain"},
+					{Name: "member-2", KubeconfigSecret: "member-2-kubeconfig"},
+				},
+			}
+			toolchainSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "toolchain", Namespace: Namespace},
+				Data: map[string][]byte{
+					TCClientID:     []byte("bb6d043d-f243-458f-8498-2c18a12dcf47"),
+					TCClientSecret: []byte("secret"),
+				},
+			}
+			kubeconfig := memberKubeconfig("member-1", "https://api.member-1.example.com:6443")
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(hub, toolchainSecret, kubeconfig)), map[string]string{})
+			tokens := &satoken.Provider{
+				Mechanism:  codereadyv1alpha1.TokenMechanismTokenRequest,
+				Expiration: time.Hour,
+				Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+					return "mysatoken", time.Now().Add(expiration), nil
+				},
+			}
+			memberCl := client.NewClient(fake.NewFakeClient())
+			members := member.NewMembersWithBuilder(func(cfg *rest.Config) (*member.Member, error) {
+				return &member.Member{Client: memberCl, Host: cfg.Host, Capabilities: capabilities.OpenShift4, Tokens: tokens}, nil
+			})
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache, tokens: tokens, members: members}
+
+			//when
+			_, err := r.Reconcile(newReconcileRequest(Name))
+
+			//then
+			require.NoError(t, err)
+			clusterService.AssertRegistered(t, "https://api.dsaas-stage.openshift.com/")
+			registered := clusterService.AssertRegistered(t, "https://api.member-1.openshift.com/")
+			assert.Equal(t, "system:serviceaccount:toolchain:toolchain-sre", registered.ServiceAccountUsername)
+			assert.Equal(t, "mysatoken", registered.ServiceAccountToken)
+			clusterService.AssertNotRegistered(t, "https://api.member-2.openshift.com/")
+
+			instance := getToolChainEnabler(t, cl)
+			sa, err := memberCl.GetServiceAccount(context.Background(), "toolchain", DefaultSAName)
+			require.NoError(t, err)
+			assert.Equal(t, component.OwnerLabels(instance), sa.Labels)
+			assert.Empty(t, sa.OwnerReferences)
+			crb, err := memberCl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
+			require.NoError(t, err)
+			assert.Equal(t, "toolchain", crb.Subjects[0].Namespace)
+
+			connected := instance.Status.GetMember("member-1")
+			require.NotNil(t, connected)
+			assert.Equal(t, "https://api.member-1.example.com:6443", connected.APIURL)
+			require.NotNil(t, connected.Capabilities)
+			assert.Equal(t, "4", connected.Capabilities.OpenShiftVersion)
+			assert.True(t, connected.IsConditionTrue(codereadyv1alpha1.MemberConnected))
+			assert.True(t, connected.IsConditionTrue(codereadyv1alpha1.MemberProvisioned))
+			assert.True(t, connected.IsConditionTrue(codereadyv1alpha1.ClusterRegistered))
+			missing := instance.Status.GetMember("member-2")
+			require.NotNil(t, missing)
+			condition := missing.GetCondition(codereadyv1alpha1.MemberConnected)
+			require.NotNil(t, condition)
+			assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			assert.Equal(t, ReasonKubeconfigNotFound, condition.Reason)
+			assert.Nil(t, missing.GetCondition(codereadyv1alpha1.ClusterRegistered))
+
+			//when
+			err = r.finalize(context.Background(), instance)
+
+			//then
+			require.NoError(t, err)
+			_, err = memberCl.GetServiceAccount(context.Background(), "toolchain", DefaultSAName)
+			assert.True(t, errors.IsNotFound(err), "sa not deleted from member cluster")
+			_, err = memberCl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
+			assert.True(t, errors.IsNotFound(err), "clusterrolebinding not deleted from member cluster")
+			_, err = memberCl.GetOAuthClient(context.Background(), DefaultOAuthClientName)
+			assert.True(t, errors.IsNotFound(err), "oauthclient not deleted from member cluster")
+		})
+
+		t.Run("member clusters removed from the spec cleaned up", func(t *testing.T) {
+			//given
+			require.NoError(t, apis.AddToScheme(s))
+			auth := NewFakeAuthService("bb6d043d-f243-458f-8498-2c18a12dcf47", "secret")
+			defer auth.Close()
+			clusterService := NewFakeClusterService(auth)
+			defer clusterService.Close()
+
+			hub := tce.DeepCopy()
+			hub.Spec = codereadyv1alpha1.ToolChainEnablerSpec{
+				AuthURL:             auth.URL,
+				ClusterURL:          clusterService.URL,
+				ClusterName:         "dsaas-stage",
+				ToolchainSecretName: "toolchain",
+				Members: []codereadyv1alpha1.MemberSpec{
+					{Name: "member-1", KubeconfigSecret: "member-1-kubeconfig", Namespace: "toolchain"},
+				},
+			}
+			toolchainSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "toolchain", Namespace: Namespace},
+				Data: map[string][]byte{
+					TCClientID:     []byte("bb6d043d-f243-458f-8498-2c18a12dcf47"),
+					TCClientSecret: []byte("secret"),
+				},
+			}
+			kubeconfig := memberKubeconfig("member-1", "https://api.member-1.example.com:6443")
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(hub, toolchainSecret, kubeconfig)), map[string]string{})
+			tokens := &satoken.Provider{
+				Mechanism:  codereadyv1alpha1.TokenMechanismTokenRequest,
+				Expiration: time.Hour,
+				Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+					return "mysatoken", time.Now().Add(expiration), nil
+				},
+			}
+			memberCl := client.NewClient(fake.NewFakeClient())
+			reachable := true
+			members := member.NewMembersWithBuilder(func(cfg *rest.Config) (*member.Member, error) {
+				ping := func() error {
+					if !reachable {
+						return fmt.Errorf("connection refused")
+					}
+					return nil
+				}
+				return &member.Member{Client: memberCl, Host: cfg.Host, Capabilities: capabilities.OpenShift4, Tokens: tokens, Ping: ping}, nil
+			})
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache, tokens: tokens, members: members}
+			_, err := r.Reconcile(newReconcileRequest(Name))
+			require.NoError(t, err)
+			clusterService.AssertRegistered(t, "https://api.member-1.openshift.com/")
+			provisioned := getToolChainEnabler(t, cl).Status.GetMember("member-1")
+			require.NotNil(t, provisioned)
+			assert.Equal(t, "member-1-kubeconfig", provisioned.KubeconfigSecret)
+			assert.Equal(t, "toolchain", provisioned.Namespace)
+			assert.Equal(t, "https://api.member-1.openshift.com/", provisioned.RegisteredAPIURL)
+
+			//when member is removed from the spec while it can't be reached
+			reachable = false
+			instance := getToolChainEnabler(t, cl)
+			instance.Spec.Members = nil
+			require.NoError(t, cl.Update(context.Background(), instance))
+			_, err = r.Reconcile(newReconcileRequest(Name))
+
+			//then
+			require.NoError(t, err)
+			clusterService.AssertRegistered(t, "https://api.member-1.openshift.com/")
+			_, err = memberCl.GetServiceAccount(context.Background(), "toolchain", DefaultSAName)
+			require.NoError(t, err)
+			skipped := getToolChainEnabler(t, cl).Status.GetMember("member-1")
+			require.NotNil(t, skipped)
+			condition := skipped.GetCondition(codereadyv1alpha1.MemberCleanedUp)
+			require.NotNil(t, condition)
+			assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			assert.Equal(t, ReasonConnectionFailed, condition.Reason)
+
+			//when member can be reached again
+			reachable = true
+			_, err = r.Reconcile(newReconcileRequest(Name))
+
+			//then
+			require.NoError(t, err)
+			clusterService.AssertNotRegistered(t, "https://api.member-1.openshift.com/")
+			_, err = memberCl.GetServiceAccount(context.Background(), "toolchain", DefaultSAName)
+			assert.True(t, errors.IsNotFound(err), "sa not deleted from member cluster")
+			_, err = memberCl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
+			assert.True(t, errors.IsNotFound(err), "clusterrolebinding not deleted from member cluster")
+			_, err = memberCl.GetOAuthClient(context.Background(), DefaultOAuthClientName)
+			assert.True(t, errors.IsNotFound(err), "oauthclient not deleted from member cluster")
+			assert.Empty(t, getToolChainEnabler(t, cl).Status.Members)
+		})
+
+		t.Run("openshift-infra events mapped to all ToolChainEnablers", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(objs...))
+			sa := &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      online_registration.ServiceAccountName,
+					Namespace: DefaultInfraNamespace,
+				},
+			}
+
+			//when
+			requests := allToolChainEnablers(cl)(handler.MapObject{Meta: sa, Object: sa})
+
+			//then
+			assert.Equal(t, []reconcile.Request{newReconcileRequest(Name)}, requests)
+		})
+
+		t.Run("secrets referred in spec mapped to ToolChainEnabler", func(t *testing.T) {
+			//given
+			referring := tce.DeepCopy()
+			referring.Spec = codereadyv1alpha1.ToolChainEnablerSpec{
+				ToolchainSecretName: "toolchain",
+				Kubernetes:          &codereadyv1alpha1.KubernetesSpec{OIDCClientSecret: "oidc-client"},
+				Members:             []codereadyv1alpha1.MemberSpec{{Name: "member-1", KubeconfigSecret: "member-1-kubeconfig"}},
+			}
+			cl := client.NewClient(fake.NewFakeClient(referring))
+
+			for _, name := range []string{"toolchain", "oidc-client", "member-1-kubeconfig"} {
+				secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: Namespace}}
+
+				//when
+				requests := referringToolChainEnablers(cl)(handler.MapObject{Meta: secret, Object: secret})
+
+				//then
+				assert.Equal(t, []reconcile.Request{newReconcileRequest(Name)}, requests, name)
+			}
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: Namespace}}
+			assert.Empty(t, referringToolChainEnablers(cl)(handler.MapObject{Meta: secret, Object: secret}))
+		})
+
+		t.Run("operator configmap mapped to all ToolChainEnablers", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(tce))
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: OperatorConfigMapName, Namespace: Namespace}}
+
+			//when
+			requests := referringToolChainEnablers(cl)(handler.MapObject{Meta: cm, Object: cm})
+
+			//then
+			assert.Equal(t, []reconcile.Request{newReconcileRequest(Name)}, requests)
 		})
 
 		t.Run("without ToolChainEnabler custom resource", func(t *testing.T) {
@@ -95,19 +745,19 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err, "reconcile is failing")
 			assert.False(t, res.Requeue, "reconcile requested requeue request")
 
-			sa, err := cl.GetServiceAccount(Namespace, SAName)
+			sa, err := cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
 			assert.Error(t, err, "failed to get not found error")
-			assert.Nil(t, sa, "found sa %s", SAName)
+			assert.Nil(t, sa, "found sa %s", DefaultSAName)
 
-			actual, err := cl.GetClusterRoleBinding(SelfProvisioner)
+			actual, err := cl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
 			assert.Error(t, err, "failed to get not found error")
 			assert.Nil(t, actual, "found ClusterRoleBinding %s", SelfProvisioner)
 
-			sa, err = cl.GetServiceAccount(online_registration.Namespace, online_registration.ServiceAccountName)
+			sa, err = cl.GetServiceAccount(context.Background(), DefaultInfraNamespace, online_registration.ServiceAccountName)
 			assert.Error(t, err, "failed to get not found error")
 			assert.Nil(t, sa, "found sa %s", online_registration.ServiceAccountName)
 
-			actual, err = cl.GetClusterRoleBinding(online_registration.ClusterRoleBindingName)
+			actual, err = cl.GetClusterRoleBinding(context.Background(), online_registration.ClusterRoleBindingName)
 			assert.Error(t, err, "failed to get not found error")
 			assert.Nil(t, actual, "found ClusterRoleBinding %s", online_registration.ClusterRoleBindingName)
 		})
@@ -130,9 +780,9 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err)
 
 			//when
-			err = r.ensureSA(instance)
+			err = r.ensureSA(context.Background(), instance)
 			//then
-			require.NoError(t, err, "failed to create SA %s", SAName)
+			require.NoError(t, err, "failed to create SA %s", DefaultSAName)
 			assertSA(t, cl)
 		})
 
@@ -151,15 +801,15 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err)
 
 			//create SA first time
-			err = r.ensureSA(instance)
-			require.NoError(t, err, "failed to create SA %s", SAName)
+			err = r.ensureSA(context.Background(), instance)
+			require.NoError(t, err, "failed to create SA %s", DefaultSAName)
 			assertSA(t, cl)
 
 			//when
-			err = r.ensureSA(instance)
+			err = r.ensureSA(context.Background(), instance)
 
 			//then
-			require.NoError(t, err, "failed to ensure SA %s", SAName)
+			require.NoError(t, err, "failed to ensure SA %s", DefaultSAName)
 			assertSA(t, cl)
 
 		})
@@ -182,12 +832,663 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err)
 
 			//when
-			err = r.ensureSA(instance)
+			err = r.ensureSA(context.Background(), instance)
+
+			//then
+			assert.EqualError(t, err, fmt.Sprintf("failed to get service account %s: %s", DefaultSAName, errMsg))
+		})
+
+		t.Run("named by operator configuration", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(objs...))
+			operatorConfig := DefaultOperatorConfig()
+			operatorConfig.ServiceAccountName = "toolchain"
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, operatorConfig: StaticOperatorConfig(operatorConfig)}
+
+			instance := &codereadyv1alpha1.ToolChainEnabler{}
+			err := r.client.Get(context.TODO(), newReconcileRequest(Name).NamespacedName, instance)
+			require.NoError(t, err)
+
+			//when
+			err = r.ensureSA(context.Background(), instance)
+
+			//then
+			require.NoError(t, err)
+			_, err = cl.GetServiceAccount(context.Background(), Namespace, "toolchain")
+			assert.NoError(t, err)
+			_, err = cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
+			assert.True(t, errors.IsNotFound(err))
+		})
+
+	})
+
+	t.Run("SA token", func(t *testing.T) {
+		t.Run("populated by api server", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(objs...))
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+			instance := getToolChainEnabler(t, cl)
+
+			//when
+			err := r.ensureSAToken(context.Background(), instance)
+
+			//then
+			require.NoError(t, err)
+			secret, err := cl.GetSecret(context.Background(), Namespace, "toolchain-sre-token")
+			require.NoError(t, err)
+			assert.Equal(t, corev1.SecretTypeServiceAccountToken, secret.Type)
+			assert.Equal(t, DefaultSAName, secret.Annotations[corev1.ServiceAccountNameKey])
+
+			token, err := r.tokenStatus(context.Background(), Namespace)
+			require.NoError(t, err)
+			assert.Equal(t, codereadyv1alpha1.TokenMechanismSecret, token.Mechanism)
+			assert.False(t, tokenRenewed(nil, token))
+		})
+
+		t.Run("minted via token request and renewed", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(objs...))
+			minted := 0
+			tokens := &satoken.Provider{
+				Mechanism:  codereadyv1alpha1.TokenMechanismTokenRequest,
+				Expiration: time.Hour,
+				Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+					minted++
+					return fmt.Sprintf("token-%d", minted), time.Now().Add(expiration), nil
+				},
+			}
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, tokens: tokens}
+			instance := getToolChainEnabler(t, cl)
+
+			//when
+			err := r.ensureSAToken(context.Background(), instance)
+
+			//then
+			require.NoError(t, err)
+			secret, err := cl.GetSecret(context.Background(), Namespace, "toolchain-sre-token")
+			require.NoError(t, err)
+			assert.Equal(t, "token-1", satoken.Token(secret))
+			token, err := r.tokenStatus(context.Background(), Namespace)
+			require.NoError(t, err)
+			assert.True(t, tokenRenewed(nil, token), "minted token not registered")
+			assert.False(t, tokenRenewed(token, token))
+			assert.True(t, requeueAfter(10*time.Hour, token) <= 48*time.Minute)
+
+			//when not due
+			err = r.ensureSAToken(context.Background(), instance)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, 1, minted)
+
+			//when due
+			secret.Annotations[satoken.IssueTimeAnnotation] = time.Now().Add(-55 * time.Minute).UTC().Format(time.RFC3339)
+			secret.Annotations[satoken.ExpirationTimeAnnotation] = time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339)
+			require.NoError(t, cl.Update(context.Background(), secret))
+			err = r.ensureSAToken(context.Background(), instance)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, 2, minted)
+			secret, err = cl.GetSecret(context.Background(), Namespace, "toolchain-sre-token")
+			require.NoError(t, err)
+			assert.Equal(t, "token-2", satoken.Token(secret))
+			renewed, err := r.tokenStatus(context.Background(), Namespace)
+			require.NoError(t, err)
+			assert.True(t, tokenRenewed(token, renewed), "renewed token not registered")
+		})
+	})
+
+	t.Run("project template", func(t *testing.T) {
+		//given
+		require.NoError(t, apis.AddToScheme(s))
+		withTemplate := tce.DeepCopy()
+		withTemplate.Spec.ProjectTemplate = &codereadyv1alpha1.ProjectTemplateSpec{
+			LimitRange: &corev1.LimitRangeSpec{
+				Limits: []corev1.LimitRangeItem{{Type: corev1.LimitTypeContainer}},
+			},
+		}
+		projectConfig := &configv1.Project{ObjectMeta: metav1.ObjectMeta{Name: project.ConfigName}}
+		cl := client.NewClient(fake.NewFakeClient(withTemplate, projectConfig))
+		r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+		instance := getToolChainEnabler(t, cl)
+
+		//when
+		err := r.ensureProjectTemplate(context.Background(), instance)
+
+		//then
+		require.NoError(t, err)
+		instance = getToolChainEnabler(t, cl)
+		assert.Contains(t, instance.Finalizers, project.Finalizer)
+		assert.True(t, instance.Status.IsConditionTrue(codereadyv1alpha1.ProjectTemplateReady))
+		err = cl.Get(context.Background(), types.NamespacedName{Namespace: project.Namespace, Name: project.TemplateName}, &templatev1.Template{})
+		require.NoError(t, err)
+
+		//when
+		err = r.finalize(context.Background(), instance)
+
+		//then
+		require.NoError(t, err)
+		instance = getToolChainEnabler(t, cl)
+		assert.NotContains(t, instance.Finalizers, project.Finalizer)
+		err = cl.Get(context.Background(), types.NamespacedName{Namespace: project.Namespace, Name: project.TemplateName}, &templatev1.Template{})
+		assert.True(t, errors.IsNotFound(err), "project request template not removed")
+		config := &configv1.Project{}
+		require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Name: project.ConfigName}, config))
+		assert.Empty(t, config.Spec.ProjectRequestTemplate.Name)
+	})
+
+	t.Run("project template shared by ToolChainEnablers", func(t *testing.T) {
+		require.NoError(t, apis.AddToScheme(s))
+		spec := func(cpu string) *codereadyv1alpha1.ProjectTemplateSpec {
+			return &codereadyv1alpha1.ProjectTemplateSpec{
+				ResourceQuota: &corev1.ResourceQuotaSpec{
+					Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse(cpu)},
+				},
+			}
+		}
+		// the oldest ToolChainEnabler holds the template, ties are broken by name
+		holder := tce.DeepCopy()
+		holder.Spec.ProjectTemplate = spec("2")
+		newOther := func(cpu string) *codereadyv1alpha1.ToolChainEnabler {
+			other := tce.DeepCopy()
+			other.Name = "zz-other"
+			other.Spec.ProjectTemplate = spec(cpu)
+			return other
+		}
+		getTemplate := func(t *testing.T, cl client.Client) *templatev1.Template {
+			template := &templatev1.Template{}
+			require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: project.Namespace, Name: project.TemplateName}, template))
+			return template
+		}
+
+		t.Run("different one rejected", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(holder, newOther("3")))
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+			require.NoError(t, r.ensureProjectTemplate(context.Background(), getToolChainEnabler(t, cl)))
+			provisioned := getTemplate(t, cl)
+			other := &codereadyv1alpha1.ToolChainEnabler{}
+			require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: Namespace, Name: "zz-other"}, other))
+
+			//when
+			err := r.ensureProjectTemplate(context.Background(), other)
+
+			//then
+			require.Error(t, err)
+			assert.True(t, IsProjectTemplateConflict(err))
+			assert.EqualError(t, err, fmt.Sprintf("project request template differs from the one provisioned by ToolChainEnabler %s/%s", Namespace, Name))
+			assert.Equal(t, provisioned.Objects, getTemplate(t, cl).Objects, "project request template overwritten")
+			require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: Namespace, Name: "zz-other"}, other))
+			assert.NotContains(t, other.Finalizers, project.Finalizer)
+			condition := other.Status.GetCondition(codereadyv1alpha1.ProjectTemplateReady)
+			require.NotNil(t, condition)
+			assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			assert.Equal(t, ReasonProjectTemplateConflict, condition.Reason)
+		})
+
+		t.Run("same one kept while held by other", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(holder, newOther("2000m")))
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+			instance := getToolChainEnabler(t, cl)
+			require.NoError(t, r.ensureProjectTemplate(context.Background(), instance))
+			other := &codereadyv1alpha1.ToolChainEnabler{}
+			require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: Namespace, Name: "zz-other"}, other))
+			require.NoError(t, r.ensureProjectTemplate(context.Background(), other))
+
+			//when
+			err := r.finalize(context.Background(), getToolChainEnabler(t, cl))
+
+			//then
+			require.NoError(t, err)
+			assert.NotContains(t, getToolChainEnabler(t, cl).Finalizers, project.Finalizer)
+			getTemplate(t, cl)
+		})
+	})
+
+	t.Run("cluster resources", func(t *testing.T) {
+		//given
+		require.NoError(t, apis.AddToScheme(s))
+		orphan := &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "orphan",
+				Labels: map[string]string{component.OwnerNameLabel: "deleted", component.OwnerNamespaceLabel: Namespace},
+			},
+		}
+		foreign := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "foreign"}}
+		cl := client.NewClient(fake.NewFakeClient(tce, orphan, foreign))
+		r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+		instance := getToolChainEnabler(t, cl)
+		require.NoError(t, r.addFinalizer(context.Background(), instance, ClusterResourcesFinalizer))
+
+		//when
+		err := r.ensureClusterRoleBinding(context.Background(), instance, DefaultSAName, Namespace)
+		require.NoError(t, err)
+		err = r.ensureOIDCClient(context.Background(), instance)
+		require.NoError(t, err)
+
+		//then
+		crb, err := cl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
+		require.NoError(t, err)
+		assert.Equal(t, component.OwnerLabels(instance), crb.Labels)
+		assert.Empty(t, crb.OwnerReferences)
+		oauthClient, err := cl.GetOAuthClient(context.Background(), DefaultOAuthClientName)
+		require.NoError(t, err)
+		assert.Equal(t, component.OwnerLabels(instance), oauthClient.Labels)
+
+		//when
+		err = SweepOrphans(context.Background(), cl, Namespace, DefaultInfraNamespace)
+
+		//then
+		require.NoError(t, err)
+		_, err = cl.GetClusterRoleBinding(context.Background(), "orphan")
+		assert.True(t, errors.IsNotFound(err), "orphaned clusterrolebinding not deleted")
+		_, err = cl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
+		assert.NoError(t, err)
+
+		//when
+		err = r.finalize(context.Background(), instance)
+
+		//then
+		require.NoError(t, err)
+		for _, name := range []string{SelfProvisioner, DsaasClusterAdmin} {
+			_, err = cl.GetClusterRoleBinding(context.Background(), name)
+			assert.True(t, errors.IsNotFound(err), "clusterrolebinding %s not deleted", name)
+		}
+		_, err = cl.GetOAuthClient(context.Background(), DefaultOAuthClientName)
+		assert.True(t, errors.IsNotFound(err), "oauthclient not deleted")
+		_, err = cl.GetClusterRoleBinding(context.Background(), "foreign")
+		assert.NoError(t, err)
+		assert.NotContains(t, getToolChainEnabler(t, cl).Finalizers, ClusterResourcesFinalizer)
+	})
+
+	t.Run("shared online-registration resources", func(t *testing.T) {
+		//given
+		require.NoError(t, apis.AddToScheme(s))
+		other := tce.DeepCopy()
+		other.Name = "other"
+		// online-registration resources are labeled with the ToolChainEnabler which created them
+		sa := &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      online_registration.ServiceAccountName,
+				Namespace: DefaultInfraNamespace,
+				Labels:    component.OwnerLabels(tce),
+			},
+		}
+		crb := &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   online_registration.ClusterRoleBindingName,
+				Labels: component.OwnerLabels(tce),
+			},
+		}
+		cl := client.NewClient(fake.NewFakeClient(tce, other, sa, crb))
+		r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+		instance := getToolChainEnabler(t, cl)
+		require.NoError(t, r.addFinalizer(context.Background(), instance, ClusterResourcesFinalizer))
+
+		//when
+		err := r.finalize(context.Background(), instance)
+
+		//then
+		require.NoError(t, err)
+		_, err = cl.GetServiceAccount(context.Background(), DefaultInfraNamespace, online_registration.ServiceAccountName)
+		assert.NoError(t, err, "online-registration sa deleted while enabled by other ToolChainEnabler")
+		_, err = cl.GetClusterRoleBinding(context.Background(), online_registration.ClusterRoleBindingName)
+		assert.NoError(t, err, "online-registration clusterrolebinding deleted while enabled by other ToolChainEnabler")
+
+		//when
+		require.NoError(t, cl.Delete(context.Background(), instance))
+		err = SweepOrphans(context.Background(), cl, Namespace, DefaultInfraNamespace)
+
+		//then
+		require.NoError(t, err)
+		_, err = cl.GetClusterRoleBinding(context.Background(), online_registration.ClusterRoleBindingName)
+		assert.NoError(t, err, "online-registration clusterrolebinding swept while enabled by other ToolChainEnabler")
+
+		//when
+		require.NoError(t, cl.Delete(context.Background(), other))
+		err = SweepOrphans(context.Background(), cl, Namespace, DefaultInfraNamespace)
+
+		//then
+		require.NoError(t, err)
+		_, err = cl.GetServiceAccount(context.Background(), DefaultInfraNamespace, online_registration.ServiceAccountName)
+		assert.True(t, errors.IsNotFound(err), "online-registration sa not deleted")
+		_, err = cl.GetClusterRoleBinding(context.Background(), online_registration.ClusterRoleBindingName)
+		assert.True(t, errors.IsNotFound(err), "online-registration clusterrolebinding not deleted")
+	})
+
+	t.Run("resources of previous names released", func(t *testing.T) {
+		//given
+		require.NoError(t, apis.AddToScheme(s))
+		renamed := tce.DeepCopy()
+		renamed.Status.ProvisionedNames = &codereadyv1alpha1.ProvisionedNames{ServiceAccountName: "old-sre", OAuthClientName: "old-client", InfraNamespace: "old-infra"}
+		labeled := func(name, namespace string) metav1.ObjectMeta {
+			return metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: component.OwnerLabels(tce)}
+		}
+		objs := []runtime.Object{
+			renamed,
+			&corev1.ServiceAccount{ObjectMeta: labeled("old-sre", Namespace)},
+			&corev1.Secret{ObjectMeta: labeled(satoken.SecretName("old-sre"), Namespace)},
+			&oauthv1.OAuthClient{ObjectMeta: labeled("old-client", "")},
+			&corev1.ServiceAccount{ObjectMeta: labeled(online_registration.ServiceAccountName, "old-infra")},
+			// created manually, never deleted
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "old-sre", Namespace: "other"}},
+		}
+		cl := client.NewClient(fake.NewFakeClient(objs...))
+		r := &ReconcileToolChainEnabler{client: cl, scheme: s, operatorConfig: StaticOperatorConfig(DefaultOperatorConfig()), capabilities: capabilities.Static(capabilities.OpenShift4)}
+		instance := getToolChainEnabler(t, cl)
+
+		//when
+		err := r.releaseRenamed(context.Background(), instance)
+
+		//then
+		require.NoError(t, err)
+		_, err = cl.GetServiceAccount(context.Background(), Namespace, "old-sre")
+		assert.True(t, errors.IsNotFound(err), "sa of previous name not deleted")
+		_, err = cl.GetSecret(context.Background(), Namespace, satoken.SecretName("old-sre"))
+		assert.True(t, errors.IsNotFound(err), "token secret of previous sa name not deleted")
+		err = cl.Get(context.Background(), types.NamespacedName{Name: "old-client"}, &oauthv1.OAuthClient{})
+		assert.True(t, errors.IsNotFound(err), "oauthclient of previous name not deleted")
+		_, err = cl.GetServiceAccount(context.Background(), "old-infra", online_registration.ServiceAccountName)
+		assert.True(t, errors.IsNotFound(err), "online-registration sa of previous infra namespace not deleted")
+		_, err = cl.GetServiceAccount(context.Background(), "other", "old-sre")
+		assert.NoError(t, err, "sa not created by the operator deleted")
+		instance = getToolChainEnabler(t, cl)
+		require.NotNil(t, instance.Status.ProvisionedNames)
+		assert.Equal(t, codereadyv1alpha1.ProvisionedNames{ServiceAccountName: DefaultSAName, OAuthClientName: DefaultOAuthClientName, InfraNamespace: DefaultInfraNamespace}, *instance.Status.ProvisionedNames)
+	})
+
+	t.Run("adoption", func(t *testing.T) {
+		require.NoError(t, apis.AddToScheme(s))
+		// resources created manually before the operator was deployed
+		existing := func() []runtime.Object {
+			return []runtime.Object{
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultSAName, Namespace: Namespace}},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: SelfProvisioner},
+					RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "self-provisioner"},
+				},
+				&oauthv1.OAuthClient{
+					ObjectMeta:   metav1.ObjectMeta{Name: DefaultOAuthClientName},
+					Secret:       "manual",
+					GrantMethod:  oauthv1.GrantHandlerPrompt,
+					RedirectURIs: []string{"https://auth.openshift.io/"},
+				},
+			}
+		}
+		withPolicy := func(policy codereadyv1alpha1.AdoptionPolicy) *codereadyv1alpha1.ToolChainEnabler {
+			instance := tce.DeepCopy()
+			instance.Spec.AdoptionPolicy = policy
+			return instance
+		}
+
+		t.Run("ignore", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(append(existing(), tce)...))
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+
+			//when
+			err := r.ensureToolchainResources(context.Background(), getToolChainEnabler(t, cl))
+
+			//then
+			require.NoError(t, err)
+			sa, err := cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
+			require.NoError(t, err)
+			assert.Empty(t, sa.OwnerReferences)
+			crb, err := cl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
+			require.NoError(t, err)
+			assert.Empty(t, crb.Subjects)
+			assert.Empty(t, crb.Labels)
+			oauthClient, err := cl.GetOAuthClient(context.Background(), DefaultOAuthClientName)
+			require.NoError(t, err)
+			assert.Equal(t, oauthv1.GrantHandlerPrompt, oauthClient.GrantMethod)
+			assert.NotContains(t, oauthClient.Annotations, component.AdoptedAnnotation)
+		})
+
+		t.Run("adopt", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient(append(existing(), withPolicy(codereadyv1alpha1.AdoptionPolicyAdopt))...))
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+			instance := getToolChainEnabler(t, cl)
+
+			//when
+			err := r.ensureToolchainResources(context.Background(), instance)
 
 			//then
-			assert.EqualError(t, err, fmt.Sprintf("failed to get service account %s: %s", SAName, errMsg))
+			require.NoError(t, err)
+			sa, err := cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
+			require.NoError(t, err)
+			assert.True(t, metav1.IsControlledBy(sa, instance))
+			assertAdopted(t, sa, "metadata.ownerReferences")
+
+			assertClusterRoleBinding(t, cl)
+			crb, err := cl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
+			require.NoError(t, err)
+			assert.Equal(t, component.OwnerLabels(instance), crb.Labels)
+			assertAdopted(t, crb, "metadata.labels", "subjects")
+
+			oauthClient, err := cl.GetOAuthClient(context.Background(), DefaultOAuthClientName)
+			require.NoError(t, err)
+			assert.Equal(t, component.OwnerLabels(instance), oauthClient.Labels)
+			assert.Equal(t, oauthv1.GrantHandlerAuto, oauthClient.GrantMethod)
+			assert.Equal(t, "manual", oauthClient.Secret, "secret registered in cluster service changed")
+			assertAdopted(t, oauthClient, "accessTokenMaxAgeSeconds", "grantMethod", "metadata.labels")
+
+			assert.Contains(t, Features(instance), permissions.Adoption)
 		})
 
+		t.Run("fail", func(t *testing.T) {
+			//given
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(append(existing(), withPolicy(codereadyv1alpha1.AdoptionPolicyFail))...)), map[string]string{})
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache}
+			req := newReconcileRequest(Name)
+
+			//when
+			res, err := r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, reconcile.Result{RequeueAfter: DefaultPermissionsRecheckPeriod}, res)
+			sa, err := cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
+			require.NoError(t, err)
+			assert.Empty(t, sa.OwnerReferences)
+
+			condition := getToolChainEnabler(t, cl).Status.GetCondition(codereadyv1alpha1.Degraded)
+			require.NotNil(t, condition)
+			assert.Equal(t, corev1.ConditionTrue, condition.Status)
+			assert.Equal(t, ReasonResourceNotOwned, condition.Reason)
+			assert.Equal(t, fmt.Sprintf("service account %s already exists and isn't owned by the operator", DefaultSAName), condition.Message)
+		})
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		require.NoError(t, apis.AddToScheme(s))
+		paused := func(annotations map[string]string) []runtime.Object {
+			instance := tce.DeepCopy()
+			instance.Annotations = annotations
+			// role binding edited by hand during incident
+			edited := &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: SelfProvisioner, Labels: component.OwnerLabels(instance)},
+				RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "self-provisioner"},
+			}
+			return []runtime.Object{instance, edited}
+		}
+
+		t.Run("drift reported and resumed", func(t *testing.T) {
+			//given
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(paused(map[string]string{PausedAnnotation: "true"})...)), map[string]string{})
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache}
+			req := newReconcileRequest(Name)
+
+			//when
+			res, err := r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			assert.Equal(t, reconcile.Result{RequeueAfter: DefaultDriftCheckPeriod}, res)
+			crb, err := cl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
+			require.NoError(t, err)
+			assert.Empty(t, crb.Subjects)
+			_, err = cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
+			assert.True(t, errors.IsNotFound(err), "sa %s created while paused", DefaultSAName)
+
+			instance := getToolChainEnabler(t, cl)
+			condition := instance.Status.GetCondition(codereadyv1alpha1.Paused)
+			require.NotNil(t, condition)
+			assert.Equal(t, corev1.ConditionTrue, condition.Status)
+			assert.Equal(t, ReasonPausedByAnnotation, condition.Reason)
+			assert.Nil(t, instance.Status.GetCondition(codereadyv1alpha1.OperatorAuthorized))
+			assert.Equal(t, []string{
+				"service account toolchain-sre doesn't exist",
+				"secret toolchain-sre-token doesn't exist",
+				"clusterrolebinding system:toolchain-sre:self-provisioner differs from the desired state in subjects",
+				"clusterrolebinding system:toolchain-sre:dsaas-cluster-admin doesn't exist",
+				"oauthclient codeready-toolchain doesn't exist",
+			}, instance.Status.Drift)
+
+			//when resumed
+			delete(instance.Annotations, PausedAnnotation)
+			require.NoError(t, cl.Update(context.Background(), instance))
+			_, err = r.Reconcile(req)
+
+			//then
+			assert.EqualError(t, err, "'toolchainSecretName' is empty")
+			assertSA(t, cl)
+			assertClusterRoleBinding(t, cl)
+			instance = getToolChainEnabler(t, cl)
+			assert.Nil(t, instance.Status.GetCondition(codereadyv1alpha1.Paused))
+			assert.Empty(t, instance.Status.Drift)
+		})
+
+		t.Run("expired", func(t *testing.T) {
+			//given
+			until := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(paused(map[string]string{PausedAnnotation: "true", PausedUntilAnnotation: until})...)), map[string]string{})
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache}
+
+			//when
+			_, err := r.Reconcile(newReconcileRequest(Name))
+
+			//then
+			assert.EqualError(t, err, "'toolchainSecretName' is empty")
+			assertClusterRoleBinding(t, cl)
+		})
+
+		t.Run("until", func(t *testing.T) {
+			now := time.Now()
+			instance := tce.DeepCopy()
+
+			instance.Annotations = map[string]string{PausedAnnotation: "true", PausedUntilAnnotation: now.Add(time.Hour).UTC().Format(time.RFC3339)}
+			paused, until := pausedUntil(instance, now)
+			assert.True(t, paused)
+			assert.Equal(t, now.Add(time.Hour).Unix(), until.Unix())
+
+			instance.Annotations[PausedUntilAnnotation] = "tomorrow"
+			paused, until = pausedUntil(instance, now)
+			assert.True(t, paused, "invalid expiry lifted the pause")
+			assert.True(t, until.IsZero())
+
+			instance.Annotations[PausedAnnotation] = "false"
+			paused, _ = pausedUntil(instance, now)
+			assert.False(t, paused)
+
+			assert.True(t, pauseChanged(nil, map[string]string{PausedAnnotation: "true"}))
+			assert.False(t, pauseChanged(map[string]string{"other": "a"}, map[string]string{"other": "b"}))
+		})
+	})
+
+	t.Run("cluster configuration", func(t *testing.T) {
+		require.NoError(t, apis.AddToScheme(s))
+		infrastructure := func(apiURL string) *configv1.Infrastructure {
+			return &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: cluster.ConfigName},
+				Status:     configv1.InfrastructureStatus{APIServerURL: apiURL},
+			}
+		}
+
+		t.Run("registered again after migration", func(t *testing.T) {
+			//given
+			auth := NewFakeAuthService("bb6d043d-f243-458f-8498-2c18a12dcf47", "secret")
+			defer auth.Close()
+			clusterService := NewFakeClusterService(auth)
+			defer clusterService.Close()
+
+			registering := tce.DeepCopy()
+			registering.Spec = codereadyv1alpha1.ToolChainEnablerSpec{
+				AuthURL:             auth.URL,
+				ClusterURL:          clusterService.URL,
+				ToolchainSecretName: "toolchain",
+			}
+			toolchainSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "toolchain", Namespace: Namespace},
+				Data: map[string][]byte{
+					TCClientID:     []byte("bb6d043d-f243-458f-8498-2c18a12dcf47"),
+					TCClientSecret: []byte("secret"),
+				},
+			}
+			ingress := &configv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: cluster.ConfigName},
+				Spec:       configv1.IngressSpec{Domain: "apps.dsaas-stage.openshift.com"},
+			}
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(registering, toolchainSecret, infrastructure("https://api.dsaas-stage.openshift.com:6443"), ingress)), map[string]string{})
+			tokens := &satoken.Provider{
+				Mechanism:  codereadyv1alpha1.TokenMechanismTokenRequest,
+				Expiration: time.Hour,
+				Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+					return "mysatoken", time.Now().Add(expiration), nil
+				},
+			}
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s, cache: cache, tokens: tokens, capabilities: capabilities.Static(capabilities.OpenShift4)}
+			req := newReconcileRequest(Name)
+			_, err := r.Reconcile(req)
+			require.NoError(t, err)
+			registered := clusterService.AssertRegistered(t, "https://api.dsaas-stage.openshift.com:6443")
+			assert.Equal(t, "apps.dsaas-stage.openshift.com", registered.AppDNS)
+
+			//when nothing has changed
+			_, err = r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			clusterService.AssertRequests(t, http.MethodPost, "/api/clusters", 1)
+
+			//when
+			migrated := &configv1.Infrastructure{}
+			require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Name: cluster.ConfigName}, migrated))
+			migrated.Status.APIServerURL = "https://api.dsaas-prod.openshift.com:6443"
+			require.NoError(t, cl.Update(context.Background(), migrated))
+			_, err = r.Reconcile(req)
+
+			//then
+			require.NoError(t, err)
+			clusterService.AssertRegistered(t, "https://api.dsaas-prod.openshift.com:6443")
+			clusterService.AssertRequests(t, http.MethodPost, "/api/clusters", 2)
+			instance := getToolChainEnabler(t, cl)
+			assert.Equal(t, codereadyv1alpha1.VerificationReregistered, instance.Status.Verification.Result)
+		})
+
+		t.Run("events filtered by registered fields", func(t *testing.T) {
+			//given
+			predicate := clusterConfigChanged()
+			updated := func(before, after runtime.Object) event.UpdateEvent {
+				return event.UpdateEvent{MetaOld: before.(metav1.Object), ObjectOld: before, MetaNew: after.(metav1.Object), ObjectNew: after}
+			}
+			relabeled := infrastructure("https://api.dsaas-stage.openshift.com:6443")
+			relabeled.Labels = map[string]string{"foo": "bar"}
+			other := infrastructure("https://api.dsaas-stage.openshift.com:6443")
+			other.Name = "other"
+
+			//then
+			assert.True(t, predicate.Update(updated(infrastructure("https://api.dsaas-stage.openshift.com:6443"), infrastructure("https://api.dsaas-prod.openshift.com:6443"))))
+			assert.False(t, predicate.Update(updated(infrastructure("https://api.dsaas-stage.openshift.com:6443"), relabeled)))
+			assert.False(t, predicate.Update(updated(other, other)))
+			assert.True(t, predicate.Update(updated(
+				&configv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: cluster.ConfigName}, Spec: configv1.IngressSpec{Domain: "apps.dsaas-stage.openshift.com"}},
+				&configv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: cluster.ConfigName}, Spec: configv1.IngressSpec{Domain: "apps.dsaas-prod.openshift.com"}},
+			)))
+			assert.False(t, predicate.Create(event.CreateEvent{Meta: other, Object: other}))
+		})
 	})
 
 	t.Run("ClusterRoleBinding", func(t *testing.T) {
@@ -206,9 +1507,9 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err)
 
 			//when
-			err = r.ensureClusterRoleBinding(instance, SAName, Namespace)
+			err = r.ensureClusterRoleBinding(context.Background(), instance, DefaultSAName, Namespace)
 			//then
-			require.NoError(t, err, "failed to create ClusterRoleBinding %s", SAName)
+			require.NoError(t, err, "failed to create ClusterRoleBinding %s", DefaultSAName)
 			assertClusterRoleBinding(t, cl)
 		})
 
@@ -229,13 +1530,13 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err)
 
 			// create ClusterRolebinding first time
-			err = r.ensureClusterRoleBinding(instance, SAName, Namespace)
+			err = r.ensureClusterRoleBinding(context.Background(), instance, DefaultSAName, Namespace)
 
 			require.NoError(t, err, "failed to create ClusterRoleBinding %s", SelfProvisioner)
 			assertClusterRoleBinding(t, cl)
 
 			// when
-			err = r.ensureClusterRoleBinding(instance, SAName, Namespace)
+			err = r.ensureClusterRoleBinding(context.Background(), instance, DefaultSAName, Namespace)
 
 			require.NoError(t, err, "failed to ensure ClusterRoleBinding %s", SelfProvisioner)
 			assertClusterRoleBinding(t, cl)
@@ -260,7 +1561,7 @@ func TestToolChainEnablerController(t *testing.T) {
 			err := r.client.Get(context.TODO(), req.NamespacedName, instance)
 			require.NoError(t, err)
 
-			err = r.ensureClusterRoleBinding(instance, SAName, Namespace)
+			err = r.ensureClusterRoleBinding(context.Background(), instance, DefaultSAName, Namespace)
 
 			//then
 			assert.EqualError(t, err, fmt.Sprintf("failed to get clusterrolebinding %s: %s", SelfProvisioner, errMsg))
@@ -287,9 +1588,9 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err)
 
 			//when
-			err = r.ensureOAuthClient(instance)
+			err = r.ensureOIDCClient(context.Background(), instance)
 			//then
-			require.NoError(t, err, "failed to create OAuthClient %s", OAuthClientName)
+			require.NoError(t, err, "failed to create OAuthClient %s", DefaultOAuthClientName)
 			assertOAuthClient(t, cl)
 		})
 
@@ -310,15 +1611,15 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err)
 
 			// create OAuthClient first time
-			err = r.ensureOAuthClient(instance)
+			err = r.ensureOIDCClient(context.Background(), instance)
 
-			require.NoError(t, err, "failed to create OAuthClient %s", OAuthClientName)
+			require.NoError(t, err, "failed to create OAuthClient %s", DefaultOAuthClientName)
 			assertOAuthClient(t, cl)
 
 			// when
-			err = r.ensureOAuthClient(instance)
+			err = r.ensureOIDCClient(context.Background(), instance)
 
-			require.NoError(t, err, "failed to ensure OAuthClient %s", OAuthClientName)
+			require.NoError(t, err, "failed to ensure OAuthClient %s", DefaultOAuthClientName)
 			assertOAuthClient(t, cl)
 		})
 
@@ -340,9 +1641,9 @@ func TestToolChainEnablerController(t *testing.T) {
 			require.NoError(t, err)
 
 			//when
-			err = r.ensureOAuthClient(instance)
+			err = r.ensureOIDCClient(context.Background(), instance)
 			//then
-			assert.Error(t, err, "failed to get oauthclient %s: %s", OAuthClientName, errMsg)
+			assert.Error(t, err, "failed to get oauthclient %s: %s", DefaultOAuthClientName, errMsg)
 		})
 	})
 
@@ -364,20 +1665,20 @@ func TestToolChainEnablerController(t *testing.T) {
 			instance := &codereadyv1alpha1.ToolChainEnabler{}
 			err = r.client.Get(context.TODO(), req.NamespacedName, instance)
 			require.NoError(t, err)
-			err = r.ensureClusterRoleBinding(instance, SAName, Namespace)
+			err = r.ensureClusterRoleBinding(context.Background(), instance, DefaultSAName, Namespace)
 			require.NoError(t, err)
 			assertClusterRoleBinding(t, cl)
 
-			err = r.ensureSA(instance)
+			err = r.ensureSA(context.Background(), instance)
 			require.NoError(t, err)
-			err = r.ensureOAuthClient(instance)
+			err = r.ensureOIDCClient(context.Background(), instance)
 			require.NoError(t, err)
 
 			// create secrets required to refer in service account
 			saSecretOption := SASecretOption(t, cl, Namespace)
 
 			//when
-			clusterData, err := r.clusterInfo(Namespace, newConfig(), saSecretOption)
+			clusterData, err := r.clusterInfo(context.Background(), instance, newConfig(), saSecretOption)
 
 			//then
 			require.NoError(t, err, "reconcile is failing")
@@ -401,22 +1702,74 @@ func TestToolChainEnablerController(t *testing.T) {
 			instance := &codereadyv1alpha1.ToolChainEnabler{}
 			err = r.client.Get(context.TODO(), req.NamespacedName, instance)
 			require.NoError(t, err)
-			err = r.ensureClusterRoleBinding(instance, SAName, Namespace)
+			err = r.ensureClusterRoleBinding(context.Background(), instance, DefaultSAName, Namespace)
 			require.NoError(t, err)
 			assertClusterRoleBinding(t, cl)
-			err = r.ensureSA(instance)
+			err = r.ensureSA(context.Background(), instance)
 			require.NoError(t, err)
-			err = r.ensureOAuthClient(instance)
+			err = r.ensureOIDCClient(context.Background(), instance)
 			require.NoError(t, err)
 
 			//when
-			_, err = r.clusterInfo(Namespace, newConfig())
+			_, err = r.clusterInfo(context.Background(), instance, newConfig())
 
 			//then
 			assert.EqualError(t, err, "couldn't find any secret reference for sa toolchain-sre")
 		})
 	})
 
+	t.Run("permissions", func(t *testing.T) {
+		t.Run("granted", func(t *testing.T) {
+			//given
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(objs...)), map[string]string{})
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+
+			//when
+			err := r.checkPermissions(context.Background(), tce)
+
+			//then
+			assert.NoError(t, err)
+		})
+
+		t.Run("missing", func(t *testing.T) {
+			//given
+			cl := NewDummyClient(client.NewClient(fake.NewFakeClient(objs...)), map[string]string{"denied": "resourcequotas"})
+			r := &ReconcileToolChainEnabler{client: cl, scheme: s}
+
+			//when
+			err := r.checkPermissions(context.Background(), tce)
+
+			//then
+			assert.EqualError(t, err, "service account toolchain-sre is missing permissions: create resourcequotas, get resourcequotas, list resourcequotas, update resourcequotas, delete resourcequotas")
+		})
+	})
+
+	t.Run("cluster service check", func(t *testing.T) {
+		t.Run("without ToolChainEnabler", func(t *testing.T) {
+			// given
+			cl := client.NewClient(fake.NewFakeClient())
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+			// when
+			err := ClusterServiceCheck(cl, Namespace, nil)(req)
+
+			// then
+			assert.NoError(t, err)
+		})
+
+		t.Run("misconfigured ToolChainEnabler", func(t *testing.T) {
+			// given
+			cl := client.NewClient(fake.NewFakeClient(objs...))
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+			// when
+			err := ClusterServiceCheck(cl, Namespace, nil)(req)
+
+			// then
+			assert.EqualError(t, err, "'toolchainSecretName' is empty")
+		})
+	})
+
 	t.Run("save cluster config", func(t *testing.T) {
 		// given
 		defer gock.OffAll()
@@ -457,7 +1810,7 @@ func TestToolChainEnablerController(t *testing.T) {
 		}
 
 		// when
-		err := r.saveClusterConfiguration(clusterData, cfg, httpsupport.WithRoundTripper(http.DefaultTransport))
+		err := r.saveClusterConfiguration(context.Background(), cluster.NewClusterService(cfg), clusterData, httpsupport.WithRoundTripper(http.DefaultTransport))
 
 		//then
 		assert.NoError(t, err)
@@ -466,14 +1819,20 @@ func TestToolChainEnablerController(t *testing.T) {
 
 func assertSA(t *testing.T, cl client.Client) {
 	// Check if Service Account has been created
-	sa, err := cl.GetServiceAccount(Namespace, SAName)
-	assert.NoError(t, err, "couldn't find created sa %s in namespace %s", SAName, Namespace)
+	sa, err := cl.GetServiceAccount(context.Background(), Namespace, DefaultSAName)
+	assert.NoError(t, err, "couldn't find created sa %s in namespace %s", DefaultSAName, Namespace)
 	assert.NotNil(t, sa)
 }
 
+func assertAdopted(t *testing.T, obj metav1.Object, changed ...string) {
+	record := component.AdoptionRecord{}
+	require.NoError(t, json.Unmarshal([]byte(obj.GetAnnotations()[component.AdoptedAnnotation]), &record), "%s not adopted", obj.GetName())
+	assert.Equal(t, changed, record.Changed)
+}
+
 func assertClusterRoleBinding(t *testing.T, cl client.Client) {
 	// Check Service Account has self-provision ClusterRole
-	actual, err := cl.GetClusterRoleBinding(SelfProvisioner)
+	actual, err := cl.GetClusterRoleBinding(context.Background(), SelfProvisioner)
 	assert.NoError(t, err, "couldn't find ClusterRoleBinding %s", SelfProvisioner)
 	assert.NotNil(t, actual)
 
@@ -481,7 +1840,7 @@ func assertClusterRoleBinding(t *testing.T, cl client.Client) {
 		{
 			Kind:      "ServiceAccount",
 			APIGroup:  "",
-			Name:      SAName,
+			Name:      DefaultSAName,
 			Namespace: Namespace,
 		},
 	}
@@ -495,7 +1854,7 @@ func assertClusterRoleBinding(t *testing.T, cl client.Client) {
 	assert.Equal(t, actual.RoleRef, roleRef)
 
 	// Check Service Account has dsaas-cluster-admin ClusterRole
-	dsaasClusterAdmin, err := cl.GetClusterRoleBinding(DsaasClusterAdmin)
+	dsaasClusterAdmin, err := cl.GetClusterRoleBinding(context.Background(), DsaasClusterAdmin)
 	assert.NoError(t, err, "couldn't find ClusterRoleBinding %s", DsaasClusterAdmin)
 	assert.NotNil(t, dsaasClusterAdmin)
 
@@ -511,8 +1870,8 @@ func assertClusterRoleBinding(t *testing.T, cl client.Client) {
 
 func assertOAuthClient(t *testing.T, cl client.Client) {
 	// Check OAuthClient has been created
-	actual, err := cl.GetOAuthClient(OAuthClientName)
-	assert.NoError(t, err, "couldn't find OAuthClient %s", OAuthClientName)
+	actual, err := cl.GetOAuthClient(context.Background(), DefaultOAuthClientName)
+	assert.NoError(t, err, "couldn't find OAuthClient %s", DefaultOAuthClientName)
 	assert.NotNil(t, actual)
 
 	require.NotNil(t, actual.AccessTokenMaxAgeSeconds)
@@ -536,6 +1895,30 @@ func assertClusterData(t *testing.T, data *clusterclient.CreateClusterData) {
 	assert.Equal(t, data.ServiceAccountToken, "mysatoken")
 }
 
+// memberKubeconfig returns the Secret with kubeconfig of the member cluster of the given name and API server
+func memberKubeconfig(name, server string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-kubeconfig", Namespace: Namespace},
+		Data: map[string][]byte{member.KubeconfigKey: []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: %[2]s
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: admin
+current-context: %[1]s
+users:
+- name: admin
+  user:
+    token: admin-token
+`, name, server))},
+	}
+}
+
 func newReconcileRequest(name string) reconcile.Request {
 	return reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -545,6 +1928,13 @@ func newReconcileRequest(name string) reconcile.Request {
 	}
 }
 
+func getToolChainEnabler(t *testing.T, cl client.Client) *codereadyv1alpha1.ToolChainEnabler {
+	instance := &codereadyv1alpha1.ToolChainEnabler{}
+	err := cl.Get(context.TODO(), newReconcileRequest(Name).NamespacedName, instance)
+	require.NoError(t, err)
+	return instance
+}
+
 func newConfig() ToolchainConfig {
 	return ToolchainConfig{
 		ClusterName:  "dsaas-stage",
@@ -554,3 +1944,61 @@ func newConfig() ToolchainConfig {
 		ClusterURL:   "http://cluster",
 	}
 }
+
+func TestVerificationResult(t *testing.T) {
+	t.Run("matched", func(t *testing.T) {
+		result, message := verificationResult(cluster.Verification{Found: true})
+		assert.Equal(t, codereadyv1alpha1.VerificationMatched, result)
+		assert.Empty(t, message)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		result, message := verificationResult(cluster.Verification{Reregistered: true})
+		assert.Equal(t, codereadyv1alpha1.VerificationReregistered, result)
+		assert.Equal(t, "cluster wasn't registered in cluster management service", message)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		result, message := verificationResult(cluster.Verification{Found: true, Reregistered: true, Mismatches: []string{"app-dns", "auth-client-secret"}})
+		assert.Equal(t, codereadyv1alpha1.VerificationReregistered, result)
+		assert.Equal(t, "registered cluster didn't match: app-dns, auth-client-secret", message)
+	})
+}
+
+func TestPlatform(t *testing.T) {
+	tce := &codereadyv1alpha1.ToolChainEnabler{
+		ObjectMeta: metav1.ObjectMeta{Name: Name, Namespace: Namespace},
+		Spec: codereadyv1alpha1.ToolChainEnablerSpec{
+			Kubernetes: &codereadyv1alpha1.KubernetesSpec{APIURL: "https://api.k8s.example.com:6443", IngressDomain: "apps.k8s.example.com", OIDCClientSecret: "oidc-client"},
+		},
+	}
+
+	t.Run("openshift", func(t *testing.T) {
+		//when
+		p := Platform(client.NewClient(fake.NewFakeClient()), tce, capabilities.OpenShift4, DefaultOperatorConfig())
+
+		//then
+		assert.Empty(t, p.APIURL)
+		assert.Empty(t, p.IngressDomain)
+		assert.Len(t, p.OIDC.Components(), 1)
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		//given
+		oidcSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "oidc-client", Namespace: Namespace},
+			Data:       map[string][]byte{"client-id": []byte("toolchain"), "client-secret": []byte("oidcsecret")},
+		}
+
+		//when
+		p := Platform(client.NewClient(fake.NewFakeClient(oidcSecret)), tce, capabilities.Capabilities{TokenRequest: true}, DefaultOperatorConfig())
+
+		//then
+		assert.Equal(t, "https://api.k8s.example.com:6443", p.APIURL)
+		assert.Equal(t, "apps.k8s.example.com", p.IngressDomain)
+		assert.Empty(t, p.OIDC.Components())
+		oidcClient, err := p.OIDC.Client(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "oidcsecret", oidcClient.Secret)
+	})
+}
@@ -0,0 +1,143 @@
+package election
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	errs "github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("election")
+
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// Config configures lease based leader election
+type Config struct {
+	// LockName is the name of ConfigMap holding the lease
+	LockName string
+	// Namespace is the namespace of ConfigMap holding the lease
+	Namespace string
+	// Identity is the unique identity of the replica, e.g. its pod name
+	Identity string
+	// LeaseDuration is the duration standby replicas wait before taking over leadership not renewed by the leader
+	LeaseDuration time.Duration
+	// RenewDeadline is the duration the leader retries renewing its lease before giving up leadership
+	RenewDeadline time.Duration
+	// RetryPeriod is the duration replicas wait between tries of acquiring or renewing the lease
+	RetryPeriod time.Duration
+}
+
+// Elector elects a leader among replicas of the operator. Only the leader is supposed to reconcile resources, standby
+// replicas keep their caches warm to be able to take over quickly
+type Elector struct {
+	elector *leaderelection.LeaderElector
+	mu      sync.RWMutex
+	leader  bool
+	leading chan struct{}
+}
+
+// New creates a new elector holding the lease in ConfigMap described by the given config
+func New(cfg *rest.Config, config Config) (*Elector, error) {
+	if config.LockName == "" || config.Namespace == "" || config.Identity == "" {
+		return nil, errs.New("lock name, namespace and identity are required for leader election")
+	}
+	client, err := corev1client.NewForConfig(cfg)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to create client for leader election")
+	}
+
+	e := &Elector{leading: make(chan struct{})}
+	e.elector, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.ConfigMapLock{
+			ConfigMapMeta: metav1.ObjectMeta{
+				Name:      config.LockName,
+				Namespace: config.Namespace,
+			},
+			Client: client,
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: config.Identity,
+			},
+		},
+		LeaseDuration: config.LeaseDuration,
+		RenewDeadline: config.RenewDeadline,
+		RetryPeriod:   config.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				log.Info("became the leader", "identity", config.Identity)
+				e.mu.Lock()
+				defer e.mu.Unlock()
+				e.leader = true
+				close(e.leading)
+			},
+			OnStoppedLeading: func() {
+				log.Info("stopped leading", "identity", config.Identity)
+				e.mu.Lock()
+				defer e.mu.Unlock()
+				e.leader = false
+			},
+			OnNewLeader: func(identity string) {
+				log.Info("new leader elected", "identity", identity)
+			},
+		},
+	})
+	if err != nil {
+		return nil, errs.Wrapf(err, "invalid leader election configuration")
+	}
+	return e, nil
+}
+
+// Run takes part in the election until leadership is lost or the stop channel is closed. It returns when the elector
+// stops leading, so the caller should stop reconciling and exit
+func (e *Elector) Run(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	e.elector.Run(ctx)
+}
+
+// IsLeader returns true if the elector holds leadership. Nil elector, i.e. when leader election is disabled, is always
+// the leader
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Leading returns channel closed once leadership is acquired
+func (e *Elector) Leading() <-chan struct{} {
+	if e == nil {
+		leading := make(chan struct{})
+		close(leading)
+		return leading
+	}
+	return e.leading
+}
+
+// Check fails if the elector doesn't hold leadership, so that standby replicas are reported as not ready
+func (e *Elector) Check(*http.Request) error {
+	if !e.IsLeader() {
+		return errs.New("leadership not acquired")
+	}
+	return nil
+}
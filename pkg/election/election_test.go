@@ -0,0 +1,72 @@
+package election
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestElector(t *testing.T) {
+
+	t.Run("disabled", func(t *testing.T) {
+		//given
+		var e *Elector
+
+		//then
+		assert.True(t, e.IsLeader())
+		assert.NoError(t, e.Check(nil))
+		select {
+		case <-e.Leading():
+		default:
+			assert.Fail(t, "leading channel of disabled leader election not closed")
+		}
+	})
+
+	t.Run("standby", func(t *testing.T) {
+		//when
+		e, err := New(&rest.Config{Host: "http://localhost"}, newConfig())
+
+		//then
+		require.NoError(t, err)
+		assert.False(t, e.IsLeader())
+		assert.EqualError(t, e.Check(nil), "leadership not acquired")
+	})
+
+	t.Run("missing identity", func(t *testing.T) {
+		//given
+		config := newConfig()
+		config.Identity = ""
+
+		//when
+		_, err := New(&rest.Config{Host: "http://localhost"}, config)
+
+		//then
+		assert.EqualError(t, err, "lock name, namespace and identity are required for leader election")
+	})
+
+	t.Run("renew deadline longer than lease", func(t *testing.T) {
+		//given
+		config := newConfig()
+		config.RenewDeadline = time.Minute
+
+		//when
+		_, err := New(&rest.Config{Host: "http://localhost"}, config)
+
+		//then
+		assert.Error(t, err)
+	})
+}
+
+func newConfig() Config {
+	return Config{
+		LockName:      "toolchain-enabler-lock",
+		Namespace:     "codeready-toolchain",
+		Identity:      "toolchain-enabler-1",
+		LeaseDuration: DefaultLeaseDuration,
+		RenewDeadline: DefaultRenewDeadline,
+		RetryPeriod:   DefaultRetryPeriod,
+	}
+}
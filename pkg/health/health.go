@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	errs "github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("health")
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+	// ConfigPath serves effective configuration of the operator
+	ConfigPath = "/config"
+
+	// checkTimeout is the deadline for all checks of single probe
+	checkTimeout = 3 * time.Second
+)
+
+// Checker returns an error if the checked part of the operator isn't healthy
+type Checker func(req *http.Request) error
+
+// Server serves liveness and readiness endpoints, together with additional endpoints describing the operator
+type Server struct {
+	mu          sync.RWMutex
+	liveChecks  map[string]Checker
+	readyChecks map[string]Checker
+	endpoints   map[string]http.Handler
+}
+
+// NewServer creates a new server without any checks, i.e. reporting the operator as live and ready
+func NewServer() *Server {
+	return &Server{
+		liveChecks:  map[string]Checker{},
+		readyChecks: map[string]Checker{},
+		endpoints:   map[string]http.Handler{},
+	}
+}
+
+// AddLivenessCheck adds check failing liveness probe of the operator
+func (s *Server) AddLivenessCheck(name string, check Checker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.liveChecks[name] = check
+}
+
+// AddReadinessCheck adds check failing readiness probe of the operator
+func (s *Server) AddReadinessCheck(name string, check Checker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readyChecks[name] = check
+}
+
+// AddEndpoint adds endpoint served on the given path, it has to be added before the server is started
+func (s *Server) AddEndpoint(path string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints[path] = handler
+}
+
+// Handler returns http handler serving liveness and readiness endpoints and the added ones
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, s.serve(func() map[string]Checker { return s.liveChecks }))
+	mux.HandleFunc(ReadinessPath, s.serve(func() map[string]Checker { return s.readyChecks }))
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for path, handler := range s.endpoints {
+		mux.Handle(path, handler)
+	}
+	return mux
+}
+
+// Start serves liveness and readiness endpoints on the given address until the stop channel is closed
+func (s *Server) Start(addr string, stop <-chan struct{}) error {
+	srv := &http.Server{Addr: addr, Handler: s.Handler()}
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Error(err, "failed to shutdown health server")
+		}
+	}()
+
+	log.Info("serving health probes", "address", addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) serve(checks func() map[string]Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+
+		s.mu.RLock()
+		names := make([]string, 0, len(checks()))
+		for name := range checks() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var failures []string
+		for _, name := range names {
+			if err := checks()[name](req); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %s", name, err))
+			}
+		}
+		s.mu.RUnlock()
+
+		if len(failures) > 0 {
+			log.Info("health check failed", "path", req.URL.Path, "failures", failures)
+			http.Error(w, strings.Join(failures, "\n"), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}
+}
+
+// CacheSynced returns check failing until the given cache is synced
+func CacheSynced(c cache.Cache) Checker {
+	return func(req *http.Request) error {
+		if !c.WaitForCacheSync(req.Context().Done()) {
+			return errs.New("cache not synced")
+		}
+		return nil
+	}
+}
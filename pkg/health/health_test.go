@@ -0,0 +1,115 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+
+	t.Run("without checks", func(t *testing.T) {
+		//given
+		s := NewServer()
+
+		//when
+		live := probe(s, LivenessPath)
+		ready := probe(s, ReadinessPath)
+
+		//then
+		assert.Equal(t, http.StatusOK, live.Code)
+		assert.Equal(t, http.StatusOK, ready.Code)
+	})
+
+	t.Run("failing readiness check", func(t *testing.T) {
+		//given
+		s := NewServer()
+		s.AddLivenessCheck("ok", func(*http.Request) error { return nil })
+		s.AddReadinessCheck("cache", func(*http.Request) error { return errors.New("cache not synced") })
+
+		//when
+		live := probe(s, LivenessPath)
+		ready := probe(s, ReadinessPath)
+
+		//then
+		assert.Equal(t, http.StatusOK, live.Code)
+		assert.Equal(t, http.StatusServiceUnavailable, ready.Code)
+		assert.Contains(t, ready.Body.String(), "cache: cache not synced")
+	})
+
+	t.Run("added endpoint", func(t *testing.T) {
+		//given
+		s := NewServer()
+		s.AddEndpoint(ConfigPath, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(`{"resync-period":"10m0s"}`))
+		}))
+
+		//when
+		rec := probe(s, ConfigPath)
+
+		//then
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"resync-period":"10m0s"}`, rec.Body.String())
+	})
+}
+
+func TestWatchdog(t *testing.T) {
+
+	t.Run("reconcile done", func(t *testing.T) {
+		//given
+		w := NewWatchdog(time.Minute)
+
+		//when
+		w.Start()()
+
+		//then
+		assert.NoError(t, w.Check(nil))
+	})
+
+	t.Run("reconcile running", func(t *testing.T) {
+		//given
+		w := NewWatchdog(time.Minute)
+		now := time.Now()
+		w.now = func() time.Time { return now }
+		done := w.Start()
+
+		//when
+		w.now = func() time.Time { return now.Add(30 * time.Second) }
+
+		//then
+		assert.NoError(t, w.Check(nil))
+
+		//when
+		w.now = func() time.Time { return now.Add(2 * time.Minute) }
+
+		//then
+		assert.EqualError(t, w.Check(nil), "reconcile running for 2m0s, longer than 1m0s")
+
+		//when
+		done()
+
+		//then
+		assert.NoError(t, w.Check(nil))
+	})
+
+	t.Run("nil watchdog", func(t *testing.T) {
+		//given
+		var w *Watchdog
+
+		//when
+		done := w.Start()
+
+		//then
+		done()
+	})
+}
+
+func probe(s *Server, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
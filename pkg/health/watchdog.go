@@ -0,0 +1,60 @@
+package health
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	errs "github.com/pkg/errors"
+)
+
+// Watchdog tracks running reconciles to detect a stuck reconcile worker
+type Watchdog struct {
+	mu       sync.Mutex
+	timeout  time.Duration
+	next     uint64
+	inFlight map[uint64]time.Time
+	now      func() time.Time
+}
+
+// NewWatchdog creates a new watchdog failing its check if any reconcile is running longer than the given timeout
+func NewWatchdog(timeout time.Duration) *Watchdog {
+	return &Watchdog{
+		timeout:  timeout,
+		inFlight: map[uint64]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// Start records start of a reconcile and returns function which has to be called when the reconcile is done.
+// It's safe to call it on nil watchdog
+func (w *Watchdog) Start() (done func()) {
+	if w == nil {
+		return func() {}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.next
+	w.next++
+	w.inFlight[id] = w.now()
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.inFlight, id)
+	}
+}
+
+// Check fails if any reconcile is running longer than the timeout
+func (w *Watchdog) Check(*http.Request) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+	for _, started := range w.inFlight {
+		if running := now.Sub(started); running > w.timeout {
+			return errs.Errorf("reconcile running for %s, longer than %s", running.Round(time.Second), w.timeout)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package member
+
+import (
+	"sync"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/satoken"
+	errs "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("member")
+
+// KubeconfigKey is the key of kubeconfig of member cluster in the Secret referred by ToolChainEnabler
+const KubeconfigKey = "kubeconfig"
+
+// Member is a remote cluster provisioned and registered in cluster management service by the operator running on the
+// hub cluster
+type Member struct {
+	// Client reads and writes resources of the member cluster directly, without any cache
+	Client client.Client
+	// Host is the API server URL of the member cluster
+	Host string
+	// Capabilities are APIs served by the member cluster, detected once it's connected
+	Capabilities capabilities.Capabilities
+	// Tokens provides token of toolchain-sre on the member cluster, with the mechanism detected from capabilities of
+	// its API server
+	Tokens *satoken.Provider
+	// Ping returns an error if API server of the member cluster isn't reachable
+	Ping func() error
+}
+
+// Builder builds Member from REST config of the member cluster
+type Builder func(cfg *rest.Config) (*Member, error)
+
+// Members builds members from kubeconfig Secrets and keeps them until the Secret changes, so that clients and
+// detected capabilities of member clusters are reused by reconciles
+type Members struct {
+	build   Builder
+	mu      sync.Mutex
+	members map[types.NamespacedName]cached
+}
+
+type cached struct {
+	resourceVersion string
+	member          *Member
+}
+
+// NewMembers returns Members building clients of member clusters with the given scheme
+func NewMembers(scheme *runtime.Scheme) *Members {
+	return NewMembersWithBuilder(newMember(scheme))
+}
+
+// NewMembersWithBuilder returns Members building members with the given builder, e.g. returning fake clients in tests
+func NewMembersWithBuilder(build Builder) *Members {
+	return &Members{build: build, members: map[types.NamespacedName]cached{}}
+}
+
+// Get returns member cluster of the given kubeconfig Secret. The member is built again only if the Secret has changed
+// since the last call
+func (m *Members) Get(secret *corev1.Secret) (*Member, error) {
+	key := types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.members[key]; ok && c.resourceVersion == secret.ResourceVersion {
+		return c.member, nil
+	}
+
+	kubeconfig, ok := secret.Data[KubeconfigKey]
+	if !ok {
+		return nil, errs.Errorf("secret %s doesn't contain '%s'", key, KubeconfigKey)
+	}
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load kubeconfig from secret %s", key)
+	}
+	member, err := m.build(cfg)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to connect to member cluster %s", cfg.Host)
+	}
+
+	log.Info("member cluster connected", "host", member.Host, "secret", key.String())
+	m.members[key] = cached{resourceVersion: secret.ResourceVersion, member: member}
+	return member, nil
+}
+
+// Forget drops member cluster of the given kubeconfig Secret, e.g. when it's not referred anymore
+func (m *Members) Forget(namespace, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.members, types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+func newMember(scheme *runtime.Scheme) Builder {
+	return func(cfg *rest.Config) (*Member, error) {
+		cl, err := crclient.New(cfg, crclient.Options{Scheme: scheme})
+		if err != nil {
+			return nil, err
+		}
+		cs, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		caps, err := capabilities.Detect(cs.Discovery())
+		if err != nil {
+			return nil, err
+		}
+		tokens, err := satoken.NewProvider(cfg, caps)
+		if err != nil {
+			return nil, err
+		}
+		return &Member{
+			Client:       client.NewClient(cl),
+			Host:         cfg.Host,
+			Capabilities: caps,
+			Tokens:       tokens,
+			Ping: func() error {
+				_, err := cs.Discovery().ServerVersion()
+				return err
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,90 @@
+package member
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: member
+  cluster:
+    server: https://api.member.example.com:6443
+contexts:
+- name: member
+  context:
+    cluster: member
+    user: admin
+current-context: member
+users:
+- name: admin
+  user:
+    token: secret-token
+`
+
+func TestMembers(t *testing.T) {
+	built := 0
+	members := NewMembersWithBuilder(func(cfg *rest.Config) (*Member, error) {
+		built++
+		return &Member{Host: cfg.Host}, nil
+	})
+	secret := func(resourceVersion string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "member-kubeconfig", Namespace: "codeready-toolchain", ResourceVersion: resourceVersion},
+			Data:       data,
+		}
+	}
+
+	t.Run("built once", func(t *testing.T) {
+		//when
+		first, err := members.Get(secret("1", map[string][]byte{KubeconfigKey: []byte(kubeconfig)}))
+		require.NoError(t, err)
+		second, err := members.Get(secret("1", map[string][]byte{KubeconfigKey: []byte(kubeconfig)}))
+		require.NoError(t, err)
+
+		//then
+		assert.Equal(t, "https://api.member.example.com:6443", first.Host)
+		assert.True(t, first == second, "member built again")
+		assert.Equal(t, 1, built)
+	})
+
+	t.Run("built again once secret changes", func(t *testing.T) {
+		//when
+		_, err := members.Get(secret("2", map[string][]byte{KubeconfigKey: []byte(kubeconfig)}))
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, 2, built)
+
+		//when
+		members.Forget("codeready-toolchain", "member-kubeconfig")
+		_, err = members.Get(secret("2", map[string][]byte{KubeconfigKey: []byte(kubeconfig)}))
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, 3, built)
+	})
+
+	t.Run("missing kubeconfig", func(t *testing.T) {
+		//when
+		_, err := members.Get(secret("3", map[string][]byte{}))
+
+		//then
+		assert.EqualError(t, err, "secret codeready-toolchain/member-kubeconfig doesn't contain 'kubeconfig'")
+	})
+
+	t.Run("invalid kubeconfig", func(t *testing.T) {
+		//when
+		_, err := members.Get(secret("4", map[string][]byte{KubeconfigKey: []byte("apiVersion: v1\nkind: Config\n")}))
+
+		//then
+		assert.Error(t, err)
+		assert.Equal(t, 3, built)
+	})
+}
@@ -0,0 +1,23 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+)
+
+// Client is the OIDC client auth service logs in users with the cluster, registered in cluster management service
+type Client struct {
+	ID           string
+	Secret       string
+	DefaultScope string
+}
+
+// Provisioner provisions OIDC client of the cluster. OpenShift clusters get OAuthClient created by the operator, other
+// clusters have the client registered in their identity provider beforehand
+type Provisioner interface {
+	// Components declares resources of the client ensured on the cluster, none if the client is provisioned elsewhere
+	Components() []component.Component
+	// Client returns the provisioned client
+	Client(ctx context.Context) (Client, error)
+}
@@ -0,0 +1,99 @@
+package oidc
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	"github.com/fabric8-services/toolchain-operator/pkg/secret"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	errs "github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenShiftDefaultScope is the scope of tokens auth service requests for users logged in with OAuthClient
+const OpenShiftDefaultScope = "user:full"
+
+type oauthClientProvisioner struct {
+	cl       client.Client
+	name     string
+	owner    metav1.Object
+	adoption component.Adoption
+}
+
+// NewOAuthClientProvisioner returns Provisioner of OAuthClient of the given name labeled with the given owner,
+// OAuthClient which exists and isn't owned by it is handled according to the given adoption
+func NewOAuthClientProvisioner(cl client.Client, name string, owner metav1.Object, adoption component.Adoption) Provisioner {
+	return oauthClientProvisioner{cl: cl, name: name, owner: owner, adoption: adoption}
+}
+
+func (p oauthClientProvisioner) Components() []component.Component {
+	return []component.Component{p.component()}
+}
+
+func (p oauthClientProvisioner) Client(ctx context.Context) (Client, error) {
+	oauthClient, err := p.cl.GetOAuthClient(ctx, p.name)
+	if err != nil {
+		return Client{}, err
+	}
+	return Client{ID: p.name, Secret: oauthClient.Secret, DefaultScope: OpenShiftDefaultScope}, nil
+}
+
+// component declares OAuthClient used by auth service to log in users with the cluster
+func (p oauthClientProvisioner) component() component.Component {
+	return component.Component{
+		Description: "oauthclient " + p.name,
+		Desired: func() (component.Object, error) {
+			randomString, err := secret.CreateRandomString(256)
+			if err != nil {
+				return nil, errs.Wrapf(err, "failed to generate random string to be used as secret for oauthclient")
+			}
+			var ageSeconds int32
+			return &oauthv1.OAuthClient{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: p.name,
+				},
+				Secret:                   randomString,
+				GrantMethod:              oauthv1.GrantHandlerAuto,
+				RedirectURIs:             []string{"https://auth.openshift.io/"},
+				AccessTokenMaxAgeSeconds: &ageSeconds,
+			}, nil
+		},
+		// cluster-scoped resource isn't garbage collected with namespaced owner, so it's deleted by the finalizer
+		Ownership: component.LabeledBy(p.owner),
+		Get: func(ctx context.Context) (component.Object, error) {
+			return p.cl.GetOAuthClient(ctx, p.name)
+		},
+		Create: func(ctx context.Context, obj component.Object) error {
+			return p.cl.CreateOAuthClient(ctx, obj.(*oauthv1.OAuthClient))
+		},
+		// secret registered in cluster service is never changed, only owner labels and the way users are logged in are
+		// kept in sync
+		Mutate: func(existing, desired component.Object) bool {
+			e, d := existing.(*oauthv1.OAuthClient), desired.(*oauthv1.OAuthClient)
+			changed := component.Label(e, p.owner)
+			if e.GrantMethod != d.GrantMethod {
+				e.GrantMethod = d.GrantMethod
+				changed = true
+			}
+			if !reflect.DeepEqual(e.RedirectURIs, d.RedirectURIs) {
+				e.RedirectURIs = d.RedirectURIs
+				changed = true
+			}
+			if !reflect.DeepEqual(e.AccessTokenMaxAgeSeconds, d.AccessTokenMaxAgeSeconds) {
+				e.AccessTokenMaxAgeSeconds = d.AccessTokenMaxAgeSeconds
+				changed = true
+			}
+			return changed
+		},
+		Update: func(ctx context.Context, obj component.Object) error {
+			return p.cl.Update(ctx, obj)
+		},
+		Delete: func(ctx context.Context, obj component.Object) error {
+			return p.cl.Delete(ctx, obj)
+		},
+		Owned:    component.OwnedBy(p.owner),
+		Adoption: p.adoption,
+	}
+}
@@ -0,0 +1,82 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/apis"
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const namespace = "codeready-toolchain"
+
+func TestOAuthClientProvisioner(t *testing.T) {
+	err := apis.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "toolchain", Namespace: namespace}}
+
+	t.Run("provisioned", func(t *testing.T) {
+		//given
+		cl := client.NewClient(fake.NewFakeClient())
+		p := NewOAuthClientProvisioner(cl, config.DefaultOAuthClientName, owner, component.Ignore)
+
+		//when
+		for _, c := range p.Components() {
+			require.NoError(t, component.Ensure(context.Background(), c))
+		}
+		oidcClient, err := p.Client(context.Background())
+
+		//then
+		require.NoError(t, err)
+		oauthClient, err := cl.GetOAuthClient(context.Background(), config.DefaultOAuthClientName)
+		require.NoError(t, err)
+		assert.Equal(t, component.OwnerLabels(owner), oauthClient.Labels)
+		assert.Equal(t, oauthv1.GrantHandlerAuto, oauthClient.GrantMethod)
+		assert.Equal(t, Client{ID: config.DefaultOAuthClientName, Secret: oauthClient.Secret, DefaultScope: OpenShiftDefaultScope}, oidcClient)
+		assert.NotEmpty(t, oidcClient.Secret)
+	})
+
+	t.Run("secret kept", func(t *testing.T) {
+		//given
+		existing := &oauthv1.OAuthClient{
+			ObjectMeta:  metav1.ObjectMeta{Name: config.DefaultOAuthClientName, Labels: component.OwnerLabels(owner)},
+			Secret:      "registered",
+			GrantMethod: oauthv1.GrantHandlerPrompt,
+		}
+		cl := client.NewClient(fake.NewFakeClient(existing))
+		p := NewOAuthClientProvisioner(cl, config.DefaultOAuthClientName, owner, component.Ignore)
+
+		//when
+		for _, c := range p.Components() {
+			require.NoError(t, component.Ensure(context.Background(), c))
+		}
+		oidcClient, err := p.Client(context.Background())
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, "registered", oidcClient.Secret)
+		oauthClient, err := cl.GetOAuthClient(context.Background(), config.DefaultOAuthClientName)
+		require.NoError(t, err)
+		assert.Equal(t, oauthv1.GrantHandlerAuto, oauthClient.GrantMethod)
+	})
+
+	t.Run("not provisioned yet", func(t *testing.T) {
+		//given
+		p := NewOAuthClientProvisioner(client.NewClient(fake.NewFakeClient()), config.DefaultOAuthClientName, owner, component.Ignore)
+
+		//when
+		_, err := p.Client(context.Background())
+
+		//then
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,63 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	errs "github.com/pkg/errors"
+)
+
+const (
+	// ClientIDKey is the key of the client ID in the Secret of OIDC client
+	ClientIDKey = "client-id"
+	// ClientSecretKey is the key of the client secret in the Secret of OIDC client
+	ClientSecretKey = "client-secret"
+	// DefaultScopeKey is the key of the optional default scope in the Secret of OIDC client
+	DefaultScopeKey = "default-scope"
+
+	// DefaultScope is the scope of tokens auth service requests if the Secret doesn't set any
+	DefaultScope = "openid"
+)
+
+type secretProvisioner struct {
+	cl        client.Client
+	namespace string
+	name      string
+}
+
+// NewSecretProvisioner returns Provisioner of OIDC client registered in identity provider of the cluster beforehand,
+// its credentials are read from the Secret with the given name. Client fails if the name is empty
+func NewSecretProvisioner(cl client.Client, namespace, name string) Provisioner {
+	return secretProvisioner{cl: cl, namespace: namespace, name: name}
+}
+
+// Components returns no component, the client isn't provisioned by the operator
+func (p secretProvisioner) Components() []component.Component {
+	return nil
+}
+
+func (p secretProvisioner) Client(ctx context.Context) (Client, error) {
+	if p.name == "" {
+		return Client{}, errs.New("cluster doesn't serve OAuthClients and no secret of OIDC client is set in 'kubernetes.oidcClientSecret'")
+	}
+	secret, err := p.cl.GetSecret(ctx, p.namespace, p.name)
+	if err != nil {
+		return Client{}, errs.Wrapf(err, "failed to get secret '%s' of OIDC client", p.name)
+	}
+	c := Client{
+		ID:           string(secret.Data[ClientIDKey]),
+		Secret:       string(secret.Data[ClientSecretKey]),
+		DefaultScope: string(secret.Data[DefaultScopeKey]),
+	}
+	if c.ID == "" {
+		return Client{}, errs.Errorf("'%s' is empty in secret '%s'", ClientIDKey, p.name)
+	}
+	if c.Secret == "" {
+		return Client{}, errs.Errorf("'%s' is empty in secret '%s'", ClientSecretKey, p.name)
+	}
+	if c.DefaultScope == "" {
+		c.DefaultScope = DefaultScope
+	}
+	return c, nil
+}
@@ -0,0 +1,84 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSecretProvisioner(t *testing.T) {
+	secret := func(data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "oidc-client", Namespace: namespace}, Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+
+	t.Run("default scope", func(t *testing.T) {
+		//given
+		cl := client.NewClient(fake.NewFakeClient(secret(map[string]string{ClientIDKey: "toolchain", ClientSecretKey: "oidcsecret"})))
+		p := NewSecretProvisioner(cl, namespace, "oidc-client")
+
+		//when
+		oidcClient, err := p.Client(context.Background())
+
+		//then
+		require.NoError(t, err)
+		assert.Empty(t, p.Components())
+		assert.Equal(t, Client{ID: "toolchain", Secret: "oidcsecret", DefaultScope: DefaultScope}, oidcClient)
+	})
+
+	t.Run("scope set", func(t *testing.T) {
+		//given
+		cl := client.NewClient(fake.NewFakeClient(secret(map[string]string{ClientIDKey: "toolchain", ClientSecretKey: "oidcsecret", DefaultScopeKey: "openid profile"})))
+
+		//when
+		oidcClient, err := NewSecretProvisioner(cl, namespace, "oidc-client").Client(context.Background())
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, "openid profile", oidcClient.DefaultScope)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			secretName string
+			objs       []*corev1.Secret
+			expected   string
+		}{
+			"no secret set": {
+				expected: "cluster doesn't serve OAuthClients and no secret of OIDC client is set in 'kubernetes.oidcClientSecret'",
+			},
+			"secret missing": {
+				secretName: "oidc-client",
+				expected:   `failed to get secret 'oidc-client' of OIDC client: secrets "oidc-client" not found`,
+			},
+			"client secret missing": {
+				secretName: "oidc-client",
+				objs:       []*corev1.Secret{secret(map[string]string{ClientIDKey: "toolchain"})},
+				expected:   "'client-secret' is empty in secret 'oidc-client'",
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				//given
+				cl := fake.NewFakeClient()
+				for _, obj := range tc.objs {
+					require.NoError(t, cl.Create(context.Background(), obj))
+				}
+
+				//when
+				_, err := NewSecretProvisioner(client.NewClient(cl), namespace, tc.secretName).Client(context.Background())
+
+				//then
+				assert.EqualError(t, err, tc.expected)
+			})
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package online_registration
+
+import (
+	"context"
+	"sync"
+
+	errs "github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// InfraCache is the cache for openshift-infra namespace. It's started only when online-registration is enabled, so
+// that the operator doesn't need to watch openshift-infra namespace on clusters not using online registration
+type InfraCache interface {
+	cache.Cache
+	// EnsureStarted starts the cache if it hasn't been started yet and waits until it's synced
+	EnsureStarted(ctx context.Context) error
+	// Started returns true if the cache has been started
+	Started() bool
+}
+
+type lazyCache struct {
+	cache.Cache
+	namespace string
+	stop      <-chan struct{}
+	once      sync.Once
+	mu        sync.RWMutex
+	started   bool
+}
+
+// NewInfraCache returns the given cache of the given openshift-infra namespace which is started on the first call of
+// EnsureStarted and stopped when the given stop channel is closed
+func NewInfraCache(c cache.Cache, namespace string, stop <-chan struct{}) InfraCache {
+	return &lazyCache{Cache: c, namespace: namespace, stop: stop}
+}
+
+func (c *lazyCache) EnsureStarted(ctx context.Context) error {
+	c.once.Do(func() {
+		log.Info("starting cache", "namespace", c.namespace)
+		go func() {
+			if err := c.Cache.Start(c.stop); err != nil {
+				log.Error(err, "failed to start cache", "namespace", c.namespace)
+			}
+		}()
+		c.mu.Lock()
+		c.started = true
+		c.mu.Unlock()
+	})
+
+	if !c.Cache.WaitForCacheSync(ctx.Done()) {
+		return errs.Errorf("failed to sync cache for namespace %s", c.namespace)
+	}
+	return nil
+}
+
+func (c *lazyCache) Started() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.started
+}
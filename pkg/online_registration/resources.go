@@ -3,12 +3,16 @@ package online_registration
 import (
 	"context"
 	"fmt"
+	"reflect"
+
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
 	errs "github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -18,68 +22,186 @@ var log = logf.Log.WithName("online_registration_resource_creator")
 
 const (
 	ServiceAccountName     = "online-registration"
-	Namespace              = "openshift-infra"
 	ClusterRoleBindingName = "online-registration"
 )
 
-var serviceAccount = corev1.ServiceAccount{
-	ObjectMeta: metav1.ObjectMeta{
-		Name:      ServiceAccountName,
-		Namespace: Namespace,
-	},
+// serviceAccount returns desired online-registration service account in the given namespace
+func serviceAccount(namespace string) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ServiceAccountName,
+			Namespace: namespace,
+		},
+	}
 }
 
-var clusterRoleBinding = rbacv1.ClusterRoleBinding{
-	ObjectMeta: metav1.ObjectMeta{
-		Name: ClusterRoleBindingName,
-	},
-	Subjects: []rbacv1.Subject{
-		{
-			Kind:      "ServiceAccount",
-			Name:      ServiceAccountName,
-			Namespace: Namespace,
-		},
-	},
-	RoleRef: rbacv1.RoleRef{
-		APIGroup: "rbac.authorization.k8s.io",
-		Kind:     "ClusterRole",
-		Name:     "online-registration",
-	},
+// clusterRoleBinding returns desired online-registration cluster role binding of the service account in the given
+// namespace
+func clusterRoleBinding(namespace string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ClusterRoleBindingName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      ServiceAccountName,
+				Namespace: namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     "online-registration",
+		},
+	}
+}
+
+// IsShared returns true if the given resource is the online-registration cluster role binding. It's shared by all the
+// ToolChainEnablers enabling online-registration, so it's labeled only with the one which created it and mustn't be
+// deleted together with it
+func IsShared(obj runtime.Object) bool {
+	crb, ok := obj.(*rbacv1.ClusterRoleBinding)
+	return ok && crb.Name == ClusterRoleBindingName
 }
 
-func EnsureServiceAccount(client client.Client, cache cache.Cache) error {
-	sa := &corev1.ServiceAccount{}
-	if err := cache.Get(context.Background(), types.NamespacedName{Namespace: Namespace, Name: ServiceAccountName}, sa); err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("creating a new service account ", "namespace", Namespace, "name", ServiceAccountName)
-			sa := serviceAccount
-			if err := client.CreateServiceAccount(&sa); err != nil {
-				return err
+// ServiceAccountComponent declares online-registration service account in the given namespace labeled with the given
+// ToolChainEnabler which enabled it. Its current state is read from the given cache of the namespace
+func ServiceAccountComponent(client client.Client, cache cache.Cache, namespace string, owner metav1.Object) component.Component {
+	return component.Component{
+		Description: fmt.Sprintf("service account %s from namespace %s", ServiceAccountName, namespace),
+		Desired: func() (component.Object, error) {
+			return serviceAccount(namespace), nil
+		},
+		Ownership: component.LabeledBy(owner),
+		Get: func(ctx context.Context) (component.Object, error) {
+			sa := &corev1.ServiceAccount{}
+			if err := cache.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ServiceAccountName}, sa); err != nil {
+				return nil, err
 			}
-			log.Info(fmt.Sprintf("service account %s in namespace %s created successfully", ServiceAccountName, Namespace))
-			return nil
-		}
-		return errs.Wrapf(err, "failed to get service account %s from namespace %s", ServiceAccountName, Namespace)
+			return sa, nil
+		},
+		Create: func(ctx context.Context, obj component.Object) error {
+			return client.CreateServiceAccount(ctx, obj.(*corev1.ServiceAccount))
+		},
+		Delete: func(ctx context.Context, obj component.Object) error {
+			return client.DeleteServiceAccount(ctx, obj.(*corev1.ServiceAccount))
+		},
 	}
-	log.Info(fmt.Sprintf("service account %s already exists", ServiceAccountName))
-	return nil
 }
 
-func EnsureClusterRoleBinding(client client.Client) error {
-	if _, err := client.GetClusterRoleBinding(ClusterRoleBindingName); err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("adding online-registration cluster role to", "service account", ServiceAccountName, "namespace", Namespace)
-			crb := clusterRoleBinding
-			if err := client.CreateClusterRoleBinding(&crb); err != nil {
-				return err
+// ClusterRoleBindingComponent declares online-registration cluster role binding of the service account in the given
+// namespace labeled with the given ToolChainEnabler which enabled it
+func ClusterRoleBindingComponent(client client.Client, namespace string, owner metav1.Object) component.Component {
+	return component.Component{
+		Description: "clusterrolebinding " + ClusterRoleBindingName,
+		Desired: func() (component.Object, error) {
+			return clusterRoleBinding(namespace), nil
+		},
+		Ownership: component.LabeledBy(owner),
+		Get: func(ctx context.Context) (component.Object, error) {
+			return client.GetClusterRoleBinding(ctx, ClusterRoleBindingName)
+		},
+		Create: func(ctx context.Context, obj component.Object) error {
+			return client.CreateClusterRoleBinding(ctx, obj.(*rbacv1.ClusterRoleBinding))
+		},
+		// the service account is bound in the infra namespace set in operator configuration, which may change
+		Mutate: func(existing, desired component.Object) bool {
+			e, d := existing.(*rbacv1.ClusterRoleBinding), desired.(*rbacv1.ClusterRoleBinding)
+			if reflect.DeepEqual(e.Subjects, d.Subjects) {
+				return false
 			}
+			e.Subjects = d.Subjects
+			return true
+		},
+		Update: func(ctx context.Context, obj component.Object) error {
+			return client.Update(ctx, obj)
+		},
+		Delete: func(ctx context.Context, obj component.Object) error {
+			return client.DeleteClusterRoleBinding(ctx, obj.(*rbacv1.ClusterRoleBinding))
+		},
+	}
+}
 
-			log.Info(fmt.Sprintf("clusterrolebinding %s created successfully", ClusterRoleBindingName))
-			return nil
-		}
-		return errs.Wrapf(err, "failed to get clusterrolebinding %s", ClusterRoleBindingName)
+// EnsureServiceAccount creates online-registration service account in the given namespace labeled with the given owner
+// if not exists
+func EnsureServiceAccount(ctx context.Context, client client.Client, cache cache.Cache, namespace string, owner metav1.Object) error {
+	return component.Ensure(ctx, ServiceAccountComponent(client, cache, namespace, owner))
+}
+
+// EnsureClusterRoleBinding creates online-registration cluster role binding of the service account in the given
+// namespace labeled with the given owner if not exists
+func EnsureClusterRoleBinding(ctx context.Context, client client.Client, namespace string, owner metav1.Object) error {
+	return component.Ensure(ctx, ClusterRoleBindingComponent(client, namespace, owner))
+}
+
+// Delete deletes online-registration service account in the given namespace and cluster role binding created by the
+// operator. Resources which aren't labeled with any ToolChainEnabler, e.g. as they were created before the operator
+// managed them, are kept. The given client has to read from the API server, as the cache of openshift-infra namespace
+// may not be started
+func Delete(ctx context.Context, client client.Client, namespace string) error {
+	crbDeleted, err := deleteLabeled(ctx, "clusterrolebinding "+ClusterRoleBindingName,
+		func() (component.Object, error) {
+			return client.GetClusterRoleBinding(ctx, ClusterRoleBindingName)
+		},
+		func(obj component.Object) error {
+			return client.DeleteClusterRoleBinding(ctx, obj.(*rbacv1.ClusterRoleBinding))
+		})
+	if err != nil {
+		return err
+	}
+	saDeleted, err := deleteLabeled(ctx, fmt.Sprintf("service account %s from namespace %s", ServiceAccountName, namespace),
+		func() (component.Object, error) {
+			return client.GetServiceAccount(ctx, namespace, ServiceAccountName)
+		},
+		func(obj component.Object) error {
+			return client.DeleteServiceAccount(ctx, obj.(*corev1.ServiceAccount))
+		})
+	if err != nil {
+		return err
 	}
 
-	log.Info(fmt.Sprintf("clusterrolebinding %s already exists", ClusterRoleBindingName))
+	if crbDeleted || saDeleted {
+		log.Info("online-registration resources deleted", "namespace", namespace)
+	}
 	return nil
 }
+
+// DeleteServiceAccount deletes online-registration service account created by the operator in the given namespace,
+// e.g. infra namespace which has been changed in operator configuration since. The cluster role binding is kept, it
+// binds the service account of the current infra namespace
+func DeleteServiceAccount(ctx context.Context, client client.Client, namespace string) error {
+	_, err := deleteLabeled(ctx, fmt.Sprintf("service account %s from namespace %s", ServiceAccountName, namespace),
+		func() (component.Object, error) {
+			return client.GetServiceAccount(ctx, namespace, ServiceAccountName)
+		},
+		func(obj component.Object) error {
+			return client.DeleteServiceAccount(ctx, obj.(*corev1.ServiceAccount))
+		})
+	return err
+}
+
+// deleteLabeled deletes the resource if it exists and is labeled with a ToolChainEnabler, and returns true if it has
+// been deleted. Forbidden means the operator isn't granted permissions of online-registration, so it couldn't have
+// created the resource and there is nothing to do
+func deleteLabeled(ctx context.Context, description string, get func() (component.Object, error), del func(obj component.Object) error) (bool, error) {
+	existing, err := get()
+	if err != nil {
+		if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			return false, nil
+		}
+		return false, errs.Wrapf(err, "failed to get %s", description)
+	}
+	if _, labeled := existing.GetLabels()[component.OwnerNameLabel]; !labeled {
+		log.Info(description + " isn't created by the operator, leaving it as it is")
+		return false, nil
+	}
+	if err := del(existing); err != nil {
+		if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			return false, nil
+		}
+		return false, errs.Wrapf(err, "failed to delete %s", description)
+	}
+	log.Info(description + " deleted successfully")
+	return true, nil
+}
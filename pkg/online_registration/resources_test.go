@@ -1,20 +1,27 @@
 package online_registration
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
 	"github.com/fabric8-services/toolchain-operator/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	errs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 )
 
+const infraNamespace = "openshift-infra"
+
 func TestResourceCreator(t *testing.T) {
+	owner := &metav1.ObjectMeta{Name: "toolchain-enabler", Namespace: "toolchain-enabler"}
 
 	t.Run("SA", func(t *testing.T) {
 
@@ -22,7 +29,7 @@ func TestResourceCreator(t *testing.T) {
 			//given
 			cl := client.NewClient(fake.NewFakeClient())
 			//when
-			err := EnsureServiceAccount(cl, test.NewFakeCache(errs.NewNotFound(schema.GroupResource{}, ServiceAccountName)))
+			err := EnsureServiceAccount(context.Background(), cl, test.NewFakeCache(errs.NewNotFound(schema.GroupResource{}, ServiceAccountName)), infraNamespace, owner)
 			//then
 			require.NoError(t, err, "failed to create SA %s", ServiceAccountName)
 			assertSA(t, cl)
@@ -33,12 +40,12 @@ func TestResourceCreator(t *testing.T) {
 			cl := client.NewClient(fake.NewFakeClient())
 
 			//create SA first time
-			err := EnsureServiceAccount(cl, test.NewFakeCache(errs.NewNotFound(schema.GroupResource{}, ServiceAccountName)))
+			err := EnsureServiceAccount(context.Background(), cl, test.NewFakeCache(errs.NewNotFound(schema.GroupResource{}, ServiceAccountName)), infraNamespace, owner)
 			require.NoError(t, err, "failed to create SA %s", ServiceAccountName)
 			assertSA(t, cl)
 
 			//when
-			err = EnsureServiceAccount(cl, &test.FakeCache{})
+			err = EnsureServiceAccount(context.Background(), cl, &test.FakeCache{}, infraNamespace, owner)
 
 			//then
 			require.NoError(t, err, "failed to ensure SA %s", ServiceAccountName)
@@ -51,10 +58,10 @@ func TestResourceCreator(t *testing.T) {
 			cl := client.NewClient(fake.NewFakeClient())
 
 			//when
-			err := EnsureServiceAccount(cl, test.NewFakeCache(errors.New("something went wrong")))
+			err := EnsureServiceAccount(context.Background(), cl, test.NewFakeCache(errors.New("something went wrong")), infraNamespace, owner)
 
 			//then
-			assert.EqualError(t, err, fmt.Sprintf("failed to get service account %s from namespace %s: %s", ServiceAccountName, Namespace, "something went wrong"))
+			assert.EqualError(t, err, fmt.Sprintf("failed to get service account %s from namespace %s: %s", ServiceAccountName, infraNamespace, "something went wrong"))
 		})
 
 	})
@@ -65,7 +72,7 @@ func TestResourceCreator(t *testing.T) {
 			cl := client.NewClient(fake.NewFakeClient())
 
 			//when
-			err := EnsureClusterRoleBinding(cl)
+			err := EnsureClusterRoleBinding(context.Background(), cl, infraNamespace, owner)
 
 			//then
 			require.NoError(t, err, "failed to create ClusterRoleBinding %s", ClusterRoleBindingName)
@@ -77,19 +84,33 @@ func TestResourceCreator(t *testing.T) {
 			cl := client.NewClient(fake.NewFakeClient())
 
 			//when
-			err := EnsureClusterRoleBinding(cl)
+			err := EnsureClusterRoleBinding(context.Background(), cl, infraNamespace, owner)
 
 			//then
 			require.NoError(t, err, "failed to create ClusterRoleBinding %s", ClusterRoleBindingName)
 			assertClusterRoleBinding(t, cl)
 
 			// when
-			err = EnsureClusterRoleBinding(cl)
+			err = EnsureClusterRoleBinding(context.Background(), cl, infraNamespace, owner)
 
 			require.NoError(t, err, "failed to ensure ClusterRoleBinding %s", ClusterRoleBindingName)
 			assertClusterRoleBinding(t, cl)
 		})
 
+		t.Run("infra namespace changed", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient())
+			err := EnsureClusterRoleBinding(context.Background(), cl, "old-infra", owner)
+			require.NoError(t, err, "failed to create ClusterRoleBinding %s", ClusterRoleBindingName)
+
+			//when
+			err = EnsureClusterRoleBinding(context.Background(), cl, infraNamespace, owner)
+
+			//then
+			require.NoError(t, err, "failed to update ClusterRoleBinding %s", ClusterRoleBindingName)
+			assertClusterRoleBinding(t, cl)
+		})
+
 		t.Run("fail", func(t *testing.T) {
 			//given
 			errMsg := "something went wrong while getting clusterrolebinding"
@@ -99,24 +120,130 @@ func TestResourceCreator(t *testing.T) {
 			cl := test.NewDummyClient(client.NewClient(fake.NewFakeClient()), m)
 
 			//when
-			err := EnsureClusterRoleBinding(cl)
+			err := EnsureClusterRoleBinding(context.Background(), cl, infraNamespace, owner)
 
 			//then
 			assert.EqualError(t, err, fmt.Sprintf("failed to get clusterrolebinding %s: %s", ClusterRoleBindingName, errMsg))
 		})
 	})
+
+	t.Run("Delete", func(t *testing.T) {
+		t.Run("exists", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient())
+			err := EnsureServiceAccount(context.Background(), cl, test.NewFakeCache(errs.NewNotFound(schema.GroupResource{}, ServiceAccountName)), infraNamespace, owner)
+			require.NoError(t, err)
+			err = EnsureClusterRoleBinding(context.Background(), cl, infraNamespace, owner)
+			require.NoError(t, err)
+
+			sa, err := cl.GetServiceAccount(context.Background(), infraNamespace, ServiceAccountName)
+			require.NoError(t, err)
+			assert.Equal(t, component.OwnerLabels(owner), sa.Labels)
+
+			//when
+			err = Delete(context.Background(), cl, infraNamespace)
+
+			//then
+			require.NoError(t, err)
+			_, err = cl.GetServiceAccount(context.Background(), infraNamespace, ServiceAccountName)
+			assert.True(t, errs.IsNotFound(err), "sa %s not deleted", ServiceAccountName)
+			_, err = cl.GetClusterRoleBinding(context.Background(), ClusterRoleBindingName)
+			assert.True(t, errs.IsNotFound(err), "clusterrolebinding %s not deleted", ClusterRoleBindingName)
+		})
+
+		t.Run("not created by operator", func(t *testing.T) {
+			//given
+			sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: ServiceAccountName, Namespace: infraNamespace}}
+			crb := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleBindingName}}
+			cl := client.NewClient(fake.NewFakeClient(sa, crb))
+
+			//when
+			err := Delete(context.Background(), cl, infraNamespace)
+
+			//then
+			require.NoError(t, err)
+			_, err = cl.GetServiceAccount(context.Background(), infraNamespace, ServiceAccountName)
+			assert.NoError(t, err, "sa %s deleted", ServiceAccountName)
+			_, err = cl.GetClusterRoleBinding(context.Background(), ClusterRoleBindingName)
+			assert.NoError(t, err, "clusterrolebinding %s deleted", ClusterRoleBindingName)
+		})
+
+		t.Run("not exists", func(t *testing.T) {
+			//given
+			cl := client.NewClient(fake.NewFakeClient())
+
+			//when
+			err := Delete(context.Background(), cl, infraNamespace)
+
+			//then
+			require.NoError(t, err)
+		})
+
+		t.Run("forbidden", func(t *testing.T) {
+			//given
+			cl := forbiddenClient{client.NewClient(fake.NewFakeClient())}
+
+			//when
+			err := Delete(context.Background(), cl, infraNamespace)
+
+			//then
+			require.NoError(t, err)
+		})
+
+		t.Run("fail", func(t *testing.T) {
+			//given
+			cl := test.NewDummyClient(client.NewClient(fake.NewFakeClient()), map[string]string{"crb": "something went wrong"})
+
+			//when
+			err := Delete(context.Background(), cl, infraNamespace)
+
+			//then
+			assert.EqualError(t, err, fmt.Sprintf("failed to get clusterrolebinding %s: something went wrong", ClusterRoleBindingName))
+		})
+	})
+
+	t.Run("DeleteServiceAccount", func(t *testing.T) {
+		//given
+		labels := component.OwnerLabels(owner)
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: ServiceAccountName, Namespace: "old-infra", Labels: labels}}
+		crb := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleBindingName, Labels: labels}}
+		cl := client.NewClient(fake.NewFakeClient(sa, crb))
+
+		//when
+		err := DeleteServiceAccount(context.Background(), cl, "old-infra")
+
+		//then
+		require.NoError(t, err)
+		_, err = cl.GetServiceAccount(context.Background(), "old-infra", ServiceAccountName)
+		assert.True(t, errs.IsNotFound(err), "sa not deleted")
+		_, err = cl.GetClusterRoleBinding(context.Background(), ClusterRoleBindingName)
+		assert.NoError(t, err, "clusterrolebinding deleted")
+	})
+}
+
+// forbiddenClient denies reading online-registration resources, as if the operator wasn't granted its permissions
+type forbiddenClient struct {
+	client.Client
+}
+
+func (c forbiddenClient) GetServiceAccount(ctx context.Context, namespace, name string) (*corev1.ServiceAccount, error) {
+	return nil, errs.NewForbidden(schema.GroupResource{Resource: "serviceaccounts"}, name, errors.New("forbidden"))
+}
+
+func (c forbiddenClient) GetClusterRoleBinding(ctx context.Context, name string) (*rbacv1.ClusterRoleBinding, error) {
+	return nil, errs.NewForbidden(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}, name, errors.New("forbidden"))
 }
 
 func assertSA(t *testing.T, cl client.Client) {
 	// Check if service account has been created
-	sa, err := cl.GetServiceAccount(Namespace, ServiceAccountName)
-	assert.NoError(t, err, "couldn't find created sa %s in namespace %s", ServiceAccountName, Namespace)
+	sa, err := cl.GetServiceAccount(context.Background(), infraNamespace, ServiceAccountName)
+	assert.NoError(t, err, "couldn't find created sa %s in namespace %s", ServiceAccountName, infraNamespace)
 	assert.NotNil(t, sa)
 }
 
 func assertClusterRoleBinding(t *testing.T, cl client.Client) {
 	// Check service account has online-registration clusterrole
-	actual, err := cl.GetClusterRoleBinding(ClusterRoleBindingName)
+	actual, err := cl.GetClusterRoleBinding(context.Background(), ClusterRoleBindingName)
 	assert.NoError(t, err, "couldn't find ClusterRoleBinding %s", ClusterRoleBindingName)
 	assert.NotNil(t, actual)
 
@@ -124,7 +251,7 @@ func assertClusterRoleBinding(t *testing.T, cl client.Client) {
 		{
 			Kind:      "ServiceAccount",
 			Name:      ServiceAccountName,
-			Namespace: Namespace,
+			Namespace: infraNamespace,
 		},
 	}
 
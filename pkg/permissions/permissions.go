@@ -0,0 +1,211 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	errs "github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Permission is a verb on a resource, cluster wide if namespace is empty
+type Permission struct {
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+}
+
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource = fmt.Sprintf("%s.%s", p.Resource, p.Group)
+	}
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in %s", p.Verb, resource, p.Namespace)
+}
+
+// manage returns permissions to fully manage the given resource
+func manage(group, resource string) []Permission {
+	return verbs(group, resource, "", "create", "get", "list", "update", "delete")
+}
+
+// verbs returns permissions for the given verbs on the resource
+func verbs(group, resource, namespace string, verbs ...string) []Permission {
+	var permissions []Permission
+	for _, verb := range verbs {
+		permissions = append(permissions, Permission{Verb: verb, Group: group, Resource: resource, Namespace: namespace})
+	}
+	return permissions
+}
+
+// Required lists permissions the toolchain needs on the cluster: it creates projects of users and manages their
+// oauthclients, limitranges, resourcequotas and rolebindingrestrictions
+var Required = func() []Permission {
+	permissions := []Permission{{Verb: "create", Group: "project.openshift.io", Resource: "projectrequests"}}
+	permissions = append(permissions, manage("oauth.openshift.io", "oauthclients")...)
+	permissions = append(permissions, manage("", "limitranges")...)
+	permissions = append(permissions, manage("", "resourcequotas")...)
+	permissions = append(permissions, manage("authorization.openshift.io", "rolebindingrestrictions")...)
+	return permissions
+}()
+
+// RequiredOnKubernetes lists permissions the toolchain needs on a cluster serving no OpenShift API: it creates
+// namespaces of users and manages their limitranges and resourcequotas
+var RequiredOnKubernetes = func() []Permission {
+	permissions := []Permission{{Verb: "create", Resource: "namespaces"}}
+	permissions = append(permissions, manage("", "limitranges")...)
+	permissions = append(permissions, manage("", "resourcequotas")...)
+	return permissions
+}()
+
+// RequiredOn returns permissions the toolchain needs on a cluster of the given platform
+func RequiredOn(platform capabilities.Platform) []Permission {
+	if platform == capabilities.PlatformKubernetes {
+		return RequiredOnKubernetes
+	}
+	return Required
+}
+
+// Feature is an optional feature of ToolChainEnabler which requires additional permissions of the operator
+type Feature string
+
+const (
+	// OnlineRegistration manages online-registration service account in openshift-infra namespace
+	OnlineRegistration Feature = "online-registration"
+	// ProjectTemplate manages project request template in openshift-config namespace
+	ProjectTemplate Feature = "project-template"
+	// Capacity computes capacity of the cluster from its nodes, pods and projects
+	Capacity Feature = "capacity"
+	// Adoption takes ownership of existing Service Account which isn't owned by the operator
+	Adoption Feature = "adoption"
+)
+
+// AllFeatures lists all the optional features of ToolChainEnabler
+var AllFeatures = []Feature{OnlineRegistration, ProjectTemplate, Capacity, Adoption}
+
+// Operator lists permissions the operator itself needs to reconcile ToolChainEnablers in the given namespace with the
+// given optional features enabled on a cluster of the given platform
+func Operator(namespace, infraNamespace string, platform capabilities.Platform, features ...Feature) []Permission {
+	var permissions []Permission
+	permissions = append(permissions, verbs("codeready.openshift.io", "toolchainenablers", namespace, "get", "list", "watch", "update")...)
+	permissions = append(permissions, verbs("codeready.openshift.io", "toolchainenablers/status", namespace, "update")...)
+	permissions = append(permissions, verbs("", "serviceaccounts", namespace, "create", "get", "list", "watch")...)
+	permissions = append(permissions, verbs("", "serviceaccounts/token", namespace, "create")...)
+	permissions = append(permissions, verbs("", "secrets", namespace, "create", "get", "list", "watch", "update")...)
+	permissions = append(permissions, verbs("", "configmaps", namespace, "get", "list", "watch")...)
+	permissions = append(permissions, verbs("rbac.authorization.k8s.io", "clusterrolebindings", "", "create", "get", "list", "watch", "update", "delete")...)
+	permissions = append(permissions, verbs("authorization.k8s.io", "subjectaccessreviews", "", "create")...)
+	// app DNS of Kubernetes is set in spec and its OIDC client is provisioned outside of the cluster, so no more
+	// permissions are needed there
+	if platform != capabilities.PlatformKubernetes {
+		permissions = append(permissions, verbs("route.openshift.io", "routes", namespace, "create", "delete")...)
+		permissions = append(permissions, verbs("oauth.openshift.io", "oauthclients", "", "create", "get", "list", "watch", "update", "delete")...)
+		permissions = append(permissions, verbs("config.openshift.io", "infrastructures", "", "get", "list", "watch")...)
+		permissions = append(permissions, verbs("config.openshift.io", "ingresses", "", "get", "list", "watch")...)
+	}
+	for _, feature := range features {
+		permissions = append(permissions, ForFeature(namespace, infraNamespace, feature)...)
+	}
+	return permissions
+}
+
+// ForFeature lists the additional permissions the operator needs to reconcile ToolChainEnablers in the given namespace
+// with the given optional feature enabled
+func ForFeature(namespace, infraNamespace string, feature Feature) []Permission {
+	switch feature {
+	case OnlineRegistration:
+		return verbs("", "serviceaccounts", infraNamespace, "create", "get", "list", "watch", "delete")
+	case ProjectTemplate:
+		return append(verbs("template.openshift.io", "templates", "openshift-config", "create", "get", "update", "delete"),
+			verbs("config.openshift.io", "projects", "", "get", "update")...)
+	case Capacity:
+		var permissions []Permission
+		permissions = append(permissions, verbs("", "nodes", "", "list")...)
+		permissions = append(permissions, verbs("", "pods", "", "list")...)
+		return append(permissions, verbs("", "namespaces", "", "list")...)
+	case Adoption:
+		return verbs("", "serviceaccounts", namespace, "update")
+	}
+	return nil
+}
+
+// ServiceAccountUser returns user name and groups of the given Service Account as seen by API server
+func ServiceAccountUser(namespace, name string) (string, []string) {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+		[]string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"}
+}
+
+// Missing returns the given permissions which aren't granted to the user
+func Missing(ctx context.Context, cl client.Client, user string, groups []string, permissions []Permission) ([]Permission, error) {
+	return missing(permissions, func(p Permission) (bool, error) {
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:               user,
+				Groups:             groups,
+				ResourceAttributes: resourceAttributes(p),
+			},
+		}
+		if err := cl.Create(ctx, review); err != nil {
+			return false, errs.Wrapf(err, "failed to review permission '%s' of %s", p, user)
+		}
+		return review.Status.Allowed, nil
+	})
+}
+
+// MissingForSelf returns the given permissions which aren't granted to the operator itself
+func MissingForSelf(ctx context.Context, cl client.Client, permissions []Permission) ([]Permission, error) {
+	return missing(permissions, func(p Permission) (bool, error) {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: resourceAttributes(p),
+			},
+		}
+		if err := cl.Create(ctx, review); err != nil {
+			return false, errs.Wrapf(err, "failed to review permission '%s' of the operator", p)
+		}
+		return review.Status.Allowed, nil
+	})
+}
+
+func missing(permissions []Permission, allowed func(p Permission) (bool, error)) ([]Permission, error) {
+	var missing []Permission
+	for _, p := range permissions {
+		ok, err := allowed(p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+func resourceAttributes(p Permission) *authorizationv1.ResourceAttributes {
+	resource, subresource := p.Resource, ""
+	if i := strings.Index(p.Resource, "/"); i >= 0 {
+		resource, subresource = p.Resource[:i], p.Resource[i+1:]
+	}
+	return &authorizationv1.ResourceAttributes{
+		Namespace:   p.Namespace,
+		Verb:        p.Verb,
+		Group:       p.Group,
+		Resource:    resource,
+		Subresource: subresource,
+	}
+}
+
+// Join returns comma separated list of the given permissions
+func Join(permissions []Permission) string {
+	names := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		names = append(names, p.String())
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,166 @@
+package permissions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// reviewingClient allows all the reviewed permissions except the denied resources
+type reviewingClient struct {
+	client.Client
+	denied map[string]bool
+}
+
+func (c reviewingClient) Create(ctx context.Context, obj runtime.Object) error {
+	switch review := obj.(type) {
+	case *authorizationv1.SubjectAccessReview:
+		review.Status.Allowed = !c.denied[review.Spec.ResourceAttributes.Resource]
+		return nil
+	case *authorizationv1.SelfSubjectAccessReview:
+		review.Status.Allowed = !c.denied[review.Spec.ResourceAttributes.Resource]
+		return nil
+	}
+	return c.Client.Create(ctx, obj)
+}
+
+func TestMissing(t *testing.T) {
+	user, groups := ServiceAccountUser("codeready-toolchain", "toolchain-sre")
+
+	t.Run("all granted", func(t *testing.T) {
+		//given
+		cl := reviewingClient{Client: fake.NewFakeClient()}
+
+		//when
+		missing, err := Missing(context.Background(), cl, user, groups, Required)
+
+		//then
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		//given
+		cl := reviewingClient{Client: fake.NewFakeClient(), denied: map[string]bool{"projectrequests": true}}
+
+		//when
+		missing, err := Missing(context.Background(), cl, user, groups, Required)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, []Permission{{Verb: "create", Group: "project.openshift.io", Resource: "projectrequests"}}, missing)
+		assert.Equal(t, "create projectrequests.project.openshift.io", missing[0].String())
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		//given
+		cl := reviewingClient{Client: fake.NewFakeClient(), denied: map[string]bool{"projectrequests": true, "oauthclients": true, "rolebindingrestrictions": true}}
+
+		//when
+		missing, err := Missing(context.Background(), cl, user, groups, RequiredOn(capabilities.PlatformKubernetes))
+
+		//then
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, Required, RequiredOn(capabilities.PlatformOpenShift))
+	})
+
+	t.Run("service account user", func(t *testing.T) {
+		assert.Equal(t, "system:serviceaccount:codeready-toolchain:toolchain-sre", user)
+		assert.Contains(t, groups, "system:serviceaccounts:codeready-toolchain")
+	})
+}
+
+func TestMissingForSelf(t *testing.T) {
+
+	t.Run("all granted", func(t *testing.T) {
+		//given
+		cl := reviewingClient{Client: fake.NewFakeClient()}
+
+		//when
+		missing, err := MissingForSelf(context.Background(), cl, Operator("codeready-toolchain", "openshift-infra", capabilities.PlatformOpenShift, AllFeatures...))
+
+		//then
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	t.Run("all missing reported", func(t *testing.T) {
+		//given
+		cl := reviewingClient{Client: fake.NewFakeClient(), denied: map[string]bool{"routes": true, "infrastructures": true}}
+
+		//when
+		missing, err := MissingForSelf(context.Background(), cl, Operator("codeready-toolchain", "openshift-infra", capabilities.PlatformOpenShift))
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, "create routes.route.openshift.io in codeready-toolchain, delete routes.route.openshift.io in codeready-toolchain, get infrastructures.config.openshift.io, list infrastructures.config.openshift.io, watch infrastructures.config.openshift.io", Join(missing))
+	})
+
+	t.Run("no openshift api on kubernetes", func(t *testing.T) {
+		//given
+		cl := reviewingClient{Client: fake.NewFakeClient(), denied: map[string]bool{"routes": true, "oauthclients": true, "infrastructures": true, "ingresses": true}}
+
+		//when
+		missing, err := MissingForSelf(context.Background(), cl, Operator("codeready-toolchain", "openshift-infra", capabilities.PlatformKubernetes))
+
+		//then
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	t.Run("status subresource", func(t *testing.T) {
+		//when
+		attributes := resourceAttributes(Permission{Verb: "update", Group: "codeready.openshift.io", Resource: "toolchainenablers/status"})
+
+		//then
+		assert.Equal(t, "toolchainenablers", attributes.Resource)
+		assert.Equal(t, "status", attributes.Subresource)
+	})
+}
+
+func TestForFeature(t *testing.T) {
+
+	t.Run("operator with features", func(t *testing.T) {
+		//when
+		withFeatures := Operator("codeready-toolchain", "openshift-infra", capabilities.PlatformOpenShift, AllFeatures...)
+
+		//then
+		expected := Operator("codeready-toolchain", "openshift-infra", capabilities.PlatformOpenShift)
+		for _, feature := range AllFeatures {
+			expected = append(expected, ForFeature("codeready-toolchain", "openshift-infra", feature)...)
+		}
+		assert.Equal(t, expected, withFeatures)
+	})
+
+	t.Run("online-registration", func(t *testing.T) {
+		//when
+		permissions := ForFeature("codeready-toolchain", "openshift-infra", OnlineRegistration)
+
+		//then
+		assert.Equal(t, "create serviceaccounts in openshift-infra, get serviceaccounts in openshift-infra, list serviceaccounts in openshift-infra, watch serviceaccounts in openshift-infra, delete serviceaccounts in openshift-infra", Join(permissions))
+	})
+
+	t.Run("adoption", func(t *testing.T) {
+		//when
+		permissions := ForFeature("codeready-toolchain", "openshift-infra", Adoption)
+
+		//then
+		assert.Equal(t, "update serviceaccounts in codeready-toolchain", Join(permissions))
+	})
+
+	t.Run("unknown feature", func(t *testing.T) {
+		//when
+		permissions := ForFeature("codeready-toolchain", "openshift-infra", Feature("unknown"))
+
+		//then
+		assert.Empty(t, permissions)
+	})
+}
@@ -0,0 +1,82 @@
+package permissions
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reviews caches missing permissions reviewed for each owner, e.g. ToolChainEnabler, so that a reconcile doesn't issue
+// an access review per required permission every time. Permissions are reviewed again once the cached result is older
+// than the given period, or the reviewed user or permissions change. Nil Reviews doesn't cache anything
+type Reviews struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entries map[string]cachedReview
+}
+
+type cachedReview struct {
+	fingerprint string
+	reviewed    time.Time
+	missing     []Permission
+}
+
+// NewReviews returns empty cache of permission reviews
+func NewReviews() *Reviews {
+	return &Reviews{now: time.Now, entries: map[string]cachedReview{}}
+}
+
+// Missing returns the given permissions which aren't granted to the user, reviewed at most once per the given period
+// for the given owner
+func (r *Reviews) Missing(ctx context.Context, cl client.Client, owner string, period time.Duration, user string, groups []string, permissions []Permission) ([]Permission, error) {
+	return r.get(owner+" "+user, user+" "+strings.Join(groups, ",")+" "+Join(permissions), period, func() ([]Permission, error) {
+		return Missing(ctx, cl, user, groups, permissions)
+	})
+}
+
+// MissingForSelf returns the given permissions which aren't granted to the operator itself, reviewed at most once per
+// the given period for the given owner
+func (r *Reviews) MissingForSelf(ctx context.Context, cl client.Client, owner string, period time.Duration, permissions []Permission) ([]Permission, error) {
+	return r.get(owner, Join(permissions), period, func() ([]Permission, error) {
+		return MissingForSelf(ctx, cl, permissions)
+	})
+}
+
+// Forget drops reviews cached for the given owner, it's called once the owner is deleted
+func (r *Reviews) Forget(owner string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.entries {
+		if key == owner || strings.HasPrefix(key, owner+" ") {
+			delete(r.entries, key)
+		}
+	}
+}
+
+func (r *Reviews) get(key, fingerprint string, period time.Duration, review func() ([]Permission, error)) ([]Permission, error) {
+	if r == nil {
+		return review()
+	}
+	r.mu.Lock()
+	cached, found := r.entries[key]
+	r.mu.Unlock()
+	if found && cached.fingerprint == fingerprint && r.now().Before(cached.reviewed.Add(period)) {
+		return cached.missing, nil
+	}
+
+	reviewed := r.now()
+	missing, err := review()
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[key] = cachedReview{fingerprint: fingerprint, reviewed: reviewed, missing: missing}
+	return missing, nil
+}
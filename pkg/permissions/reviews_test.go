@@ -0,0 +1,145 @@
+package permissions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// countingClient counts access reviews created through it
+type countingClient struct {
+	reviewingClient
+	created int
+}
+
+func (c *countingClient) Create(ctx context.Context, obj runtime.Object) error {
+	c.created++
+	return c.reviewingClient.Create(ctx, obj)
+}
+
+func TestReviews(t *testing.T) {
+	required := Operator("codeready-toolchain", "openshift-infra", capabilities.PlatformOpenShift)
+	user, groups := ServiceAccountUser("codeready-toolchain", "toolchain-sre")
+
+	newReviews := func(now *time.Time) *Reviews {
+		reviews := NewReviews()
+		reviews.now = func() time.Time { return *now }
+		return reviews
+	}
+
+	t.Run("cached within period", func(t *testing.T) {
+		//given
+		now := time.Now()
+		reviews := newReviews(&now)
+		cl := &countingClient{reviewingClient: reviewingClient{Client: fake.NewFakeClient(), denied: map[string]bool{"routes": true}}}
+		first, err := reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+		require.NoError(t, err)
+		created := cl.created
+
+		//when
+		now = now.Add(59 * time.Second)
+		second, err := reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, len(required), created)
+		assert.Equal(t, created, cl.created, "permissions reviewed again within period")
+		assert.Equal(t, first, second)
+		assert.NotEmpty(t, second)
+	})
+
+	t.Run("reviewed again after period", func(t *testing.T) {
+		//given
+		now := time.Now()
+		reviews := newReviews(&now)
+		cl := &countingClient{reviewingClient: reviewingClient{Client: fake.NewFakeClient(), denied: map[string]bool{"routes": true}}}
+		_, err := reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+		require.NoError(t, err)
+
+		//when
+		cl.denied = nil
+		now = now.Add(time.Minute)
+		missing, err := reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+
+		//then
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, 2*len(required), cl.created)
+	})
+
+	t.Run("reviewed again once permissions change", func(t *testing.T) {
+		//given
+		now := time.Now()
+		reviews := newReviews(&now)
+		cl := &countingClient{reviewingClient: reviewingClient{Client: fake.NewFakeClient()}}
+		_, err := reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+		require.NoError(t, err)
+
+		//when
+		withFeature := Operator("codeready-toolchain", "openshift-infra", capabilities.PlatformOpenShift, OnlineRegistration)
+		_, err = reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, withFeature)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, len(required)+len(withFeature), cl.created)
+	})
+
+	t.Run("cached per owner and user", func(t *testing.T) {
+		//given
+		now := time.Now()
+		reviews := newReviews(&now)
+		cl := &countingClient{reviewingClient: reviewingClient{Client: fake.NewFakeClient()}}
+		_, err := reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+		require.NoError(t, err)
+
+		//when
+		_, err = reviews.MissingForSelf(context.Background(), cl, "ns/other", time.Minute, required)
+		require.NoError(t, err)
+		_, err = reviews.Missing(context.Background(), cl, "ns/tce", time.Minute, user, groups, Required)
+		require.NoError(t, err)
+		_, err = reviews.Missing(context.Background(), cl, "ns/tce", time.Minute, user, groups, Required)
+		require.NoError(t, err)
+
+		//then
+		assert.Equal(t, 2*len(required)+len(Required), cl.created)
+	})
+
+	t.Run("forgotten", func(t *testing.T) {
+		//given
+		now := time.Now()
+		reviews := newReviews(&now)
+		cl := &countingClient{reviewingClient: reviewingClient{Client: fake.NewFakeClient()}}
+		_, err := reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+		require.NoError(t, err)
+		_, err = reviews.Missing(context.Background(), cl, "ns/tce", time.Minute, user, groups, Required)
+		require.NoError(t, err)
+
+		//when
+		reviews.Forget("ns/tce")
+
+		//then
+		assert.Empty(t, reviews.entries)
+	})
+
+	t.Run("nil doesn't cache", func(t *testing.T) {
+		//given
+		var reviews *Reviews
+		cl := &countingClient{reviewingClient: reviewingClient{Client: fake.NewFakeClient()}}
+
+		//when
+		_, err := reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+		require.NoError(t, err)
+		_, err = reviews.MissingForSelf(context.Background(), cl, "ns/tce", time.Minute, required)
+		require.NoError(t, err)
+		reviews.Forget("ns/tce")
+
+		//then
+		assert.Equal(t, 2*len(required), cl.created)
+	})
+}
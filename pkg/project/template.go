@@ -0,0 +1,244 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/fabric8-services/toolchain-operator/pkg/component"
+	configv1 "github.com/openshift/api/config/v1"
+	projectv1 "github.com/openshift/api/project/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+	errs "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("project_template")
+
+const (
+	// TemplateName is the name of project request template managed by the operator
+	TemplateName = "project-request"
+	// Namespace is the namespace of project request template referred by project configuration of the cluster
+	Namespace = "openshift-config"
+	// ConfigName is the name of project configuration of the cluster
+	ConfigName = "cluster"
+
+	// Finalizer holds back deletion of ToolChainEnabler until project request template is removed from the cluster
+	Finalizer = "codeready.openshift.io/project-template"
+)
+
+// parameters of project request template, filled in by the API server when user requests a project
+var parameters = []templatev1.Parameter{
+	{Name: "PROJECT_NAME"},
+	{Name: "PROJECT_DISPLAYNAME"},
+	{Name: "PROJECT_DESCRIPTION"},
+	{Name: "PROJECT_ADMIN_USER"},
+	{Name: "PROJECT_REQUESTING_USER"},
+}
+
+// Template renders the given spec into project request template. Besides the default project and admin role binding
+// of the requesting user, it contains the configured quota, limit range and network policies
+func Template(spec *codereadyv1alpha1.ProjectTemplateSpec) (*templatev1.Template, error) {
+	if spec == nil {
+		spec = &codereadyv1alpha1.ProjectTemplateSpec{}
+	}
+	objects := []runtime.Object{
+		&projectv1.Project{
+			TypeMeta: metav1.TypeMeta{APIVersion: "project.openshift.io/v1", Kind: "Project"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "${PROJECT_NAME}",
+				Annotations: map[string]string{
+					"openshift.io/description":  "${PROJECT_DESCRIPTION}",
+					"openshift.io/display-name": "${PROJECT_DISPLAYNAME}",
+					"openshift.io/requester":    "${PROJECT_REQUESTING_USER}",
+				},
+			},
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta: metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "admin",
+				Namespace: "${PROJECT_NAME}",
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "ClusterRole",
+				Name:     "admin",
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					APIGroup: "rbac.authorization.k8s.io",
+					Kind:     "User",
+					Name:     "${PROJECT_ADMIN_USER}",
+				},
+			},
+		},
+	}
+	if spec.ResourceQuota != nil {
+		objects = append(objects, &corev1.ResourceQuota{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ResourceQuota"},
+			ObjectMeta: metav1.ObjectMeta{Name: "default-quota", Namespace: "${PROJECT_NAME}"},
+			Spec:       *spec.ResourceQuota,
+		})
+	}
+	if spec.LimitRange != nil {
+		objects = append(objects, &corev1.LimitRange{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "LimitRange"},
+			ObjectMeta: metav1.ObjectMeta{Name: "default-limits", Namespace: "${PROJECT_NAME}"},
+			Spec:       *spec.LimitRange,
+		})
+	}
+	for _, policy := range spec.NetworkPolicies {
+		objects = append(objects, &networkingv1.NetworkPolicy{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+			ObjectMeta: metav1.ObjectMeta{Name: policy.Name, Namespace: "${PROJECT_NAME}"},
+			Spec:       policy.Spec,
+		})
+	}
+
+	template := &templatev1.Template{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TemplateName,
+			Namespace: Namespace,
+		},
+		Parameters: parameters,
+	}
+	for _, obj := range objects {
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to render %s of project request template", obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+		template.Objects = append(template.Objects, runtime.RawExtension{Raw: raw})
+	}
+	return template, nil
+}
+
+// TemplateComponent declares project request template rendered from the given spec. It's shared by the cluster, so
+// it's left without owner and removed by Delete
+func TemplateComponent(cl client.Client, spec *codereadyv1alpha1.ProjectTemplateSpec) component.Component {
+	return component.Component{
+		Description: fmt.Sprintf("template %s from namespace %s", TemplateName, Namespace),
+		Desired: func() (component.Object, error) {
+			return Template(spec)
+		},
+		Ownership: component.Unowned,
+		Get: func(ctx context.Context) (component.Object, error) {
+			template := &templatev1.Template{}
+			if err := cl.Get(ctx, types.NamespacedName{Namespace: Namespace, Name: TemplateName}, template); err != nil {
+				return nil, err
+			}
+			return template, nil
+		},
+		Create: func(ctx context.Context, obj component.Object) error {
+			return cl.Create(ctx, obj)
+		},
+		// objects edited by hand are reverted to the rendered ones
+		Mutate: func(existing, desired component.Object) bool {
+			e, d := existing.(*templatev1.Template), desired.(*templatev1.Template)
+			if sameObjects(e.Objects, d.Objects) && reflect.DeepEqual(e.Parameters, d.Parameters) {
+				return false
+			}
+			log.Info("project request template has drifted from the spec")
+			e.Objects = d.Objects
+			e.Parameters = d.Parameters
+			return true
+		},
+		Update: func(ctx context.Context, obj component.Object) error {
+			return cl.Update(ctx, obj)
+		},
+		Delete: func(ctx context.Context, obj component.Object) error {
+			return cl.Delete(ctx, obj)
+		},
+	}
+}
+
+// SameTemplate returns true if the given specs render the same project request template
+func SameTemplate(spec, other *codereadyv1alpha1.ProjectTemplateSpec) bool {
+	t, err := Template(spec)
+	if err != nil {
+		return false
+	}
+	o, err := Template(other)
+	if err != nil {
+		return false
+	}
+	return sameObjects(t.Objects, o.Objects)
+}
+
+// sameObjects compares objects of templates semantically, regardless of formatting of their JSON
+func sameObjects(existing, desired []runtime.RawExtension) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for i := range existing {
+		var e, d interface{}
+		if err := json.Unmarshal(existing[i].Raw, &e); err != nil {
+			return false
+		}
+		if err := json.Unmarshal(desired[i].Raw, &d); err != nil {
+			return false
+		}
+		if !reflect.DeepEqual(e, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// Ensure creates or updates project request template rendered from the given spec and points project configuration of
+// the cluster at it, if the cluster serves it
+func Ensure(ctx context.Context, cl client.Client, spec *codereadyv1alpha1.ProjectTemplateSpec, caps capabilities.Capabilities) error {
+	if err := component.Ensure(ctx, TemplateComponent(cl, spec)); err != nil {
+		return err
+	}
+	return setConfigTemplate(ctx, cl, TemplateName, caps)
+}
+
+// Delete unsets project request template in project configuration of the cluster, if it's the one managed by the
+// operator, and deletes the template
+func Delete(ctx context.Context, cl client.Client, caps capabilities.Capabilities) error {
+	if err := setConfigTemplate(ctx, cl, "", caps); err != nil {
+		return err
+	}
+	return component.Delete(ctx, TemplateComponent(cl, nil))
+}
+
+// setConfigTemplate sets the given project request template in project configuration of the cluster. The template
+// set by the cluster admin is never unset
+func setConfigTemplate(ctx context.Context, cl client.Client, name string, caps capabilities.Capabilities) error {
+	// Openshift 3 configures project request template in master config
+	if !caps.Config {
+		log.Info("cluster doesn't serve project configuration, project request template has to be set in master config", "template", TemplateName)
+		return nil
+	}
+	config := &configv1.Project{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: ConfigName}, config); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("project configuration of the cluster not found, project request template has to be set in master config", "template", TemplateName)
+			return nil
+		}
+		return errs.Wrapf(err, "failed to get project configuration %s", ConfigName)
+	}
+
+	current := config.Spec.ProjectRequestTemplate.Name
+	if current == name || (name == "" && current != TemplateName) {
+		return nil
+	}
+	config.Spec.ProjectRequestTemplate.Name = name
+	if err := cl.Update(ctx, config); err != nil {
+		return errs.Wrapf(err, "failed to update project configuration %s", ConfigName)
+	}
+	log.Info("project request template set in project configuration", "template", name)
+	return nil
+}
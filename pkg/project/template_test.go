@@ -0,0 +1,171 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fabric8-services/toolchain-operator/pkg/apis"
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	configv1 "github.com/openshift/api/config/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTemplate(t *testing.T) {
+	//given
+	spec := &codereadyv1alpha1.ProjectTemplateSpec{
+		ResourceQuota: &corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("2")},
+		},
+		NetworkPolicies: []codereadyv1alpha1.ProjectNetworkPolicy{{Name: "allow-from-same-namespace"}},
+	}
+
+	//when
+	template, err := Template(spec)
+
+	//then
+	require.NoError(t, err)
+	assert.Equal(t, TemplateName, template.Name)
+	assert.Equal(t, Namespace, template.Namespace)
+	assert.Equal(t, []string{"Project", "RoleBinding", "ResourceQuota", "NetworkPolicy"}, kinds(t, template))
+	assert.Len(t, template.Parameters, 5)
+}
+
+func TestSameTemplate(t *testing.T) {
+	spec := func(cpu string) *codereadyv1alpha1.ProjectTemplateSpec {
+		return &codereadyv1alpha1.ProjectTemplateSpec{
+			ResourceQuota: &corev1.ResourceQuotaSpec{
+				Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse(cpu)},
+			},
+		}
+	}
+
+	t.Run("same", func(t *testing.T) {
+		assert.True(t, SameTemplate(spec("2"), spec("2000m")))
+		assert.True(t, SameTemplate(nil, &codereadyv1alpha1.ProjectTemplateSpec{}))
+	})
+
+	t.Run("different", func(t *testing.T) {
+		assert.False(t, SameTemplate(spec("2"), spec("3")))
+		assert.False(t, SameTemplate(spec("2"), nil))
+	})
+}
+
+func TestEnsure(t *testing.T) {
+	require.NoError(t, apis.AddToScheme(scheme.Scheme))
+	spec := &codereadyv1alpha1.ProjectTemplateSpec{
+		LimitRange: &corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{{Type: corev1.LimitTypeContainer}},
+		},
+	}
+
+	t.Run("create template and set it in project config", func(t *testing.T) {
+		//given
+		cl := fake.NewFakeClient(projectConfig(""))
+
+		//when
+		err := Ensure(context.Background(), cl, spec, capabilities.OpenShift4)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Project", "RoleBinding", "LimitRange"}, kinds(t, getTemplate(t, cl)))
+		assert.Equal(t, TemplateName, getProjectConfig(t, cl).Spec.ProjectRequestTemplate.Name)
+	})
+
+	t.Run("revert drifted template", func(t *testing.T) {
+		//given
+		drifted, err := Template(nil)
+		require.NoError(t, err)
+		cl := fake.NewFakeClient(projectConfig(TemplateName), drifted)
+
+		//when
+		err = Ensure(context.Background(), cl, spec, capabilities.OpenShift4)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Project", "RoleBinding", "LimitRange"}, kinds(t, getTemplate(t, cl)))
+	})
+
+	t.Run("delete template", func(t *testing.T) {
+		//given
+		template, err := Template(spec)
+		require.NoError(t, err)
+		cl := fake.NewFakeClient(projectConfig(TemplateName), template)
+
+		//when
+		err = Delete(context.Background(), cl, capabilities.OpenShift4)
+
+		//then
+		require.NoError(t, err)
+		err = cl.Get(context.Background(), types.NamespacedName{Namespace: Namespace, Name: TemplateName}, &templatev1.Template{})
+		assert.True(t, errors.IsNotFound(err), "template not deleted")
+		assert.Empty(t, getProjectConfig(t, cl).Spec.ProjectRequestTemplate.Name)
+	})
+
+	t.Run("keep template set by cluster admin", func(t *testing.T) {
+		//given
+		cl := fake.NewFakeClient(projectConfig("custom-project-request"))
+
+		//when
+		err := Delete(context.Background(), cl, capabilities.OpenShift4)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, "custom-project-request", getProjectConfig(t, cl).Spec.ProjectRequestTemplate.Name)
+	})
+
+	t.Run("project config not served", func(t *testing.T) {
+		//given
+		cl := fake.NewFakeClient(projectConfig(""))
+
+		//when
+		err := Ensure(context.Background(), cl, spec, capabilities.Capabilities{Route: true, OAuth: true, OpenShiftVersion: "3"})
+
+		//then
+		require.NoError(t, err)
+		getTemplate(t, cl)
+		assert.Empty(t, getProjectConfig(t, cl).Spec.ProjectRequestTemplate.Name)
+	})
+}
+
+func projectConfig(template string) *configv1.Project {
+	return &configv1.Project{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigName},
+		Spec: configv1.ProjectSpec{
+			ProjectRequestTemplate: configv1.TemplateReference{Name: template},
+		},
+	}
+}
+
+func getProjectConfig(t *testing.T, cl client.Client) *configv1.Project {
+	config := &configv1.Project{}
+	require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Name: ConfigName}, config))
+	return config
+}
+
+func getTemplate(t *testing.T, cl client.Client) *templatev1.Template {
+	template := &templatev1.Template{}
+	require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: Namespace, Name: TemplateName}, template))
+	return template
+}
+
+func kinds(t *testing.T, template *templatev1.Template) []string {
+	var kinds []string
+	for _, obj := range template.Objects {
+		var typeMeta metav1.TypeMeta
+		require.NoError(t, json.Unmarshal(obj.Raw, &typeMeta))
+		kinds = append(kinds, typeMeta.Kind)
+	}
+	return kinds
+}
@@ -0,0 +1,272 @@
+package redact
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	clusterclient "github.com/fabric8-services/fabric8-cluster-client/cluster"
+	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces secrets scrubbed from text
+const Redacted = "<redacted>"
+
+// Value hides the given secret value, only its presence and length are kept
+func Value(value string) string {
+	if value == "" {
+		return "<empty>"
+	}
+	return fmt.Sprintf("<redacted, %d bytes>", len(value))
+}
+
+// URL hides password of user info in the given URL
+func URL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "redacted")
+	}
+	return u.String()
+}
+
+// secretKeys are names of fields holding secrets in requests and responses of auth and cluster service
+const secretKeys = `token|access_token|refresh_token|id_token|service-account-token|auth-client-secret|client_secret|client-secret|secret|password`
+
+// patterns of secrets scrubbed from text, applied in order
+var patterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	// private keys in PEM format
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), Redacted},
+	// JSON fields, e.g. cluster configuration echoed back by cluster service
+	{regexp.MustCompile(`(?i)("(?:` + secretKeys + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`), `${1}"` + Redacted + `"`},
+	// form fields and query parameters, e.g. token request sent to auth service
+	{regexp.MustCompile(`(?i)\b((?:` + secretKeys + `)=)[^&\s"']+`), `${1}` + Redacted},
+	// credentials of Authorization header
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`), `${1} ` + Redacted},
+	// JSON Web Tokens anywhere else
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Redacted},
+}
+
+// Text scrubs known patterns of secrets from the given text, e.g. body of response of auth or cluster service, before
+// it's logged or written to status
+func Text(text string) string {
+	for _, p := range patterns {
+		text = p.re.ReplaceAllString(text, p.replacement)
+	}
+	return text
+}
+
+// Error returns the given error with secrets scrubbed from its message, nil if there is no error
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := Text(err.Error())
+	if message == err.Error() {
+		return err
+	}
+	return &scrubbed{message: message, cause: err}
+}
+
+// scrubbed is an error with secrets scrubbed from its message, its cause is kept for errors.Cause
+type scrubbed struct {
+	message string
+	cause   error
+}
+
+func (e *scrubbed) Error() string {
+	return e.message
+}
+
+func (e *scrubbed) Cause() error {
+	return e.cause
+}
+
+// field is a named value safe to be logged
+type field struct {
+	name  string
+	value interface{}
+}
+
+// fields are logged as a single value, with String for fmt and MarshalLogObject for the zap logger behind the operator
+// logger, so that no secret is printed however they're logged
+type fields []field
+
+func (f fields) String() string {
+	values := make([]string, 0, len(f))
+	for _, v := range f {
+		values = append(values, fmt.Sprintf("%s=%v", v.name, v.value))
+	}
+	return "{" + strings.Join(values, ", ") + "}"
+}
+
+func (f fields) MarshalLog() interface{} {
+	values := make(map[string]interface{}, len(f))
+	for _, v := range f {
+		values[v.name] = v.value
+	}
+	return values
+}
+
+func (f fields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, v := range f {
+		enc.AddString(v.name, fmt.Sprint(v.value))
+	}
+	return nil
+}
+
+// ClusterData wraps cluster configuration registered in cluster service, so that it can be logged without the token of
+// the service account and the secret of the OAuth client
+type ClusterData struct {
+	data *clusterclient.CreateClusterData
+}
+
+// NewClusterData wraps the given cluster configuration
+func NewClusterData(data *clusterclient.CreateClusterData) ClusterData {
+	return ClusterData{data: data}
+}
+
+func (d ClusterData) fields() fields {
+	if d.data == nil {
+		return nil
+	}
+	tokenProviderID := ""
+	if d.data.TokenProviderID != nil {
+		tokenProviderID = *d.data.TokenProviderID
+	}
+	return fields{
+		{"name", d.data.Name},
+		{"api-url", d.data.APIURL},
+		{"app-dns", d.data.AppDNS},
+		{"auth-client-id", d.data.AuthClientID},
+		{"auth-client-secret", Value(d.data.AuthClientSecret)},
+		{"auth-client-default-scope", d.data.AuthClientDefaultScope},
+		{"service-account-username", d.data.ServiceAccountUsername},
+		{"service-account-token", Value(d.data.ServiceAccountToken)},
+		{"token-provider-id", tokenProviderID},
+		{"type", d.data.Type},
+	}
+}
+
+// String returns the cluster configuration with secrets redacted
+func (d ClusterData) String() string {
+	return d.fields().String()
+}
+
+// GoString returns the same as String, so that secrets aren't printed by %#v either
+func (d ClusterData) GoString() string {
+	return d.String()
+}
+
+// MarshalLog returns the cluster configuration with secrets redacted as a map for structured loggers
+func (d ClusterData) MarshalLog() interface{} {
+	return d.fields().MarshalLog()
+}
+
+// MarshalLogObject encodes the cluster configuration with secrets redacted for zap logger
+func (d ClusterData) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return d.fields().MarshalLogObject(enc)
+}
+
+// Config wraps toolchain configuration, so that it can be logged without the client secret, the client key and proxy
+// passwords
+type Config struct {
+	config config.ToolchainConfig
+}
+
+// NewConfig wraps the given toolchain configuration
+func NewConfig(cfg config.ToolchainConfig) Config {
+	return Config{config: cfg}
+}
+
+func (c Config) fields() fields {
+	return fields{
+		{"authURL", c.config.AuthURL},
+		{"clusterURL", c.config.ClusterURL},
+		{"clusterName", c.config.ClusterName},
+		{"clientID", c.config.ClientID},
+		{"clientSecret", Value(c.config.ClientSecret)},
+		{"httpConnectTimeout", c.config.HTTPConnectTimeout},
+		{"httpResponseTimeout", c.config.HTTPResponseTimeout},
+		{"resyncPeriod", c.config.ResyncPeriod},
+		{"caBundle", Value(string(c.config.CABundle))},
+		{"clientCertificate", Value(string(c.config.ClientCertificate))},
+		{"clientKey", Value(string(c.config.ClientKey))},
+		{"httpProxy", URL(c.config.HTTPProxy)},
+		{"httpsProxy", URL(c.config.HTTPSProxy)},
+		{"noProxy", c.config.NoProxy},
+	}
+}
+
+// String returns the toolchain configuration with secrets redacted
+func (c Config) String() string {
+	return c.fields().String()
+}
+
+// GoString returns the same as String, so that secrets aren't printed by %#v either
+func (c Config) GoString() string {
+	return c.String()
+}
+
+// MarshalLog returns the toolchain configuration with secrets redacted as a map for structured loggers
+func (c Config) MarshalLog() interface{} {
+	return c.fields().MarshalLog()
+}
+
+// MarshalLogObject encodes the toolchain configuration with secrets redacted for zap logger
+func (c Config) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return c.fields().MarshalLogObject(enc)
+}
+
+// OperatorConfig wraps configuration of the operator, so that it's logged and served under the keys of the operator
+// ConfigMap. It holds no secret, durations are formatted to be readable
+type OperatorConfig struct {
+	config config.OperatorConfig
+}
+
+// NewOperatorConfig wraps the given operator configuration
+func NewOperatorConfig(cfg config.OperatorConfig) OperatorConfig {
+	return OperatorConfig{config: cfg}
+}
+
+func (c OperatorConfig) fields() fields {
+	return fields{
+		{config.RegistrationRetryPeriodKey, c.config.RegistrationRetryPeriod.String()},
+		{config.PermissionsRecheckPeriodKey, c.config.PermissionsRecheckPeriod.String()},
+		{config.DriftCheckPeriodKey, c.config.DriftCheckPeriod.String()},
+		{config.ReconcileTimeoutKey, c.config.ReconcileTimeout.String()},
+		{config.HTTPConnectTimeoutKey, c.config.HTTPConnectTimeout.String()},
+		{config.HTTPResponseTimeoutKey, c.config.HTTPResponseTimeout.String()},
+		{config.ResyncPeriodKey, c.config.ResyncPeriod.String()},
+		{config.ServiceAccountNameKey, c.config.ServiceAccountName},
+		{config.OAuthClientNameKey, c.config.OAuthClientName},
+		{config.InfraNamespaceKey, c.config.InfraNamespace},
+	}
+}
+
+// String returns the operator configuration
+func (c OperatorConfig) String() string {
+	return c.fields().String()
+}
+
+// GoString returns the same as String
+func (c OperatorConfig) GoString() string {
+	return c.String()
+}
+
+// MarshalLog returns the operator configuration as a map for structured loggers and JSON
+func (c OperatorConfig) MarshalLog() interface{} {
+	return c.fields().MarshalLog()
+}
+
+// MarshalLogObject encodes the operator configuration for zap logger
+func (c OperatorConfig) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return c.fields().MarshalLogObject(enc)
+}
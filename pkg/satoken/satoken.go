@@ -0,0 +1,195 @@
+package satoken
+
+import (
+	"time"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	errs "github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("satoken")
+
+const (
+	// IssueTimeAnnotation holds the time when token stored in Secret was minted via TokenRequest API
+	IssueTimeAnnotation = "codeready.openshift.io/token-issue-time"
+	// ExpirationTimeAnnotation holds the time when token stored in Secret expires
+	ExpirationTimeAnnotation = "codeready.openshift.io/token-expiration-time"
+
+	// DefaultExpiration is the validity requested for tokens minted via TokenRequest API
+	DefaultExpiration = 7 * 24 * time.Hour
+
+	// tokenKey is the key of token in Secret, the same as in Secrets populated by the API server
+	tokenKey = "token"
+	// renewalRatio is the part of token validity after which the token is renewed
+	renewalRatio = 0.8
+)
+
+// SecretName returns name of Secret holding token of the given Service Account
+func SecretName(saName string) string {
+	return saName + "-token"
+}
+
+// MechanismOf returns TokenRequest mechanism if the API server serves serviceaccounts/token subresource, or Secret
+// mechanism otherwise
+func MechanismOf(caps capabilities.Capabilities) codereadyv1alpha1.TokenMechanism {
+	if caps.TokenRequest {
+		return codereadyv1alpha1.TokenMechanismTokenRequest
+	}
+	return codereadyv1alpha1.TokenMechanismSecret
+}
+
+// Requester mints token of the given Service Account valid for the given duration, returning the token and the time
+// when it expires
+type Requester func(namespace, name string, expiration time.Duration) (string, time.Time, error)
+
+// NewRequester returns Requester minting tokens via TokenRequest API
+func NewRequester(cl corev1client.ServiceAccountsGetter) Requester {
+	return func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+		seconds := int64(expiration.Seconds())
+		tr, err := cl.ServiceAccounts(namespace).CreateToken(name, &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				ExpirationSeconds: &seconds,
+			},
+		})
+		if err != nil {
+			return "", time.Time{}, errs.Wrapf(err, "failed to request token of sa %s", name)
+		}
+		return tr.Status.Token, tr.Status.ExpirationTimestamp.Time, nil
+	}
+}
+
+// Provider provides token of Service Account using the mechanism supported by the API server. Nil provider falls back
+// to Secret mechanism
+type Provider struct {
+	Mechanism  codereadyv1alpha1.TokenMechanism
+	Request    Requester
+	Expiration time.Duration
+	now        func() time.Time
+}
+
+// NewProvider returns Provider with the mechanism picked from the given capabilities of the API server
+func NewProvider(cfg *rest.Config, caps capabilities.Capabilities) (*Provider, error) {
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to create client for service account tokens")
+	}
+	mechanism := MechanismOf(caps)
+	log.Info("service account token mechanism detected", "mechanism", mechanism)
+	return &Provider{Mechanism: mechanism, Request: NewRequester(cs.CoreV1()), Expiration: DefaultExpiration}, nil
+}
+
+// Bounded returns true if tokens are minted via TokenRequest API and need to be renewed
+func (p *Provider) Bounded() bool {
+	return p != nil && p.Mechanism == codereadyv1alpha1.TokenMechanismTokenRequest
+}
+
+// Desired returns Secret holding token of the given Service Account. With Secret mechanism, the token is populated by
+// the API server into the Secret annotated for the Service Account
+func (p *Provider) Desired(namespace, saName string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SecretName(saName),
+			Namespace: namespace,
+		},
+	}
+	if p.Bounded() {
+		secret.Type = corev1.SecretTypeOpaque
+		return secret
+	}
+	secret.Type = corev1.SecretTypeServiceAccountToken
+	secret.Annotations = map[string]string{corev1.ServiceAccountNameKey: saName}
+	return secret
+}
+
+// Mint requests new token of the given Service Account and stores it in the given Secret together with its issue
+// and expiration time
+func (p *Provider) Mint(secret *corev1.Secret, saName string) error {
+	issued := p.clock()
+	token, expiration, err := p.Request(secret.Namespace, saName, p.Expiration)
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[tokenKey] = []byte(token)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[IssueTimeAnnotation] = issued.UTC().Format(time.RFC3339)
+	secret.Annotations[ExpirationTimeAnnotation] = expiration.UTC().Format(time.RFC3339)
+	log.Info("token minted", "sa", saName, "expiration", expiration)
+	return nil
+}
+
+// Due returns true if the given Secret doesn't hold any token yet or the token minted via TokenRequest API should be
+// renewed
+func (p *Provider) Due(secret *corev1.Secret) bool {
+	if len(Token(secret)) == 0 {
+		return true
+	}
+	if !p.Bounded() {
+		return false
+	}
+	renewAt := RenewTime(secret)
+	return renewAt == nil || !p.clock().Before(*renewAt)
+}
+
+// Token returns token stored in the given Secret
+func Token(secret *corev1.Secret) string {
+	return string(secret.Data[tokenKey])
+}
+
+// IssueTime returns the time when bounded token stored in the given Secret was minted, nil for long-lived token
+func IssueTime(secret *corev1.Secret) *time.Time {
+	return annotatedTime(secret, IssueTimeAnnotation)
+}
+
+// ExpirationTime returns the time when bounded token stored in the given Secret expires, nil for long-lived token
+func ExpirationTime(secret *corev1.Secret) *time.Time {
+	return annotatedTime(secret, ExpirationTimeAnnotation)
+}
+
+// RenewTime returns the time when bounded token stored in the given Secret should be renewed, well before it expires.
+// It returns nil for long-lived token
+func RenewTime(secret *corev1.Secret) *time.Time {
+	issued, expiration := IssueTime(secret), ExpirationTime(secret)
+	if issued == nil || expiration == nil {
+		return nil
+	}
+	renewAt := RenewTimeOf(*issued, *expiration)
+	return &renewAt
+}
+
+// RenewTimeOf returns the time when bounded token issued and expiring at the given times should be renewed
+func RenewTimeOf(issued, expiration time.Time) time.Time {
+	return issued.Add(time.Duration(float64(expiration.Sub(issued)) * renewalRatio))
+}
+
+func annotatedTime(secret *corev1.Secret, annotation string) *time.Time {
+	value, ok := secret.Annotations[annotation]
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Error(err, "invalid time in annotation of secret", "secret", secret.Name, "annotation", annotation)
+		return nil
+	}
+	return &t
+}
+
+func (p *Provider) clock() time.Time {
+	if p != nil && p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
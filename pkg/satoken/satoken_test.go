@@ -0,0 +1,107 @@
+package satoken
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	codereadyv1alpha1 "github.com/fabric8-services/toolchain-operator/pkg/apis/codeready/v1alpha1"
+	"github.com/fabric8-services/toolchain-operator/pkg/capabilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMechanismOf(t *testing.T) {
+
+	t.Run("token request supported", func(t *testing.T) {
+		//when
+		mechanism := MechanismOf(capabilities.Capabilities{TokenRequest: true})
+
+		//then
+		assert.Equal(t, codereadyv1alpha1.TokenMechanismTokenRequest, mechanism)
+	})
+
+	t.Run("token request not supported", func(t *testing.T) {
+		//when
+		mechanism := MechanismOf(capabilities.Capabilities{Config: true, Route: true})
+
+		//then
+		assert.Equal(t, codereadyv1alpha1.TokenMechanismSecret, mechanism)
+	})
+}
+
+func TestProvider(t *testing.T) {
+	now := time.Date(2019, 4, 1, 10, 0, 0, 0, time.UTC)
+	bounded := &Provider{
+		Mechanism:  codereadyv1alpha1.TokenMechanismTokenRequest,
+		Expiration: 10 * time.Hour,
+		Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+			return "minted-" + name, now.Add(expiration), nil
+		},
+		now: func() time.Time { return now },
+	}
+
+	t.Run("nil provider falls back to secret populated by api server", func(t *testing.T) {
+		//given
+		var p *Provider
+
+		//when
+		secret := p.Desired("codeready-toolchain", "toolchain-sre")
+
+		//then
+		assert.Equal(t, "toolchain-sre-token", secret.Name)
+		assert.Equal(t, corev1.SecretTypeServiceAccountToken, secret.Type)
+		assert.Equal(t, "toolchain-sre", secret.Annotations[corev1.ServiceAccountNameKey])
+		assert.True(t, p.Due(secret), "token not populated yet")
+	})
+
+	t.Run("mint bounded token", func(t *testing.T) {
+		//given
+		secret := bounded.Desired("codeready-toolchain", "toolchain-sre")
+		require.True(t, bounded.Due(secret))
+
+		//when
+		err := bounded.Mint(secret, "toolchain-sre")
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, corev1.SecretTypeOpaque, secret.Type)
+		assert.Equal(t, "minted-toolchain-sre", Token(secret))
+		assert.Equal(t, now, *IssueTime(secret))
+		assert.Equal(t, now.Add(10*time.Hour), *ExpirationTime(secret))
+		assert.Equal(t, now.Add(8*time.Hour), *RenewTime(secret))
+		assert.False(t, bounded.Due(secret))
+	})
+
+	t.Run("renew bounded token before expiration", func(t *testing.T) {
+		//given
+		secret := bounded.Desired("codeready-toolchain", "toolchain-sre")
+		secret.Data = map[string][]byte{"token": []byte("old")}
+		secret.Annotations = map[string]string{
+			IssueTimeAnnotation:      now.Add(-9 * time.Hour).Format(time.RFC3339),
+			ExpirationTimeAnnotation: now.Add(time.Hour).Format(time.RFC3339),
+		}
+
+		//then
+		assert.True(t, bounded.Due(secret))
+	})
+
+	t.Run("request failed", func(t *testing.T) {
+		//given
+		p := &Provider{
+			Mechanism: codereadyv1alpha1.TokenMechanismTokenRequest,
+			Request: func(namespace, name string, expiration time.Duration) (string, time.Time, error) {
+				return "", time.Time{}, errors.New("forbidden")
+			},
+		}
+		secret := p.Desired("codeready-toolchain", "toolchain-sre")
+
+		//when
+		err := p.Mint(secret, "toolchain-sre")
+
+		//then
+		assert.EqualError(t, err, "forbidden")
+		assert.Empty(t, Token(secret))
+	})
+}
@@ -0,0 +1,201 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"contrib.go.opencensus.io/exporter/ocagent"
+	"github.com/fabric8-services/toolchain-operator/pkg/redact"
+	errs "github.com/pkg/errors"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("tracing")
+
+const (
+	// ServiceName is the name of the service spans are exported for
+	ServiceName = "toolchain-operator"
+
+	// ExporterNone disables export of spans, trace context is still propagated in headers of outbound requests
+	ExporterNone = "none"
+	// ExporterStdout writes spans to the standard output as JSON, one per line
+	ExporterStdout = "stdout"
+	// ExporterAgent sends spans over gRPC to OpenCensus agent, or to OpenTelemetry collector with opencensus receiver
+	ExporterAgent = "ocagent"
+)
+
+// format propagates trace context in W3C traceparent and tracestate headers
+var format = &tracecontext.HTTPFormat{}
+
+// Config configures export of spans
+type Config struct {
+	// Exporter is one of none, stdout or ocagent
+	Exporter string
+	// Address is host and port of the agent receiving spans, localhost:55678 if empty
+	Address string
+	// Insecure sends spans to the agent without TLS
+	Insecure bool
+	// Output receives spans written by stdout exporter, the standard output if nil
+	Output io.Writer
+}
+
+// Setup registers exporter of spans as configured. All the spans are sampled, as reconciles are rare enough to trace
+// each of them. The returned function flushes spans which haven't been exported yet and stops the exporter
+func Setup(cfg Config) (func(), error) {
+	var stop func()
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func() {}, nil
+	case ExporterStdout:
+		out := cfg.Output
+		if out == nil {
+			out = os.Stdout
+		}
+		exporter := &stdoutExporter{encoder: json.NewEncoder(out)}
+		trace.RegisterExporter(exporter)
+		stop = func() {
+			trace.UnregisterExporter(exporter)
+		}
+	case ExporterAgent:
+		options := []ocagent.ExporterOption{ocagent.WithServiceName(ServiceName)}
+		if cfg.Address != "" {
+			options = append(options, ocagent.WithAddress(cfg.Address))
+		}
+		if cfg.Insecure {
+			options = append(options, ocagent.WithInsecure())
+		}
+		exporter, err := ocagent.NewExporter(options...)
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to create %s exporter of spans", cfg.Exporter)
+		}
+		trace.RegisterExporter(exporter)
+		stop = func() {
+			trace.UnregisterExporter(exporter)
+			exporter.Flush()
+			if err := exporter.Stop(); err != nil {
+				log.Error(err, "failed to stop exporter of spans")
+			}
+		}
+	default:
+		return nil, errs.Errorf("unknown exporter of spans %q, expected one of %s, %s or %s", cfg.Exporter, ExporterNone, ExporterStdout, ExporterAgent)
+	}
+
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	log.Info("exporting spans", "exporter", cfg.Exporter, "address", cfg.Address)
+	return stop, nil
+}
+
+// Start starts a span with the given name, child of the span of the given context if there is any
+func Start(ctx context.Context, name string, attributes ...trace.Attribute) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, name)
+	span.AddAttributes(attributes...)
+	return ctx, span
+}
+
+// End marks the span as failed if the given error is set and ends it. It's meant to be deferred with pointer to the
+// named error result of the traced function, so that the error is read once the function returns
+func End(span *trace.Span, err *error) {
+	if err != nil && *err != nil {
+		fail(span, *err)
+	}
+	span.End()
+}
+
+// fail records the given error in the span, exported spans leave the cluster so secrets are scrubbed from it
+func fail(span *trace.Span, err error) {
+	span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: redact.Error(err).Error()})
+}
+
+// Transport returns round tripper which starts a client span for each request and propagates its context in headers
+// of the request. http.DefaultTransport is wrapped if the given one is nil
+func Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if _, traced := next.(*transport); traced {
+		return next
+	}
+	return &transport{next: next}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := trace.StartSpan(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.AddAttributes(
+		trace.StringAttribute("http.method", req.Method),
+		trace.StringAttribute("http.url", redact.Text(redact.URL(req.URL.String()))),
+	)
+
+	// round trippers mustn't modify the given request, so trace context is set in headers of its copy
+	traced := req.WithContext(ctx)
+	traced.Header = make(http.Header, len(req.Header)+2)
+	for k, v := range req.Header {
+		traced.Header[k] = v
+	}
+	format.SpanContextToRequest(span.SpanContext(), traced)
+
+	res, err := t.next.RoundTrip(traced)
+	if err != nil {
+		fail(span, err)
+		return nil, err
+	}
+	span.AddAttributes(trace.Int64Attribute("http.status_code", int64(res.StatusCode)))
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: res.Status})
+	}
+	return res, nil
+}
+
+// stdoutExporter writes each span as a line of JSON
+type stdoutExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// exportedSpan is a span written by stdoutExporter
+type exportedSpan struct {
+	Name         string                 `json:"name"`
+	TraceID      string                 `json:"traceId"`
+	SpanID       string                 `json:"spanId"`
+	ParentSpanID string                 `json:"parentSpanId,omitempty"`
+	Start        time.Time              `json:"start"`
+	Duration     string                 `json:"duration"`
+	Status       string                 `json:"status,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+}
+
+func (e *stdoutExporter) ExportSpan(data *trace.SpanData) {
+	span := exportedSpan{
+		Name:       data.Name,
+		TraceID:    data.TraceID.String(),
+		SpanID:     data.SpanID.String(),
+		Start:      data.StartTime,
+		Duration:   data.EndTime.Sub(data.StartTime).String(),
+		Status:     data.Message,
+		Attributes: data.Attributes,
+	}
+	if data.ParentSpanID != (trace.SpanID{}) {
+		span.ParentSpanID = data.ParentSpanID.String()
+	}
+	if data.Code != trace.StatusCodeOK && span.Status == "" {
+		span.Status = fmt.Sprintf("code %d", data.Code)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.encoder.Encode(span); err != nil {
+		log.Error(err, "failed to write span", "name", data.Name)
+	}
+}
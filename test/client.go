@@ -1,11 +1,14 @@
 package test
 
 import (
+	"context"
 	"errors"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
 	oauthv1 "github.com/openshift/api/oauth/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	"k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type DummyClient struct {
@@ -17,23 +20,37 @@ func NewDummyClient(k8sClient client.Client, opts map[string]string) client.Clie
 	return &DummyClient{k8sClient, opts}
 }
 
-func (d *DummyClient) GetServiceAccount(namespace, name string) (*v1.ServiceAccount, error) {
+func (d *DummyClient) GetServiceAccount(ctx context.Context, namespace, name string) (*v1.ServiceAccount, error) {
 	if msg, ok := d.resources["sa"]; ok {
 		return nil, errors.New(msg)
 	}
-	return d.Client.GetServiceAccount(namespace, name)
+	return d.Client.GetServiceAccount(ctx, namespace, name)
 }
 
-func (d *DummyClient) GetClusterRoleBinding(name string) (*rbacv1.ClusterRoleBinding, error) {
+func (d *DummyClient) GetClusterRoleBinding(ctx context.Context, name string) (*rbacv1.ClusterRoleBinding, error) {
 	if msg, ok := d.resources["crb"]; ok {
 		return nil, errors.New(msg)
 	}
-	return d.Client.GetClusterRoleBinding(name)
+	return d.Client.GetClusterRoleBinding(ctx, name)
 }
 
-func (d *DummyClient) GetOAuthClient(name string) (*oauthv1.OAuthClient, error) {
+func (d *DummyClient) GetOAuthClient(ctx context.Context, name string) (*oauthv1.OAuthClient, error) {
 	if msg, ok := d.resources["oc"]; ok {
 		return nil, errors.New(msg)
 	}
-	return d.Client.GetOAuthClient(name)
+	return d.Client.GetOAuthClient(ctx, name)
+}
+
+// Create reviews SubjectAccessReview and SelfSubjectAccessReview allowing everything except the resource set under
+// "denied" key, other objects are created as usual
+func (d *DummyClient) Create(ctx context.Context, obj runtime.Object) error {
+	switch review := obj.(type) {
+	case *authorizationv1.SubjectAccessReview:
+		review.Status.Allowed = review.Spec.ResourceAttributes.Resource != d.resources["denied"]
+		return nil
+	case *authorizationv1.SelfSubjectAccessReview:
+		review.Status.Allowed = review.Spec.ResourceAttributes.Resource != d.resources["denied"]
+		return nil
+	}
+	return d.Client.Create(ctx, obj)
 }
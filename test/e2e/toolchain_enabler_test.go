@@ -10,6 +10,7 @@ import (
 	"github.com/fabric8-services/toolchain-operator/pkg/config"
 	"github.com/fabric8-services/toolchain-operator/pkg/controller/toolchainenabler"
 	"github.com/fabric8-services/toolchain-operator/pkg/online_registration"
+	"github.com/fabric8-services/toolchain-operator/test"
 	framework "github.com/operator-framework/operator-sdk/pkg/test"
 	"github.com/operator-framework/operator-sdk/pkg/test/e2eutil"
 	"github.com/stretchr/testify/assert"
@@ -41,11 +42,22 @@ func TestToolChainEnabler(t *testing.T) {
 	// get global framework variables
 	f := framework.Global
 
-	// wait for toolchain-operator to be ready
-	err = e2eutil.WaitForDeployment(t, f.KubeClient, namespace, config.Name, 1, retryInterval, timeout)
-	require.NoError(t, err, "failed while waiting for operator deployment")
+	if !f.LocalOperator {
+		// wait for toolchain-operator to be ready
+		err = e2eutil.WaitForDeployment(t, f.KubeClient, namespace, config.Name, 1, retryInterval, timeout)
+		require.NoError(t, err, "failed while waiting for operator deployment")
 
-	t.Log("Toolchain operator is ready and running state")
+		t.Log("Toolchain operator is ready and running state")
+	}
+
+	operatorClient := client.NewClient(f.Client.Client)
+
+	// fake auth and cluster services listen on loopback interface of the test, so only the operator running next to it
+	// with --up-local reaches them
+	auth := test.StartFakeAuthService()
+	defer auth.Close()
+	clusterService := test.NewFakeClusterService(auth)
+	defer clusterService.Close()
 
 	// create ToolChainEnabler custom resource
 	exampleToolChainEnabler := &codereadyv1alpha1.ToolChainEnabler{
@@ -58,8 +70,8 @@ func TestToolChainEnabler(t *testing.T) {
 			Namespace: namespace,
 		},
 		Spec: codereadyv1alpha1.ToolChainEnablerSpec{
-			AuthURL:             "https://auth.openshift.io",
-			ClusterURL:          "https://cluster.openshift.io",
+			AuthURL:             auth.URL,
+			ClusterURL:          clusterService.URL,
 			ClusterName:         "dsaas-stage",
 			ToolchainSecretName: "toolchain",
 		},
@@ -68,21 +80,32 @@ func TestToolChainEnabler(t *testing.T) {
 	err = f.Client.Create(context.TODO(), exampleToolChainEnabler, &framework.CleanupOptions{TestContext: ctx, Timeout: cleanupTimeout, RetryInterval: cleanupRetryInterval})
 	require.NoError(t, err, "failed to create custom resource of kind `ToolChainEnabler`")
 
-	operatorClient := client.NewClient(f.Client.Client)
+	// fake auth service trusts the credentials of toolchain secret the ToolChainEnabler refers to
+	toolchainSecret, err := operatorClient.GetSecret(context.Background(), namespace, exampleToolChainEnabler.Spec.ToolchainSecretName)
+	require.NoError(t, err, "failed to get toolchain secret")
+	auth.Register(string(toolchainSecret.Data[config.TCClientID]), string(toolchainSecret.Data[config.TCClientSecret]))
 
 	t.Run("verify", func(t *testing.T) {
 		err = verifyResources(t, operatorClient, namespace)
 		assert.NoError(t, err)
 	})
 
+	t.Run("cluster registered in fake cluster service", func(t *testing.T) {
+		if !f.LocalOperator {
+			t.Skip("operator deployed in the cluster doesn't reach fake cluster service, run it with --up-local")
+		}
+		err := waitForRegisteredCluster(t, clusterService, "dsaas-stage")
+		assert.NoError(t, err)
+	})
+
 	t.Run("delete oauth client and verify", func(t *testing.T) {
 		// given
-		oc, err := operatorClient.GetOAuthClient(config.OAuthClientName)
+		oc, err := operatorClient.GetOAuthClient(context.Background(), config.DefaultOAuthClientName)
 		require.NoError(t, err)
 
 		// when
 		err = operatorClient.Delete(context.Background(), oc)
-		require.NoError(t, err, "failed to delete oauth client %s", config.OAuthClientName)
+		require.NoError(t, err, "failed to delete oauth client %s", config.DefaultOAuthClientName)
 
 		// then
 		err = verifyResources(t, operatorClient, namespace)
@@ -91,7 +114,7 @@ func TestToolChainEnabler(t *testing.T) {
 
 	t.Run("delete self-provisioner cluster role binding and verify", func(t *testing.T) {
 		// given
-		clusterRoleBinding, err := operatorClient.GetClusterRoleBinding(toolchainenabler.SelfProvisioner)
+		clusterRoleBinding, err := operatorClient.GetClusterRoleBinding(context.Background(), toolchainenabler.SelfProvisioner)
 		require.NoError(t, err)
 
 		// when
@@ -105,7 +128,7 @@ func TestToolChainEnabler(t *testing.T) {
 
 	t.Run("delete dsaas-cluster-admin cluster role binding and verify", func(t *testing.T) {
 		// given
-		clusterRoleBinding, err := operatorClient.GetClusterRoleBinding(toolchainenabler.DsaasClusterAdmin)
+		clusterRoleBinding, err := operatorClient.GetClusterRoleBinding(context.Background(), toolchainenabler.DsaasClusterAdmin)
 		require.NoError(t, err)
 
 		// when
@@ -119,12 +142,12 @@ func TestToolChainEnabler(t *testing.T) {
 
 	t.Run("delete sa and verify", func(t *testing.T) {
 		// given
-		sa, err := operatorClient.GetServiceAccount(namespace, config.SAName)
+		sa, err := operatorClient.GetServiceAccount(context.Background(), namespace, config.DefaultSAName)
 		require.NoError(t, err)
 
 		// when
 		err = operatorClient.Delete(context.Background(), sa)
-		require.NoError(t, err, "failed to delete service account %s/%s", namespace, config.SAName)
+		require.NoError(t, err, "failed to delete service account %s/%s", namespace, config.DefaultSAName)
 
 		// then
 		err = verifyResources(t, operatorClient, namespace)
@@ -133,7 +156,7 @@ func TestToolChainEnabler(t *testing.T) {
 
 	t.Run("delete online-registration cluster role binding and verify", func(t *testing.T) {
 		// given
-		clusterRoleBinding, err := operatorClient.GetClusterRoleBinding(online_registration.ClusterRoleBindingName)
+		clusterRoleBinding, err := operatorClient.GetClusterRoleBinding(context.Background(), online_registration.ClusterRoleBindingName)
 		require.NoError(t, err)
 
 		// when
@@ -147,12 +170,12 @@ func TestToolChainEnabler(t *testing.T) {
 
 	t.Run("delete online-registration sa and verify", func(t *testing.T) {
 		// given
-		sa, err := operatorClient.GetServiceAccount(online_registration.Namespace, online_registration.ServiceAccountName)
+		sa, err := operatorClient.GetServiceAccount(context.Background(), config.DefaultInfraNamespace, online_registration.ServiceAccountName)
 		require.NoError(t, err)
 
 		// when
 		err = operatorClient.Delete(context.Background(), sa)
-		require.NoError(t, err, "failed to delete service account %s/%s", online_registration.Namespace, online_registration.ServiceAccountName)
+		require.NoError(t, err, "failed to delete service account %s/%s", config.DefaultInfraNamespace, online_registration.ServiceAccountName)
 
 		// then
 		err = verifyResources(t, operatorClient, namespace)
@@ -162,7 +185,7 @@ func TestToolChainEnabler(t *testing.T) {
 }
 
 func verifyResources(t *testing.T, operatorClient client.Client, namespace string) error {
-	if err := waitForServiceAccount(t, operatorClient, namespace, config.SAName); err != nil {
+	if err := waitForServiceAccount(t, operatorClient, namespace, config.DefaultSAName); err != nil {
 		return err
 	}
 
@@ -178,7 +201,7 @@ func verifyResources(t *testing.T, operatorClient client.Client, namespace strin
 		return err
 	}
 
-	if err := waitForServiceAccount(t, operatorClient, online_registration.Namespace, online_registration.ServiceAccountName); err != nil {
+	if err := waitForServiceAccount(t, operatorClient, config.DefaultInfraNamespace, online_registration.ServiceAccountName); err != nil {
 		return err
 	}
 
@@ -1,8 +1,10 @@
 package e2e
 
 import (
+	"context"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
 	"github.com/fabric8-services/toolchain-operator/pkg/config"
+	"github.com/fabric8-services/toolchain-operator/test"
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -20,7 +22,7 @@ const (
 
 func waitForServiceAccount(t *testing.T, operatorClient client.Client, namespace string, name string) error {
 	return wait.Poll(retryInterval, timeout, func() (done bool, err error) {
-		sa, err := operatorClient.GetServiceAccount(namespace, name)
+		sa, err := operatorClient.GetServiceAccount(context.Background(), namespace, name)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				t.Logf("Waiting for availability of service account %s in namespace %s \n", name, namespace)
@@ -41,7 +43,7 @@ func waitForServiceAccount(t *testing.T, operatorClient client.Client, namespace
 
 func waitForClusterRoleBinding(t *testing.T, operatorClient client.Client, name string) error {
 	return wait.Poll(retryInterval, timeout, func() (done bool, err error) {
-		crb, err := operatorClient.GetClusterRoleBinding(name)
+		crb, err := operatorClient.GetClusterRoleBinding(context.Background(), name)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				t.Logf("Waiting for availability of %s cluster role binding\n", name)
@@ -62,20 +64,33 @@ func waitForClusterRoleBinding(t *testing.T, operatorClient client.Client, name
 
 func waitForOauthClient(t *testing.T, operatorClient client.Client) error {
 	return wait.Poll(retryInterval, timeout, func() (done bool, err error) {
-		oc, err := operatorClient.GetOAuthClient(config.OAuthClientName)
+		oc, err := operatorClient.GetOAuthClient(context.Background(), config.DefaultOAuthClientName)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				t.Logf("Waiting for availability of oauth client %s \n", config.OAuthClientName)
+				t.Logf("Waiting for availability of oauth client %s \n", config.DefaultOAuthClientName)
 				return false, nil
 			}
 			return false, err
 		}
 
 		if !reflect.DeepEqual(oauthv1.OAuthClient{}, *oc) {
-			t.Logf("Found oauth client %s \n", config.OAuthClientName)
+			t.Logf("Found oauth client %s \n", config.DefaultOAuthClientName)
 			return true, nil
 		}
-		t.Logf("Waiting for availability of %s oauth client \n", config.OAuthClientName)
+		t.Logf("Waiting for availability of %s oauth client \n", config.DefaultOAuthClientName)
+		return false, nil
+	})
+}
+
+func waitForRegisteredCluster(t *testing.T, clusterService *test.FakeClusterService, name string) error {
+	return wait.Poll(retryInterval, timeout, func() (done bool, err error) {
+		for _, c := range clusterService.Clusters() {
+			if c.Name == name {
+				t.Logf("Found cluster %s registered with API URL %s \n", name, c.APIURL)
+				return true, nil
+			}
+		}
+		t.Logf("Waiting for registration of cluster %s in fake cluster service \n", name)
 		return false, nil
 	})
 }
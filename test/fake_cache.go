@@ -5,7 +5,6 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	toolscache "k8s.io/client-go/tools/cache"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -42,6 +41,14 @@ func (c *FakeCache) List(ctx context.Context, opts *client.ListOptions, list run
 	return nil
 }
 
-func NewFakeCache(err error) cache.Cache {
+func (c *FakeCache) EnsureStarted(ctx context.Context) error {
+	return nil
+}
+
+func (c *FakeCache) Started() bool {
+	return true
+}
+
+func NewFakeCache(err error) *FakeCache {
 	return &FakeCache{Err: err}
 }
@@ -0,0 +1,406 @@
+package test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Request is a request received by a fake service
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+}
+
+// fault is a failure injected into a fake service, replied instead of handling matching requests
+type fault struct {
+	method string
+	path   string
+	status int
+	body   string
+	times  int
+}
+
+// fakeService records received requests and replies injected failures, it's shared by fake auth and cluster service
+type fakeService struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []Request
+	faults   []*fault
+}
+
+// Fail makes the fake service reply the given status to the next requests of the given method and path. Negative times
+// makes it fail until Heal is called
+func (s *fakeService) Fail(method, path string, status, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{
+		method: method,
+		path:   normalizePath(path),
+		status: status,
+		body:   fmt.Sprintf(`{"errors":[{"code":"injected_failure","detail":"failure injected into fake service","status":"%d"}]}`, status),
+		times:  times,
+	})
+}
+
+// Heal removes all the injected failures
+func (s *fakeService) Heal() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns all the requests received so far
+func (s *fakeService) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// AssertRequests checks the number of requests of the given method and path received so far
+func (s *fakeService) AssertRequests(t *testing.T, method, path string, count int) {
+	matching := 0
+	for _, r := range s.Requests() {
+		if r.Method == method && r.Path == normalizePath(path) {
+			matching++
+		}
+	}
+	assert.Equal(t, count, matching, "unexpected number of %s %s requests", method, path)
+}
+
+// handle records the request and replies injected failure if there is any matching one, otherwise it's handled by the
+// given handler
+func (s *fakeService) handle(handler func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := normalizePath(r.URL.Path)
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: path, Query: r.URL.RawQuery})
+		var injected *fault
+		for _, f := range s.faults {
+			if f.times != 0 && f.method == r.Method && f.path == path {
+				injected = f
+				f.times--
+				break
+			}
+		}
+		s.mu.Unlock()
+
+		if injected != nil {
+			reply(w, injected.status, injected.body)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func normalizePath(path string) string {
+	return "/" + strings.Trim(path, "/")
+}
+
+func reply(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
+
+func replyJSON(w http.ResponseWriter, status int, body interface{}) {
+	content, err := json.Marshal(body)
+	if err != nil {
+		reply(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	reply(w, status, string(content))
+}
+
+// FakeAuthService is an in-process fake of token endpoint of auth service. It issues tokens to service accounts
+// registered by their client id and secret
+type FakeAuthService struct {
+	fakeService
+	clients map[string]string
+	tokens  map[string]string
+}
+
+// NewFakeAuthService starts fake auth service knowing the service account of the given client id and secret. It has to
+// be closed by the caller
+func NewFakeAuthService(clientID, clientSecret string) *FakeAuthService {
+	s := StartFakeAuthService()
+	s.Register(clientID, clientSecret)
+	return s
+}
+
+// StartFakeAuthService starts fake auth service knowing no service account, which are registered later on, e.g. once
+// their credentials are known. It has to be closed by the caller
+func StartFakeAuthService() *FakeAuthService {
+	s := &FakeAuthService{
+		clients: map[string]string{},
+		tokens:  map[string]string{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/token", s.handle(s.token))
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Register makes the fake auth service issue tokens to the service account of the given client id and secret
+func (s *FakeAuthService) Register(clientID, clientSecret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[clientID] = clientSecret
+}
+
+// token exchanges client credentials of the service account for an access token
+func (s *FakeAuthService) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		reply(w, http.StatusMethodNotAllowed, "")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		reply(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		reply(w, http.StatusBadRequest, `{"errors":[{"code":"bad_parameter","detail":"grant_type must be client_credentials","status":"400"}]}`)
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if secret, found := s.clients[clientID]; !found || secret != r.PostForm.Get("client_secret") {
+		reply(w, http.StatusUnauthorized, `{"errors":[{"code":"unauthorized_error","detail":"invalid service account credentials","status":"401"}]}`)
+		return
+	}
+	token := randomToken()
+	s.tokens[token] = clientID
+	replyJSON(w, http.StatusOK, map[string]string{"access_token": token, "token_type": "Bearer"})
+}
+
+// Authorized returns the client id of the service account the bearer token of the request has been issued to
+func (s *FakeAuthService) Authorized(r *http.Request) (string, bool) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clientID, found := s.tokens[token]
+	return clientID, found
+}
+
+// Issued returns the number of tokens issued so far
+func (s *FakeAuthService) Issued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tokens)
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// RegisteredCluster is a cluster stored by fake cluster service
+type RegisteredCluster struct {
+	Name                   string  `json:"name"`
+	APIURL                 string  `json:"api-url"`
+	AppDNS                 string  `json:"app-dns"`
+	AuthClientID           string  `json:"auth-client-id"`
+	AuthClientSecret       string  `json:"auth-client-secret"`
+	AuthClientDefaultScope string  `json:"auth-client-default-scope"`
+	ServiceAccountUsername string  `json:"service-account-username"`
+	ServiceAccountToken    string  `json:"service-account-token"`
+	TokenProviderID        *string `json:"token-provider-id,omitempty"`
+	Type                   string  `json:"type"`
+	CapacityExhausted      bool    `json:"capacity-exhausted"`
+}
+
+// FakeClusterService is an in-process fake of cluster management service. It keeps registered clusters and accepts
+// only requests authorized by tokens issued by the given fake auth service
+type FakeClusterService struct {
+	fakeService
+	auth     *FakeAuthService
+	clusters map[string]RegisteredCluster
+}
+
+// NewFakeClusterService starts fake cluster service trusting tokens issued by the given fake auth service. It has to be
+// closed by the caller
+func NewFakeClusterService(auth *FakeAuthService) *FakeClusterService {
+	s := &FakeClusterService{
+		auth:     auth,
+		clusters: map[string]RegisteredCluster{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/status", s.handle(s.status))
+	mux.HandleFunc("/api/clusters", s.handle(s.authorized(s.clustersHandler)))
+	mux.HandleFunc("/api/clusters/", s.handle(s.authorized(s.clustersHandler)))
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *FakeClusterService) status(w http.ResponseWriter, r *http.Request) {
+	replyJSON(w, http.StatusOK, map[string]string{"commit": "fake"})
+}
+
+func (s *FakeClusterService) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.auth.Authorized(r); !ok {
+			reply(w, http.StatusUnauthorized, `{"errors":[{"code":"jwt_security_error","detail":"missing or invalid token","status":"401"}]}`)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *FakeClusterService) clustersHandler(w http.ResponseWriter, r *http.Request) {
+	path := normalizePath(r.URL.Path)
+	switch {
+	case path == "/api/clusters/auth" && r.Method == http.MethodGet:
+		s.get(w, r)
+	case path == "/api/clusters" && r.Method == http.MethodGet:
+		s.list(w)
+	case path == "/api/clusters" && r.Method == http.MethodPost:
+		s.create(w, r)
+	case path == "/api/clusters" && r.Method == http.MethodPatch:
+		s.update(w, r)
+	case path == "/api/clusters" && r.Method == http.MethodDelete:
+		s.delete(w, r)
+	default:
+		reply(w, http.StatusNotFound, "")
+	}
+}
+
+func (s *FakeClusterService) get(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, found := s.clusters[trailingSlash(r.URL.Query().Get("cluster-url"))]
+	if !found {
+		reply(w, http.StatusNotFound, `{"errors":[{"code":"not_found","detail":"cluster not found","status":"404"}]}`)
+		return
+	}
+	replyJSON(w, http.StatusOK, map[string]interface{}{"data": c})
+}
+
+func (s *FakeClusterService) list(w http.ResponseWriter) {
+	replyJSON(w, http.StatusOK, map[string]interface{}{"data": s.Clusters()})
+}
+
+// create registers the cluster, the cluster registered with the same API URL is replaced
+func (s *FakeClusterService) create(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Data *RegisteredCluster `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Data == nil || payload.Data.APIURL == "" {
+		reply(w, http.StatusBadRequest, `{"errors":[{"code":"bad_parameter","detail":"invalid cluster data","status":"400"}]}`)
+		return
+	}
+	s.Register(*payload.Data)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// update merges the given fields into the cluster registered with the given API URL
+func (s *FakeClusterService) update(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Data == nil {
+		reply(w, http.StatusBadRequest, `{"errors":[{"code":"bad_parameter","detail":"invalid cluster data","status":"400"}]}`)
+		return
+	}
+	apiURL, _ := payload.Data["api-url"].(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, found := s.clusters[trailingSlash(apiURL)]
+	if !found {
+		reply(w, http.StatusNotFound, `{"errors":[{"code":"not_found","detail":"cluster not found","status":"404"}]}`)
+		return
+	}
+	fields := map[string]interface{}{}
+	current, _ := json.Marshal(c)
+	json.Unmarshal(current, &fields)
+	for k, v := range payload.Data {
+		fields[k] = v
+	}
+	merged, _ := json.Marshal(fields)
+	var updated RegisteredCluster
+	if err := json.Unmarshal(merged, &updated); err != nil {
+		reply(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated.APIURL = c.APIURL
+	s.clusters[c.APIURL] = updated
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *FakeClusterService) delete(w http.ResponseWriter, r *http.Request) {
+	apiURL := trailingSlash(r.URL.Query().Get("cluster-url"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.clusters[apiURL]; !found {
+		reply(w, http.StatusNotFound, `{"errors":[{"code":"not_found","detail":"cluster not found","status":"404"}]}`)
+		return
+	}
+	delete(s.clusters, apiURL)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Register stores the given cluster as cluster service does, with trailing slash in its API URL
+func (s *FakeClusterService) Register(c RegisteredCluster) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.APIURL = trailingSlash(c.APIURL)
+	s.clusters[c.APIURL] = c
+}
+
+// Unregister removes the cluster of the given API URL
+func (s *FakeClusterService) Unregister(apiURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clusters, trailingSlash(apiURL))
+}
+
+// Cluster returns the cluster registered with the given API URL
+func (s *FakeClusterService) Cluster(apiURL string) (RegisteredCluster, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, found := s.clusters[trailingSlash(apiURL)]
+	return c, found
+}
+
+// Clusters returns all the registered clusters
+func (s *FakeClusterService) Clusters() []RegisteredCluster {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clusters := []RegisteredCluster{}
+	for _, c := range s.clusters {
+		clusters = append(clusters, c)
+	}
+	return clusters
+}
+
+// AssertRegistered checks that the cluster of the given API URL is registered and returns it
+func (s *FakeClusterService) AssertRegistered(t *testing.T, apiURL string) RegisteredCluster {
+	c, found := s.Cluster(apiURL)
+	require.True(t, found, "cluster %s isn't registered in fake cluster service", apiURL)
+	return c
+}
+
+// AssertNotRegistered checks that the cluster of the given API URL isn't registered
+func (s *FakeClusterService) AssertNotRegistered(t *testing.T, apiURL string) {
+	_, found := s.Cluster(apiURL)
+	assert.False(t, found, "cluster %s is registered in fake cluster service", apiURL)
+}
+
+func trailingSlash(u string) string {
+	return strings.TrimSuffix(u, "/") + "/"
+}
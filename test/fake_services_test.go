@@ -0,0 +1,307 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	clientID     = "bb6d043d-f243-458f-8498-2c18a12dcf47"
+	clientSecret = "secret"
+	apiURL       = "https://api.dsaas-stage.openshift.com"
+)
+
+func TestFakeAuthService(t *testing.T) {
+	auth := NewFakeAuthService(clientID, clientSecret)
+	defer auth.Close()
+
+	t.Run("token issued", func(t *testing.T) {
+		//when
+		res, body := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+
+		//then
+		require.Equal(t, http.StatusOK, res.StatusCode, body)
+		var token map[string]string
+		require.NoError(t, json.Unmarshal([]byte(body), &token))
+		assert.NotEmpty(t, token["access_token"])
+		assert.Equal(t, "Bearer", token["token_type"])
+		req, err := http.NewRequest(http.MethodGet, auth.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token["access_token"])
+		authorized, ok := auth.Authorized(req)
+		assert.True(t, ok)
+		assert.Equal(t, clientID, authorized)
+		assert.Equal(t, 1, auth.Issued())
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		//when
+		res, _ := requestToken(t, auth, "client_credentials", clientID, "wrong")
+
+		//then
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, 1, auth.Issued())
+	})
+
+	t.Run("unsupported grant type", func(t *testing.T) {
+		//when
+		res, _ := requestToken(t, auth, "password", clientID, clientSecret)
+
+		//then
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("unknown token not authorized", func(t *testing.T) {
+		//given
+		req, err := http.NewRequest(http.MethodGet, auth.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer unknown")
+
+		//when
+		_, ok := auth.Authorized(req)
+
+		//then
+		assert.False(t, ok)
+	})
+
+	t.Run("service account registered later", func(t *testing.T) {
+		//given
+		auth := StartFakeAuthService()
+		defer auth.Close()
+		unknown, _ := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+
+		//when
+		auth.Register(clientID, clientSecret)
+		registered, body := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+
+		//then
+		assert.Equal(t, http.StatusUnauthorized, unknown.StatusCode)
+		assert.Equal(t, http.StatusOK, registered.StatusCode, body)
+	})
+}
+
+func TestFakeServiceFaults(t *testing.T) {
+
+	t.Run("fails given times", func(t *testing.T) {
+		//given
+		auth := NewFakeAuthService(clientID, clientSecret)
+		defer auth.Close()
+		auth.Fail(http.MethodPost, "api/token/", http.StatusServiceUnavailable, 2)
+
+		//when
+		first, _ := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+		second, body := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+		third, _ := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+
+		//then
+		assert.Equal(t, http.StatusServiceUnavailable, first.StatusCode)
+		assert.Equal(t, http.StatusServiceUnavailable, second.StatusCode)
+		assert.Contains(t, body, "injected_failure")
+		assert.Equal(t, http.StatusOK, third.StatusCode)
+		assert.Equal(t, 1, auth.Issued())
+		auth.AssertRequests(t, http.MethodPost, "/api/token", 3)
+	})
+
+	t.Run("fails until healed", func(t *testing.T) {
+		//given
+		auth := NewFakeAuthService(clientID, clientSecret)
+		defer auth.Close()
+		auth.Fail(http.MethodPost, "/api/token", http.StatusInternalServerError, -1)
+
+		//when
+		var statuses []int
+		for i := 0; i < 3; i++ {
+			res, _ := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+			statuses = append(statuses, res.StatusCode)
+		}
+		auth.Heal()
+		healed, _ := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+
+		//then
+		assert.Equal(t, []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}, statuses)
+		assert.Equal(t, http.StatusOK, healed.StatusCode)
+	})
+
+	t.Run("other requests not affected", func(t *testing.T) {
+		//given
+		auth := NewFakeAuthService(clientID, clientSecret)
+		defer auth.Close()
+		clusterService := NewFakeClusterService(auth)
+		defer clusterService.Close()
+		clusterService.Fail(http.MethodGet, "/api/clusters/auth", http.StatusInternalServerError, -1)
+
+		//when
+		res, _ := do(t, clusterService, "", http.MethodGet, "/api/status", nil)
+
+		//then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []Request{{Method: http.MethodGet, Path: "/api/status"}}, clusterService.Requests())
+	})
+}
+
+func TestFakeClusterService(t *testing.T) {
+	auth := NewFakeAuthService(clientID, clientSecret)
+	defer auth.Close()
+	clusterService := NewFakeClusterService(auth)
+	defer clusterService.Close()
+	_, body := requestToken(t, auth, "client_credentials", clientID, clientSecret)
+	var token map[string]string
+	require.NoError(t, json.Unmarshal([]byte(body), &token))
+	bearer := token["access_token"]
+
+	t.Run("unauthorized", func(t *testing.T) {
+		//when
+		res, _ := do(t, clusterService, "unknown", http.MethodGet, "/api/clusters", nil)
+
+		//then
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("register", func(t *testing.T) {
+		//when
+		res, _ := do(t, clusterService, bearer, http.MethodPost, "/api/clusters", map[string]interface{}{
+			"data": RegisteredCluster{Name: "dsaas-stage", APIURL: apiURL, ServiceAccountToken: "mysatoken"},
+		})
+
+		//then
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		registered := clusterService.AssertRegistered(t, apiURL)
+		assert.Equal(t, apiURL+"/", registered.APIURL)
+		assert.Equal(t, "mysatoken", registered.ServiceAccountToken)
+	})
+
+	t.Run("register invalid", func(t *testing.T) {
+		//when
+		res, _ := do(t, clusterService, bearer, http.MethodPost, "/api/clusters", map[string]interface{}{
+			"data": RegisteredCluster{Name: "no-api-url"},
+		})
+
+		//then
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		//when
+		res, body := do(t, clusterService, bearer, http.MethodGet, "/api/clusters/auth?cluster-url="+url.QueryEscape(apiURL), nil)
+
+		//then
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var cluster struct {
+			Data RegisteredCluster `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &cluster))
+		assert.Equal(t, "dsaas-stage", cluster.Data.Name)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		//when
+		res, body := do(t, clusterService, bearer, http.MethodGet, "/api/clusters", nil)
+
+		//then
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var clusters struct {
+			Data []RegisteredCluster `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &clusters))
+		require.Len(t, clusters.Data, 1)
+		assert.Equal(t, clusterService.Clusters(), clusters.Data)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		//when
+		res, _ := do(t, clusterService, bearer, http.MethodPatch, "/api/clusters", map[string]interface{}{
+			"data": map[string]interface{}{"api-url": apiURL, "capacity-exhausted": true},
+		})
+
+		//then
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+		registered := clusterService.AssertRegistered(t, apiURL)
+		assert.True(t, registered.CapacityExhausted)
+		assert.Equal(t, "mysatoken", registered.ServiceAccountToken)
+	})
+
+	t.Run("update unknown", func(t *testing.T) {
+		//when
+		res, _ := do(t, clusterService, bearer, http.MethodPatch, "/api/clusters", map[string]interface{}{
+			"data": map[string]interface{}{"api-url": "https://api.unknown.openshift.com", "capacity-exhausted": true},
+		})
+
+		//then
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		//when
+		res, _ := do(t, clusterService, bearer, http.MethodDelete, "/api/clusters?cluster-url="+url.QueryEscape(apiURL), nil)
+		again, _ := do(t, clusterService, bearer, http.MethodDelete, "/api/clusters?cluster-url="+url.QueryEscape(apiURL), nil)
+
+		//then
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+		assert.Equal(t, http.StatusNotFound, again.StatusCode)
+		clusterService.AssertNotRegistered(t, apiURL)
+	})
+
+	t.Run("get unknown", func(t *testing.T) {
+		//when
+		res, _ := do(t, clusterService, bearer, http.MethodGet, "/api/clusters/auth?cluster-url="+url.QueryEscape(apiURL), nil)
+
+		//then
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("register and unregister directly", func(t *testing.T) {
+		//when
+		clusterService.Register(RegisteredCluster{Name: "dsaas-prod", APIURL: "https://api.dsaas-prod.openshift.com/"})
+
+		//then
+		clusterService.AssertRegistered(t, "https://api.dsaas-prod.openshift.com")
+
+		//when
+		clusterService.Unregister("https://api.dsaas-prod.openshift.com")
+
+		//then
+		clusterService.AssertNotRegistered(t, "https://api.dsaas-prod.openshift.com/")
+		assert.Empty(t, clusterService.Clusters())
+	})
+}
+
+// requestToken requests a token of the service account of the given credentials from the given fake auth service
+func requestToken(t *testing.T, auth *FakeAuthService, grantType, id, secret string) (*http.Response, string) {
+	form := url.Values{"grant_type": {grantType}, "client_id": {id}, "client_secret": {secret}}
+	res, err := http.PostForm(auth.URL+"/api/token", form)
+	require.NoError(t, err)
+	return res, readBody(t, res)
+}
+
+// do sends a request with the given JSON payload and bearer token to the given fake cluster service
+func do(t *testing.T, clusterService *FakeClusterService, bearer, method, path string, payload interface{}) (*http.Response, string) {
+	var body string
+	if payload != nil {
+		content, err := json.Marshal(payload)
+		require.NoError(t, err)
+		body = string(content)
+	}
+	req, err := http.NewRequest(method, clusterService.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return res, readBody(t, res)
+}
+
+func readBody(t *testing.T, res *http.Response) string {
+	defer res.Body.Close()
+	content, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(content)
+}
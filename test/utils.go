@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"github.com/fabric8-services/toolchain-operator/pkg/client"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -33,10 +34,10 @@ func SetEnv(environments ...Environment) func() {
 }
 
 func SASecretOption(t *testing.T, cl client.Client, ns string) func(sa *corev1.ServiceAccount) {
-	err := cl.CreateSecret(Secret("toolchain-sre-1fgd3", ns, "mysatoken", corev1.SecretTypeServiceAccountToken))
+	err := cl.CreateSecret(context.Background(), Secret("toolchain-sre-1fgd3", ns, "mysatoken", corev1.SecretTypeServiceAccountToken))
 	require.NoError(t, err)
 
-	err = cl.CreateSecret(Secret("toolchain-sre-6756s", ns, "mydockertoken", corev1.SecretTypeDockercfg))
+	err = cl.CreateSecret(context.Background(), Secret("toolchain-sre-6756s", ns, "mydockertoken", corev1.SecretTypeDockercfg))
 	require.NoError(t, err)
 
 	return func(sa *corev1.ServiceAccount) {